@@ -20,6 +20,7 @@ var analyzeCmd = &cobra.Command{
 
 支持的分析：
   • 系统资源分析（CPU、内存、磁盘）
+  • 磁盘队列参数检查（I/O 调度器、预读，需 diskqueue 采集器的数据）
   • 性能指标分析
   • 健康评分计算
   • 问题检测和分类
@@ -28,6 +29,7 @@ var analyzeCmd = &cobra.Command{
 输入：
   • JSON 格式的收集数据文件
   • 来自 collect 命令的输出
+  • collect --bundle 生成的支持包（.tar.gz，重新解析原始数据后分析）
 
 输出：
   • 分析结果（JSON/YAML/Table）
@@ -48,6 +50,9 @@ var analyzeCmd = &cobra.Command{
   # 只显示问题（跳过正常项）
   clusterreport analyze --input report.json --issues-only
 
+  # 直接分析支持包
+  clusterreport analyze --input node1.tar.gz
+
   # 自定义阈值
   clusterreport analyze --input report.json --cpu-warning 80 --memory-critical 95`,
 	RunE: runAnalyze,
@@ -72,12 +77,12 @@ func init() {
 	rootCmd.AddCommand(analyzeCmd)
 
 	// 必需标志
-	analyzeCmd.Flags().StringVarP(&analyzeInput, "input", "i", "", "输入数据文件路径（JSON 格式）")
+	analyzeCmd.Flags().StringVarP(&analyzeInput, "input", "i", "", "输入数据文件路径（JSON 格式或 .tar.gz 支持包）")
 	analyzeCmd.MarkFlagRequired("input")
 
 	// 输出选项
 	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "", "输出文件路径（默认输出到标准输出）")
-	analyzeCmd.Flags().StringVarP(&analyzeFormat, "format", "f", "json", "输出格式: json, yaml, table")
+	analyzeCmd.Flags().StringVarP(&analyzeFormat, "format", "f", "json", "输出格式: json, yaml, table；NDJSON 输入还支持 ndjson（每个节点一行）")
 	analyzeCmd.Flags().BoolVar(&analyzeIssuesOnly, "issues-only", false, "仅显示检测到的问题")
 
 	// 自定义阈值
@@ -100,10 +105,32 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		fmt.Printf("时间: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
 	}
 
-	// 读取输入文件
-	data, err := os.ReadFile(analyzeInput)
-	if err != nil {
-		return fmt.Errorf("读取输入文件失败: %w", err)
+	// collect --output-format ndjson 的输出逐个节点分析
+	if !isBundlePath(analyzeInput) {
+		ndjson, err := isNDJSONInput(analyzeInput)
+		if err != nil {
+			return err
+		}
+		if ndjson {
+			return runAnalyzeNDJSON(analyzeInput)
+		}
+	}
+
+	// 读取输入文件，支持包先在原始数据上重新解析
+	var data []byte
+	if isBundlePath(analyzeInput) {
+		result, err := replayBundle(analyzeInput)
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("格式化回放结果失败: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(analyzeInput); err != nil {
+			return fmt.Errorf("读取输入文件失败: %w", err)
+		}
 	}
 
 	// 解析 JSON 数据
@@ -120,21 +147,12 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 
 	// 转换为 SystemMetrics
 	metrics := convertToSystemMetrics(&collectedData)
-
-	// 创建分析器配置
-	analyzerConfig := analyzer.AnalyzerConfig{
-		CPUWarningThreshold:       analyzeCPUWarn,
-		CPUCriticalThreshold:      analyzeCPUCrit,
-		MemoryWarningThreshold:    analyzeMemWarn,
-		MemoryCriticalThreshold:   analyzeMemCrit,
-		DiskWarningThreshold:      analyzeDiskWarn,
-		DiskCriticalThreshold:     analyzeDiskCrit,
-		LoadAvgWarningMultiplier:  analyzeLoadWarn,
-		LoadAvgCriticalMultiplier: analyzeLoadCrit,
+	if err := attachDiskQueues(metrics, &collectedData); err != nil {
+		return err
 	}
 
 	// 创建分析器
-	systemAnalyzer := analyzer.NewSystemAnalyzer(analyzerConfig)
+	systemAnalyzer := analyzer.NewSystemAnalyzer(analyzerConfigFromFlags())
 
 	if !quiet {
 		fmt.Println("📊 正在分析系统指标...")
@@ -145,6 +163,9 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("分析失败: %w", err)
 	}
+	if collectedData.PerfSnap != nil {
+		result.FlameGraphPath = collectedData.PerfSnap.FlameGraphPath
+	}
 
 	if !quiet {
 		fmt.Println("✅ 分析完成\n")
@@ -154,6 +175,20 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 	return outputAnalysisResult(result)
 }
 
+// analyzerConfigFromFlags 根据命令行阈值创建分析器配置
+func analyzerConfigFromFlags() analyzer.AnalyzerConfig {
+	return analyzer.AnalyzerConfig{
+		CPUWarningThreshold:       analyzeCPUWarn,
+		CPUCriticalThreshold:      analyzeCPUCrit,
+		MemoryWarningThreshold:    analyzeMemWarn,
+		MemoryCriticalThreshold:   analyzeMemCrit,
+		DiskWarningThreshold:      analyzeDiskWarn,
+		DiskCriticalThreshold:     analyzeDiskCrit,
+		LoadAvgWarningMultiplier:  analyzeLoadWarn,
+		LoadAvgCriticalMultiplier: analyzeLoadCrit,
+	}
+}
+
 // CollectedData 收集的数据结构
 type CollectedData struct {
 	Metadata struct {
@@ -163,8 +198,15 @@ type CollectedData struct {
 		DurationSecond float64  `json:"duration_seconds"`
 		Version        string   `json:"version"`
 	} `json:"metadata"`
-	NodeProbe *NodeProbeData `json:"nodeprobe,omitempty"`
-	PerfSnap  *PerfSnapData  `json:"perfsnap,omitempty"`
+	NodeProbe  *NodeProbeData                    `json:"nodeprobe,omitempty"`
+	PerfSnap   *PerfSnapData                     `json:"perfsnap,omitempty"`
+	Collectors map[string]CollectedCollectorData `json:"collectors,omitempty"`
+}
+
+// CollectedCollectorData 其他采集器的结果
+type CollectedCollectorData struct {
+	Type string          `json:"type"`
+	Raw  json.RawMessage `json:"raw,omitempty"`
 }
 
 // NodeProbeData NodeProbe 收集的数据
@@ -180,26 +222,44 @@ type NodeProbeData struct {
 
 // PerfSnapData PerfSnap 收集的数据
 type PerfSnapData struct {
-	Hostname string `json:"hostname"`
-	Uptime   string `json:"uptime"`
-	Load     string `json:"load"`
+	Hostname       string                       `json:"hostname"`
+	Uptime         string                       `json:"uptime"`
+	LoadAverage    collector.PerfSnapLoadAvg    `json:"load_average"`
+	CPUStats       []collector.PerfSnapCPUStat  `json:"cpu_stats"`
+	MemoryStats    collector.PerfSnapMemoryStat `json:"memory_stats"`
+	FlameGraphPath string                       `json:"flame_graph_path"`
+}
+
+// attachDiskQueues 将 diskqueue 采集器的结果合并到 SystemMetrics，供分析器检查队列参数
+func attachDiskQueues(metrics *collector.SystemMetrics, data *CollectedData) error {
+	raw := data.Collectors["diskqueue"].Raw
+	if len(raw) == 0 {
+		return nil
+	}
+	var report collector.DiskQueueReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return fmt.Errorf("解析 diskqueue 数据失败: %w", err)
+	}
+	metrics.DiskQueues = report.Devices
+	return nil
 }
 
 // convertToSystemMetrics 转换为 SystemMetrics
+// 优先使用 metrics 采集器的完整结果；否则从 NodeProbe 和 PerfSnap 中提取，
+// 无法获得的部分记录为缺失，由分析器跳过
 func convertToSystemMetrics(data *CollectedData) *collector.SystemMetrics {
+	if raw := data.Collectors["metrics"].Raw; len(raw) > 0 {
+		var metrics collector.SystemMetrics
+		if err := json.Unmarshal(raw, &metrics); err == nil {
+			return &metrics
+		}
+	}
+
 	metrics := &collector.SystemMetrics{
 		Timestamp: time.Now(),
-		CPU: collector.CPUMetrics{
-			Cores: 0,
-		},
-		Memory: collector.MemoryMetrics{
-			Total:       0,
-			Available:   0,
-			Used:        0,
-			UsedPercent: 0,
-		},
-		Disk: []collector.DiskMetrics{},
 	}
+	haveCPU := false
+	haveMemory := false
 
 	// 从 NodeProbe 获取基础信息
 	if data.NodeProbe != nil {
@@ -207,42 +267,52 @@ func convertToSystemMetrics(data *CollectedData) *collector.SystemMetrics {
 		metrics.Memory.Total = uint64(data.NodeProbe.Memory.TotalGB * 1024 * 1024 * 1024)
 	}
 
-	// 如果没有采集到核心数，使用默认值
-	if metrics.CPU.Cores == 0 {
-		metrics.CPU.Cores = 4 // 默认 4 核
-	}
-
-	// 如果没有采集到内存，使用默认值
-	if metrics.Memory.Total == 0 {
-		metrics.Memory.Total = 16 * 1024 * 1024 * 1024 // 默认 16GB
-	}
-
 	// 从 PerfSnap 获取实时数据
 	if data.PerfSnap != nil {
-		// 解析 load average
-		var load1, load5, load15 float64
-		fmt.Sscanf(data.PerfSnap.Load, "%f %f %f", &load1, &load5, &load15)
-		metrics.CPU.LoadAvg1 = load1
-		metrics.CPU.LoadAvg5 = load5
-		metrics.CPU.LoadAvg15 = load15
+		metrics.CPU.LoadAvg1 = data.PerfSnap.LoadAverage.OneMin
+		metrics.CPU.LoadAvg5 = data.PerfSnap.LoadAverage.FiveMin
+		metrics.CPU.LoadAvg15 = data.PerfSnap.LoadAverage.FifteenMin
+
+		// mpstat 只给出各核心数据，总体使用率取平均
+		if n := len(data.PerfSnap.CPUStats); n > 0 {
+			var busy float64
+			for _, stat := range data.PerfSnap.CPUStats {
+				busy += 100 - stat.Idle - stat.IOWait
+				metrics.CPU.User += stat.User / float64(n)
+				metrics.CPU.System += stat.System / float64(n)
+				metrics.CPU.IOWait += stat.IOWait / float64(n)
+				metrics.CPU.Idle += stat.Idle / float64(n)
+			}
+			metrics.CPU.Usage = busy / float64(n)
+			if metrics.CPU.Cores == 0 {
+				metrics.CPU.Cores = n
+			}
+			haveCPU = true
+		}
+
+		mem := data.PerfSnap.MemoryStats
+		if mem.TotalMB > 0 {
+			metrics.Memory.Total = uint64(mem.TotalMB) * 1024 * 1024
+			metrics.Memory.Used = uint64(mem.UsedMB) * 1024 * 1024
+			metrics.Memory.Available = metrics.Memory.Total - metrics.Memory.Used
+			metrics.Memory.UsedPercent = mem.UsedPercent
+			haveMemory = true
+		}
 	}
 
-	// 生成模拟数据用于演示
-	// 在实际使用中，这些数据应该从收集的数据中提取
-	metrics.CPU.Usage = 45.5
-	metrics.Memory.Used = uint64(float64(metrics.Memory.Total) * 0.65)
-	metrics.Memory.Available = metrics.Memory.Total - metrics.Memory.Used
-	metrics.Memory.UsedPercent = (float64(metrics.Memory.Used) / float64(metrics.Memory.Total)) * 100
-
-	// 添加磁盘数据
-	metrics.Disk = append(metrics.Disk, collector.DiskMetrics{
-		Device:      "/dev/sda1",
-		MountPoint:  "/",
-		Total:       500 * 1024 * 1024 * 1024,
-		Used:        350 * 1024 * 1024 * 1024,
-		Available:   150 * 1024 * 1024 * 1024,
-		UsedPercent: 70.0,
-	})
+	missing := func(section string) {
+		metrics.SectionErrors = append(metrics.SectionErrors, collector.SectionError{
+			Section: section,
+			Error:   "not present in collected data",
+		})
+	}
+	if !haveCPU {
+		missing(collector.SectionCPU)
+	}
+	if !haveMemory {
+		missing(collector.SectionMemory)
+	}
+	missing(collector.SectionDisk)
 
 	return metrics
 }
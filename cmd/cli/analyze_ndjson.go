@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devops-toolkit/clusterreport/pkg/analyzer"
+	"github.com/devops-toolkit/clusterreport/pkg/collector"
+	"gopkg.in/yaml.v3"
+)
+
+// ndjsonSniffSize 判断输入是否为 NDJSON 时读取的最大字节数
+const ndjsonSniffSize = 1 << 20
+
+// FleetAnalysis NDJSON 输入的分析结果
+type FleetAnalysis struct {
+	Timestamp time.Time                           `json:"timestamp" yaml:"timestamp"`
+	Nodes     map[string]*analyzer.AnalysisResult `json:"nodes" yaml:"nodes"`
+	Failed    map[string]string                   `json:"failed,omitempty" yaml:"failed,omitempty"` // 采集失败的节点及原因
+	Truncated bool                                `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+}
+
+// FleetAnalysisRecord analyze --format ndjson 输出中的一行
+type FleetAnalysisRecord struct {
+	Node         string                   `json:"node"`
+	CollectError string                   `json:"collect_error,omitempty"`
+	Result       *analyzer.AnalysisResult `json:"result,omitempty"`
+}
+
+// isNDJSONInput 根据扩展名或文件开头的内容判断输入是否为 NDJSON
+func isNDJSONInput(path string) (bool, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return true, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("读取输入文件失败: %w", err)
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, ndjsonSniffSize))
+	if err != nil {
+		return false, fmt.Errorf("读取输入文件失败: %w", err)
+	}
+	return collector.IsNDJSON(head), nil
+}
+
+// runAnalyzeNDJSON 逐行读取 collect --output-format ndjson 的输出并分析每个节点
+// 内存中只保留当前节点的采集数据；--format ndjson 时分析结果同样逐行输出
+func runAnalyzeNDJSON(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("读取输入文件失败: %w", err)
+	}
+	defer f.Close()
+
+	out := os.Stdout
+	if analyzeOutput != "" {
+		file, err := os.Create(analyzeOutput)
+		if err != nil {
+			return fmt.Errorf("创建输出文件失败: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var (
+		systemAnalyzer = analyzer.NewSystemAnalyzer(analyzerConfigFromFlags())
+		reader         = collector.NewNDJSONReader(bufio.NewReader(f))
+		writer         = collector.NewNDJSONWriter(out)
+		fleet          = &FleetAnalysis{
+			Timestamp: time.Now(),
+			Nodes:     make(map[string]*analyzer.AnalysisResult),
+			Failed:    make(map[string]string),
+		}
+	)
+	for {
+		var info collector.SystemInfo
+		err := reader.Next(&info)
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, collector.ErrTruncatedRecord) {
+			// 采集被中断，已完整写出的节点照常分析
+			fleet.Truncated = true
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "⚠️  输入在最后一个节点处被截断，已忽略: %v\n", err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("解析输入数据失败: %w", err)
+		}
+
+		node := firstNonEmpty(info.Node, info.Hostname)
+		record := FleetAnalysisRecord{Node: node}
+		// 续跑的运行目录中同一节点可能有多条记录，以最后一条为准
+		if info.CollectError != "" {
+			record.CollectError = info.CollectError
+			fleet.Failed[node] = info.CollectError
+			delete(fleet.Nodes, node)
+		} else {
+			delete(fleet.Failed, node)
+			result, err := systemAnalyzer.Analyze(systemInfoToMetrics(&info))
+			if err != nil {
+				return fmt.Errorf("分析节点 %s 失败: %w", node, err)
+			}
+			record.Result = result
+			if analyzeFormat != "ndjson" {
+				fleet.Nodes[node] = result
+			}
+		}
+
+		if analyzeFormat == "ndjson" {
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch analyzeFormat {
+	case "ndjson":
+		return nil
+	case "table":
+		return outputFleetTable(out, fleet)
+	}
+
+	var data []byte
+	switch analyzeFormat {
+	case "json":
+		data, err = json.MarshalIndent(fleet, "", "  ")
+	case "yaml":
+		data, err = yaml.Marshal(fleet)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s", analyzeFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("格式化输出失败: %w", err)
+	}
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return fmt.Errorf("写入输出失败: %w", err)
+	}
+	if analyzeOutput != "" && !quiet {
+		fmt.Printf("✅ 分析结果已保存到: %s\n", analyzeOutput)
+	}
+	return nil
+}
+
+// outputFleetTable 以表格形式输出每个节点的健康状态
+func outputFleetTable(w io.Writer, fleet *FleetAnalysis) error {
+	nodes := make([]string, 0, len(fleet.Nodes))
+	for node := range fleet.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	fmt.Fprintf(w, "%-30s %-10s %-8s %s\n", "节点", "状态", "评分", "问题")
+	for _, node := range nodes {
+		result := fleet.Nodes[node]
+		if analyzeIssuesOnly && len(result.Issues) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-30s %-10s %-8.1f %d\n", node, result.Status, result.Score, len(result.Issues))
+		for _, issue := range result.Issues {
+			fmt.Fprintf(w, "    [%s/%s] %s (%s)\n", issue.Severity, issue.Category, issue.Description, issue.Value)
+		}
+	}
+
+	failed := make([]string, 0, len(fleet.Failed))
+	for node := range fleet.Failed {
+		failed = append(failed, node)
+	}
+	sort.Strings(failed)
+	for _, node := range failed {
+		fmt.Fprintf(w, "%-30s %-10s %-8s %s\n", node, "failed", "-", fleet.Failed[node])
+	}
+	return nil
+}
+
+// systemInfoToMetrics 将集群采集的节点信息转换为 SystemMetrics
+// SystemInfo 不包含 CPU 使用率的采样，CPU 部分只有核心数和负载
+func systemInfoToMetrics(info *collector.SystemInfo) *collector.SystemMetrics {
+	metrics := &collector.SystemMetrics{
+		Timestamp: info.CollectedAt,
+	}
+	missing := func(section string) {
+		metrics.SectionErrors = append(metrics.SectionErrors, collector.SectionError{
+			Section: section,
+			Error:   "not present in collected data",
+		})
+	}
+
+	metrics.CPU.Cores = info.CPUInfo.Cores
+	metrics.CPU.Usage = info.CPUInfo.Usage
+	metrics.CPU.LoadAvg1 = info.LoadAverage.Load1
+	metrics.CPU.LoadAvg5 = info.LoadAverage.Load5
+	metrics.CPU.LoadAvg15 = info.LoadAverage.Load15
+	if metrics.CPU.Cores == 0 {
+		missing(collector.SectionCPU)
+	}
+
+	if info.MemoryInfo.Total > 0 {
+		metrics.Memory = collector.MemoryMetrics{
+			Total:       info.MemoryInfo.Total,
+			Available:   info.MemoryInfo.Available,
+			Used:        info.MemoryInfo.Used,
+			UsedPercent: info.MemoryInfo.UsageRate,
+		}
+	} else {
+		missing(collector.SectionMemory)
+	}
+
+	if len(info.DiskInfo) > 0 {
+		for _, d := range info.DiskInfo {
+			metrics.Disk = append(metrics.Disk, collector.DiskMetrics{
+				Device:            d.Device,
+				MountPoint:        d.MountPoint,
+				FSType:            d.FSType,
+				Total:             d.Total,
+				Used:              d.Used,
+				Available:         d.Free,
+				UsedPercent:       d.UsageRate,
+				InodesUsedPercent: d.InodeUsageRate,
+				Stale:             d.Stale,
+			})
+		}
+	} else {
+		missing(collector.SectionDisk)
+	}
+
+	return metrics
+}
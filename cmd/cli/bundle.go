@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/devops-toolkit/clusterreport/pkg/collector"
+)
+
+// bundleResultFile 支持包中解析后的收集结果
+const bundleResultFile = "result.json"
+
+// writeBundle 将记录的原始数据和解析结果写为支持包
+func writeBundle(path string, manifest collector.BundleManifest, recorder *collector.RecordingExecutor, result *CollectResult) error {
+	resultData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("格式化收集结果失败: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建支持包失败: %w", err)
+	}
+	if err := collector.WriteBundle(f, manifest, recorder, map[string][]byte{bundleResultFile: resultData}); err != nil {
+		f.Close()
+		return fmt.Errorf("写入支持包失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("写入支持包失败: %w", err)
+	}
+	return nil
+}
+
+// isBundlePath 判断文件是否为支持包
+func isBundlePath(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// replayBundle 在支持包记录的原始数据上重新运行各采集器的解析逻辑
+func replayBundle(path string) (*CollectResult, error) {
+	startTime := time.Now()
+
+	bundle, err := collector.OpenBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	node, err := bundle.Node()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CollectResult{
+		Collectors: make(map[string]*collector.Data),
+	}
+	var collectTypes []string
+
+	for _, bc := range bundle.Manifest.Collectors {
+		c, ok := collector.Get(bc.Name)
+		if !ok {
+			return nil, fmt.Errorf("支持包中的采集器 %s 未注册", bc.Name)
+		}
+		if configurable, ok := c.(collector.Configurable); ok {
+			options := make(map[string]interface{}, len(bc.Options))
+			for k, v := range bc.Options {
+				options[k] = v
+			}
+			// 回放时不修改任何配置
+			if bc.Name == "nodeprobe" {
+				options["auto_optimize"] = false
+			}
+			if err := configurable.Configure(options); err != nil {
+				return nil, fmt.Errorf("采集器 %s 配置无效: %w", bc.Name, err)
+			}
+		}
+
+		if !quiet {
+			fmt.Printf("📋 正在回放采集器 %s...\n", bc.Name)
+		}
+		data, err := collector.CollectWithSettings(context.Background(), c, node, collector.Settings{})
+		if err != nil {
+			return nil, fmt.Errorf("采集器 %s 回放失败: %w", bc.Name, err)
+		}
+		if err := addCollectorData(result, bc.Name, data); err != nil {
+			return nil, err
+		}
+		collectTypes = append(collectTypes, string(data.Type))
+	}
+
+	result.Metadata = CollectMetadata{
+		Timestamp:    bundle.Manifest.CreatedAt,
+		Node:         bundle.Manifest.Node,
+		CollectTypes: collectTypes,
+		Duration:     time.Since(startTime).Seconds(),
+		Version:      collectResultVersion,
+		Bundle:       path,
+	}
+	return result, nil
+}
+
+// runBundleReplay 执行 collect --from-bundle
+func runBundleReplay(path string) error {
+	if !quiet {
+		fmt.Println("📦 ClusterReport - 从支持包回放数据")
+		fmt.Println("================================================")
+		fmt.Printf("支持包: %s\n\n", path)
+	}
+
+	result, err := replayBundle(path)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Printf("✅ 节点 %s，采集时间 %s\n\n", result.Metadata.Node, result.Metadata.Timestamp.Format("2006-01-02 15:04:05"))
+	}
+
+	if err := outputResult(result); err != nil {
+		return fmt.Errorf("输出结果失败: %w", err)
+	}
+	if !quiet && collectOutput != "" {
+		fmt.Printf("📁 结果已保存到: %s\n", collectOutput)
+	}
+	return nil
+}
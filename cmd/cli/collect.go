@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/devops-toolkit/clusterreport/pkg/collector"
+	_ "github.com/devops-toolkit/clusterreport/plugins/collectors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,7 +28,24 @@ var (
 	collectAutoOptimize bool
 	collectOutput       string
 	collectFormat       string
-	collectDuration     int
+	collectOutputFormat string
+	collectDuration     secondsDuration
+	collectInterval     time.Duration
+	collectCluster      string
+	collectParallel     int
+	collectTimeout      time.Duration
+	collectNodeTimeout  time.Duration
+	collectRetry        int
+	collectCollectors   string
+	collectPerfBackend  string
+	collectProfileDir   string
+	collectFlamePIDs    []int
+	collectFlameTop     bool
+	collectJournalDir   string
+	collectBundle       string
+	collectFromBundle   string
+	collectRunDir       string
+	collectResume       string
 )
 
 // collectCmd 代表 collect 命令
@@ -44,14 +68,53 @@ var collectCmd = &cobra.Command{
   # 仅收集性能数据
   clusterreport collect --nodes localhost --collect-perf
 
-  # 收集性能数据并生成火焰图
-  clusterreport collect --nodes localhost --collect-perf --flame-graph
+  # 收集性能数据并生成火焰图，同时导出 pprof 和折叠栈文件
+  clusterreport collect --nodes localhost --collect-perf --flame-graph --profile-dir ./profiles
+
+  # 火焰图只包含 CPU 占用最高的进程
+  clusterreport collect --nodes localhost --collect-perf --flame-graph --flame-top
+
+  # 每 5 秒采样一次，持续 10 分钟，输出时间序列及 min/avg/max/p95 统计
+  clusterreport collect --nodes localhost --collect-perf --interval 5s --duration 10m
+
+  # 指定运行的采集器（默认使用配置文件 collectors 段中启用的采集器）
+  clusterreport collect --collectors nodeprobe,perfsnap,mysql
+
+  # 采集 CPU 拓扑、缓存、指令集和漏洞缓解状态
+  clusterreport collect --collectors cputopology --format yaml
+
+  # 采集内存明细：meminfo、大页、透明大页、NUMA 节点、内存相关内核参数和 slab 缓存
+  clusterreport collect --collectors memory --format yaml
+
+  # 采集硬件资产：厂商、型号、序列号、BIOS、主板、机箱和每条内存（读取 DMI/SMBIOS，不依赖 dmidecode）
+  clusterreport collect --collectors inventory --format yaml
+
+  # 采集块设备拓扑：磁盘类型、分区、md RAID 状态、LVM、多路径以及每个挂载点所在的物理磁盘
+  clusterreport collect --collectors blockdevices --format yaml
+
+  # 采集磁盘队列参数：I/O 调度器、nr_requests、read_ahead_kb、max_sectors_kb 和写缓存模式
+  clusterreport collect --collectors diskqueue --output report.json
 
   # 输出为 YAML 格式
   clusterreport collect --nodes localhost --format yaml
 
   # 保存到文件
-  clusterreport collect --nodes localhost --output report.json`,
+  clusterreport collect --nodes localhost --output report.json
+
+  # 通过 SSH 采集配置文件中定义的集群
+  clusterreport collect --cluster production --output fleet.json
+
+  # 可续跑的集群采集：运行目录中记录每个节点的状态，结果逐个写入 results.ndjson
+  clusterreport collect --cluster production --run-dir ./runs/prod
+
+  # 中断或部分节点失败后，只重新采集未成功的节点
+  clusterreport collect --resume ./runs/prod
+
+  # 生成离线支持包：包含所有原始命令输出、读取的文件、解析结果和带哈希的清单
+  clusterreport collect --nodes localhost --bundle node1.tar.gz
+
+  # 在支持包的原始数据上重新解析，无需再访问节点
+  clusterreport collect --from-bundle node1.tar.gz --format table`,
 	RunE: runCollect,
 }
 
@@ -67,17 +130,53 @@ func init() {
 	collectCmd.Flags().BoolVar(&collectAll, "collect-all", true, "收集所有数据（配置+性能）")
 
 	// 高级选项
-	collectCmd.Flags().BoolVar(&collectFlameGraph, "flame-graph", false, "生成 CPU 火焰图（需要 perf 工具）")
-	collectCmd.Flags().BoolVar(&collectAutoOptimize, "auto-optimize", false, "自动执行系统优化（需要 root 权限）")
-	collectCmd.Flags().IntVar(&collectDuration, "duration", 5, "性能采集持续时间（秒）")
+	collectCmd.Flags().BoolVar(&collectFlameGraph, "flame-graph", false, "生成 CPU 火焰图及 pprof、折叠栈文件（需要 perf 工具）")
+	collectCmd.Flags().StringVar(&collectProfileDir, "profile-dir", "", "火焰图、pprof 和折叠栈文件的输出目录（默认系统临时目录）")
+	collectCmd.Flags().IntSliceVar(&collectFlamePIDs, "flame-pids", nil, "火焰图只包含这些进程（逗号分隔的 PID）")
+	collectCmd.Flags().BoolVar(&collectFlameTop, "flame-top", false, "火焰图只包含 CPU 占用最高的进程")
+	collectCmd.Flags().BoolVar(&collectAutoOptimize, "auto-optimize", false, "自动执行系统优化（需要 root 权限），变更记录到日志，可用 optimize rollback 回滚")
+	collectCmd.Flags().StringVar(&collectJournalDir, "journal-dir", "", "优化变更日志目录（默认使用配置 optimize.journal_dir）")
+	collectDuration = secondsDuration(5 * time.Second)
+	collectCmd.Flags().Var(&collectDuration, "duration", "性能采集持续时间（秒数或 10m 形式）")
+	collectCmd.Flags().DurationVar(&collectInterval, "interval", 0, "性能时间序列采样间隔（如 5s），0 表示只采集单个快照")
+	collectCmd.Flags().StringVar(&collectPerfBackend, "perf-backend", collector.PerfSnapBackendNative, "性能采集后端: native（读取 /proc）, sysstat（需要 sysstat 工具）")
 
 	// 输出选项
 	collectCmd.Flags().StringVarP(&collectOutput, "output", "o", "", "输出文件路径（默认输出到标准输出）")
 	collectCmd.Flags().StringVarP(&collectFormat, "format", "f", "json", "输出格式: json, yaml, table")
+	collectCmd.Flags().StringVar(&collectOutputFormat, "output-format", "", "集群采集的流式输出格式: ndjson（每个节点完成后立即写入一行，中断时已完成的节点不丢失）")
+	collectCmd.Flags().StringVar(&collectBundle, "bundle", "", "同时生成离线支持包（tar.gz），包含原始命令输出和读取的文件")
+	collectCmd.Flags().StringVar(&collectFromBundle, "from-bundle", "", "从支持包回放原始数据并重新解析，不访问节点")
+
+	// 集群采集选项
+	collectCmd.Flags().StringVarP(&collectCluster, "cluster", "C", "", "配置文件中的集群名称（通过 SSH 采集集群所有节点）")
+	collectCmd.Flags().IntVarP(&collectParallel, "parallel", "p", 10, "集群采集并发数")
+	collectCmd.Flags().DurationVarP(&collectTimeout, "timeout", "t", 5*time.Minute, "集群采集总超时时间")
+	collectCmd.Flags().StringVar(&collectRunDir, "run-dir", "", "集群采集的运行目录，记录每个节点的状态和结果，可用 --resume 续跑")
+	collectCmd.Flags().StringVar(&collectResume, "resume", "", "续跑指定运行目录中未成功的节点")
+	collectCmd.Flags().DurationVar(&collectNodeTimeout, "node-timeout", 0, "单个节点每次尝试的超时时间（默认使用配置 collector.timeout）")
+	collectCmd.Flags().IntVar(&collectRetry, "retry", 0, "节点遇到临时性网络错误时的重试次数（默认使用配置 collector.retry）")
+	collectCmd.Flags().StringVar(&collectCollectors, "collectors", "", "要运行的采集器列表（逗号分隔），如 nodeprobe,perfsnap,mysql")
 }
 
 // runCollect 执行 collect 命令
 func runCollect(cmd *cobra.Command, args []string) error {
+	if collectFromBundle != "" {
+		return runBundleReplay(collectFromBundle)
+	}
+	if collectResume != "" {
+		return runResumeCollect(cmd, collectResume)
+	}
+	if collectRunDir != "" && collectCluster == "" {
+		return fmt.Errorf("--run-dir 需要同时指定 --cluster")
+	}
+	if collectCluster != "" {
+		if collectBundle != "" {
+			return fmt.Errorf("--bundle 暂不支持集群采集，请逐个节点生成支持包")
+		}
+		return runClusterCollect(cmd, collectCluster)
+	}
+
 	startTime := time.Now()
 
 	if !quiet {
@@ -88,49 +187,59 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	// 确定收集类型
-	shouldCollectConfig := collectConfig || collectAll
-	shouldCollectPerf := collectPerf || collectAll
+	settings, err := loadCollectorSettings()
+	if err != nil {
+		return err
+	}
 
-	// 如果用户明确指定了某一种，则不收集另一种
-	if collectConfig && !collectPerf {
-		shouldCollectPerf = false
-		shouldCollectConfig = true
+	collectors, err := collector.Select(selectCollectorNames(cmd, settings), settings)
+	if err != nil {
+		return err
 	}
-	if collectPerf && !collectConfig {
-		shouldCollectConfig = false
-		shouldCollectPerf = true
+	if len(collectors) == 0 {
+		return fmt.Errorf("没有启用任何采集器，请检查配置文件 collectors 段或使用 --collectors 指定")
 	}
 
-	var result CollectResult
+	result := CollectResult{
+		Collectors: make(map[string]*collector.Data),
+	}
+	node := collector.LocalNode()
+	var collectTypes []string
+
+	// 生成支持包时记录经过执行器的所有命令输出和文件
+	// 记录执行器不被视为本地执行器，采集器会走与远程节点相同的路径（如用 df 代替 statfs），保证回放时数据完整
+	var recorder *collector.RecordingExecutor
+	var bundleCollectors []collector.BundleCollector
+	if collectBundle != "" {
+		recorder = collector.NewRecordingExecutor(node.Executor)
+		node.Executor = recorder
+	}
 
-	// 收集配置信息（NodeProbe）
-	if shouldCollectConfig {
-		if !quiet {
-			fmt.Println("📋 正在收集配置信息...")
-		}
-		configData, err := collectNodeProbeData()
-		if err != nil {
-			return fmt.Errorf("配置收集失败: %w", err)
-		}
-		result.NodeProbe = configData
-		if !quiet {
-			fmt.Println("✅ 配置信息收集完成")
+	for _, c := range collectors {
+		s := settings[c.Name()]
+		options := collectorOptions(cmd, c.Name(), s.Options)
+		if configurable, ok := c.(collector.Configurable); ok {
+			if err := configurable.Configure(options); err != nil {
+				return fmt.Errorf("采集器 %s 配置无效: %w", c.Name(), err)
+			}
 		}
-	}
+		bundleCollectors = append(bundleCollectors, collector.NewBundleCollector(c.Name(), options))
 
-	// 收集性能数据（PerfSnap）
-	if shouldCollectPerf {
 		if !quiet {
-			fmt.Println("📊 正在收集性能数据...")
+			fmt.Printf("📋 正在运行采集器 %s...\n", c.Name())
 		}
-		perfData, err := collectPerfSnapData()
+		data, err := collector.CollectWithSettings(context.Background(), c, node, s)
 		if err != nil {
-			return fmt.Errorf("性能收集失败: %w", err)
+			return fmt.Errorf("采集器 %s 采集失败: %w", c.Name(), err)
+		}
+
+		if err := addCollectorData(&result, c.Name(), data); err != nil {
+			return err
 		}
-		result.PerfSnap = perfData
+		collectTypes = append(collectTypes, string(data.Type))
+
 		if !quiet {
-			fmt.Println("✅ 性能数据收集完成")
+			fmt.Printf("✅ 采集器 %s 完成\n", c.Name())
 		}
 	}
 
@@ -138,9 +247,24 @@ func runCollect(cmd *cobra.Command, args []string) error {
 	result.Metadata = CollectMetadata{
 		Timestamp:    time.Now(),
 		Node:         collectNodes,
-		CollectTypes: getCollectTypes(shouldCollectConfig, shouldCollectPerf),
+		CollectTypes: collectTypes,
 		Duration:     time.Since(startTime).Seconds(),
-		Version:      "0.8.0-dev",
+		Version:      collectResultVersion,
+	}
+
+	if recorder != nil {
+		manifest := collector.BundleManifest{
+			CreatedAt:  startTime,
+			Node:       node.Name,
+			Target:     recorder.Target(),
+			Collectors: bundleCollectors,
+		}
+		if err := writeBundle(collectBundle, manifest, recorder, &result); err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Printf("📦 支持包已保存到: %s\n", collectBundle)
+		}
 	}
 
 	// 输出结果
@@ -160,41 +284,367 @@ func runCollect(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// collectNodeProbeData 收集 NodeProbe 数据
-func collectNodeProbeData() (*collector.NodeProbeData, error) {
-	nodeProbe := collector.NewNodeProbeCollector(collectAutoOptimize)
-
-	if verbose {
-		fmt.Println("  - 收集系统信息...")
-		fmt.Println("  - 收集硬件信息...")
-		fmt.Println("  - 收集网络配置...")
-		fmt.Println("  - 检查内核模块...")
-		if collectAutoOptimize {
-			fmt.Println("  - 执行自动优化...")
+// runClusterCollect 通过 SSH 并发采集集群所有节点
+func runClusterCollect(cmd *cobra.Command, name string) error {
+	cluster, err := findClusterConfig(name)
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Println("🚀 ClusterReport - 开始集群数据收集")
+		fmt.Println("================================================")
+		fmt.Printf("集群: %s (%d 个节点)\n", cluster.Name, len(cluster.Nodes))
+		fmt.Printf("时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+		fmt.Println()
+	}
+
+	sysCollector, err := newClusterCollector(cmd, cluster, cluster.Nodes)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	if collectRunDir != "" {
+		run, err := collector.CreateRun(collectRunDir, cluster.Name, cluster.Nodes)
+		if err != nil {
+			return err
+		}
+		defer run.Close()
+		return collectRun(ctx, sysCollector, run)
+	}
+
+	switch collectOutputFormat {
+	case "":
+	case "ndjson":
+		return streamCollection(ctx, sysCollector, cluster.Nodes, collectParallel, collectOutput)
+	default:
+		return fmt.Errorf("不支持的流式输出格式: %s", collectOutputFormat)
+	}
+
+	results := sysCollector.CollectMultiple(ctx, cluster.Nodes, collectParallel)
+
+	if collectFormat == "table" {
+		sysCollector.PrintSummary(results)
+		return nil
+	}
+
+	var output []byte
+	switch collectFormat {
+	case "json":
+		output, err = json.MarshalIndent(results, "", "  ")
+	case "yaml":
+		output, err = yaml.Marshal(results)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s", collectFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("格式化输出失败: %w", err)
+	}
+
+	if collectOutput != "" {
+		if err := os.WriteFile(collectOutput, output, 0644); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+		if !quiet {
+			sysCollector.PrintSummary(results)
+			fmt.Printf("📁 结果已保存到: %s\n", collectOutput)
+		}
+	} else {
+		fmt.Println(string(output))
+	}
+
+	return nil
+}
+
+// newClusterCollector 创建采集集群节点的 SystemCollector，配置 SSH 和单节点重试策略
+func newClusterCollector(cmd *cobra.Command, cluster *ClusterConfig, nodes []string) (*SystemCollector, error) {
+	sysCollector := NewSystemCollector(CollectorConfig{Targets: nodes}, verbose)
+
+	if hasRemoteNodes(nodes) {
+		sshConfig, port, err := clusterSSHConfig(cluster)
+		if err != nil {
+			return nil, err
+		}
+		sysCollector.SetSSHConfig(sshConfig, port)
+	}
+
+	policy, err := nodeRetryPolicy(cmd)
+	if err != nil {
+		return nil, err
+	}
+	sysCollector.SetRetryPolicy(policy)
+	return sysCollector, nil
+}
+
+// runResumeCollect 续跑运行目录中未成功的节点，集群的 SSH 配置从当前配置文件中读取
+func runResumeCollect(cmd *cobra.Command, dir string) error {
+	if collectCluster != "" || collectRunDir != "" {
+		return fmt.Errorf("--resume 不能与 --cluster 或 --run-dir 同时使用，集群信息从运行清单中读取")
+	}
+
+	run, err := collector.OpenRun(dir)
+	if err != nil {
+		return err
+	}
+	defer run.Close()
+
+	manifest := run.Manifest()
+	pending := run.Pending()
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "🔁 续跑 %s (集群 %s): %d/%d 个节点待采集\n",
+			manifest.RunID, firstNonEmpty(manifest.Cluster, "-"), len(pending), len(manifest.Nodes))
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 运行清单中只记录集群名称，集群已从配置中删除时使用 SSH 默认配置
+	var cluster *ClusterConfig
+	if manifest.Cluster != "" {
+		if cluster, err = findClusterConfig(manifest.Cluster); err != nil && verbose {
+			fmt.Fprintf(os.Stderr, "⚠️  %v，使用 SSH 默认配置\n", err)
+		}
+	}
+
+	sysCollector, err := newClusterCollector(cmd, cluster, pending)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	return collectRun(ctx, sysCollector, run)
+}
+
+// collectRun 采集运行中尚未成功的节点，每个节点完成后立即写入运行目录
+func collectRun(ctx context.Context, sc *SystemCollector, run *collector.Run) error {
+	if err := sc.CollectStream(ctx, run.Pending(), collectParallel, run.Record); err != nil {
+		return fmt.Errorf("写入采集结果失败: %w", err)
+	}
+
+	if !quiet {
+		manifest := run.Manifest()
+		counts := manifest.Counts()
+		fmt.Fprintf(os.Stderr, "运行 %s 共 %d 个节点: %d 成功, %d 失败, %d 超时, %d 未完成\n",
+			manifest.RunID, len(manifest.Nodes), counts[collector.NodeStatusOK], counts[collector.NodeStatusFailed],
+			counts[collector.NodeStatusTimeout], counts[collector.NodeStatusPending])
+		fmt.Fprintf(os.Stderr, "📁 结果已保存到: %s\n", run.ResultsPath())
+		if len(manifest.Nodes) > counts[collector.NodeStatusOK] {
+			fmt.Fprintf(os.Stderr, "💡 使用 clusterreport collect --resume %s 重新采集未成功的节点\n", run.Dir())
+		}
+	}
+	return nil
+}
+
+// streamCollection 采集节点并以 NDJSON 形式逐个写出结果，path 为空时写到标准输出
+// 结果不在内存中累积，采集中断时已写出的节点保持完整
+func streamCollection(ctx context.Context, sc *SystemCollector, nodes []string, parallel int, path string) error {
+	out := os.Stdout
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("创建输出文件失败: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	writer := collector.NewNDJSONWriter(out)
+	counts := make(map[string]int)
+	err := sc.CollectStream(ctx, nodes, parallel, func(info *SystemInfo) error {
+		counts[info.Status]++
+		return writer.Write(info)
+	})
+	if err != nil {
+		return fmt.Errorf("写入采集结果失败: %w", err)
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "共 %d 个节点: %d 成功, %d 失败, %d 超时\n", len(nodes),
+			counts[collector.NodeStatusOK], counts[collector.NodeStatusFailed], counts[collector.NodeStatusTimeout])
+		if path != "" {
+			fmt.Fprintf(os.Stderr, "📁 结果已保存到: %s\n", path)
+		}
+	}
+	return nil
+}
+
+// findClusterConfig 从配置文件中查找集群定义
+func findClusterConfig(name string) (*ClusterConfig, error) {
+	var clusters []ClusterConfig
+	if err := viper.UnmarshalKey("clusters", &clusters); err != nil {
+		return nil, fmt.Errorf("解析集群配置失败: %w", err)
+	}
+
+	for i := range clusters {
+		if clusters[i].Name == name {
+			if len(clusters[i].Nodes) == 0 {
+				return nil, fmt.Errorf("集群 %s 没有定义任何节点", name)
+			}
+			return &clusters[i], nil
 		}
 	}
 
-	return nodeProbe.Collect()
+	return nil, fmt.Errorf("配置文件中未找到集群: %s", name)
 }
 
-// collectPerfSnapData 收集 PerfSnap 数据
-func collectPerfSnapData() (*collector.PerfSnapData, error) {
-	perfSnap := collector.NewPerfSnapCollectorWithOptions(collectDuration, collectFlameGraph)
-
-	if verbose {
-		fmt.Println("  - 收集系统负载...")
-		fmt.Println("  - 收集 CPU 统计...")
-		fmt.Println("  - 收集内存使用...")
-		fmt.Println("  - 收集磁盘 IO...")
-		fmt.Println("  - 收集网络流量...")
-		fmt.Println("  - 收集进程统计...")
-		fmt.Println("  - 分析性能问题...")
-		if collectFlameGraph {
-			fmt.Println("  - 生成火焰图...")
+// clusterSSHConfig 返回集群的 SSH 客户端配置和端口，未配置的项使用 ssh.default_* 默认值
+// 主机密钥按 ssh.known_hosts 校验，只有显式设置 ssh.insecure_ignore_host_key 时才跳过
+// cluster 为 nil 时只使用默认值
+func clusterSSHConfig(cluster *ClusterConfig) (*ssh.ClientConfig, int, error) {
+	if cluster == nil {
+		cluster = &ClusterConfig{}
+	}
+
+	insecure := viper.GetBool("ssh.insecure_ignore_host_key")
+	sshConfig, err := collector.NewSSHClientConfig(collector.SSHOptions{
+		Username:              firstNonEmpty(cluster.Username, viper.GetString("ssh.default_user")),
+		KeyFile:               firstNonEmpty(cluster.SSHKey, viper.GetString("ssh.default_key")),
+		KnownHostsFile:        viper.GetString("ssh.known_hosts"),
+		InsecureIgnoreHostKey: insecure,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("集群 %s 的 SSH 配置无效: %w", cluster.Name, err)
+	}
+	if insecure {
+		fmt.Fprintf(os.Stderr, "⚠️  ssh.insecure_ignore_host_key 已开启，不校验集群 %s 的主机密钥，连接可能被中间人劫持\n", cluster.Name)
+	}
+
+	port := cluster.Port
+	if port == 0 {
+		port = viper.GetInt("ssh.default_port")
+	}
+	return sshConfig, port, nil
+}
+
+// nodeRetryPolicy 返回集群采集中单个节点的超时和重试策略
+// 优先使用命令行参数，其次使用配置 collector.timeout 和 collector.retry，均未设置时使用默认值
+func nodeRetryPolicy(cmd *cobra.Command) (collector.RetryPolicy, error) {
+	policy := collector.DefaultRetryPolicy()
+
+	if value := viper.GetString("collector.timeout"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return policy, fmt.Errorf("配置 collector.timeout 无效: %s", value)
 		}
+		policy.Timeout = timeout
+	}
+	if viper.IsSet("collector.retry") {
+		policy.Retries = viper.GetInt("collector.retry")
 	}
 
-	return perfSnap.Collect()
+	if cmd.Flags().Changed("node-timeout") {
+		timeout, _ := cmd.Flags().GetDuration("node-timeout")
+		policy.Timeout = timeout
+	}
+	if cmd.Flags().Changed("retry") {
+		retries, _ := cmd.Flags().GetInt("retry")
+		policy.Retries = retries
+	}
+	if policy.Retries < 0 {
+		return policy, fmt.Errorf("重试次数不能为负数: %d", policy.Retries)
+	}
+	return policy, nil
+}
+
+// hasRemoteNodes 判断节点列表中是否包含远程节点
+func hasRemoteNodes(nodes []string) bool {
+	for _, node := range nodes {
+		node = strings.TrimSpace(node)
+		if node != "localhost" && node != "127.0.0.1" {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// loadCollectorSettings 读取配置文件中的 collectors 段
+func loadCollectorSettings() (map[string]collector.Settings, error) {
+	settings := make(map[string]collector.Settings)
+	if err := viper.UnmarshalKey("collectors", &settings); err != nil {
+		return nil, fmt.Errorf("解析采集器配置失败: %w", err)
+	}
+	return settings, nil
+}
+
+// selectCollectorNames 确定要运行的采集器名称
+// 优先使用 --collectors；其次兼容 --collect-config/--collect-perf；
+// 都未指定时返回空列表，由配置文件中的 enabled 决定
+func selectCollectorNames(cmd *cobra.Command, settings map[string]collector.Settings) []string {
+	if collectCollectors != "" {
+		return strings.Split(collectCollectors, ",")
+	}
+
+	flags := cmd.Flags()
+	if len(settings) > 0 && !flags.Changed("collect-config") && !flags.Changed("collect-perf") && !flags.Changed("collect-all") {
+		return nil
+	}
+
+	shouldCollectConfig := collectConfig || collectAll
+	shouldCollectPerf := collectPerf || collectAll
+
+	// 如果用户明确指定了某一种，则不收集另一种
+	if collectConfig && !collectPerf {
+		shouldCollectPerf = false
+		shouldCollectConfig = true
+	}
+	if collectPerf && !collectConfig {
+		shouldCollectConfig = false
+		shouldCollectPerf = true
+	}
+
+	var names []string
+	if shouldCollectConfig {
+		names = append(names, "nodeprobe")
+	}
+	if shouldCollectPerf {
+		names = append(names, "perfsnap")
+	}
+	return names
+}
+
+// collectorOptions 合并配置文件选项与命令行标志，命令行显式指定的标志优先
+func collectorOptions(cmd *cobra.Command, name string, configured map[string]interface{}) map[string]interface{} {
+	options := make(map[string]interface{}, len(configured))
+	for k, v := range configured {
+		options[k] = v
+	}
+
+	flags := cmd.Flags()
+	set := func(key, flag string, value interface{}) {
+		if _, ok := options[key]; !ok || flags.Changed(flag) {
+			options[key] = value
+		}
+	}
+
+	switch name {
+	case "nodeprobe":
+		set("auto_optimize", "auto-optimize", collectAutoOptimize)
+		set("journal_dir", "journal-dir", journalDir(collectJournalDir))
+	case "perfsnap":
+		set("duration", "duration", time.Duration(collectDuration))
+		set("interval", "interval", collectInterval)
+		set("flame_graph", "flame-graph", collectFlameGraph)
+		set("backend", "perf-backend", collectPerfBackend)
+		set("profile_dir", "profile-dir", collectProfileDir)
+		set("profile_pids", "flame-pids", collectFlamePIDs)
+		set("profile_top_processes", "flame-top", collectFlameTop)
+	}
+
+	return options
 }
 
 // CollectResult 收集结果
@@ -202,6 +652,9 @@ type CollectResult struct {
 	Metadata  CollectMetadata          `json:"metadata" yaml:"metadata"`
 	NodeProbe *collector.NodeProbeData `json:"nodeprobe,omitempty" yaml:"nodeprobe,omitempty"`
 	PerfSnap  *collector.PerfSnapData  `json:"perfsnap,omitempty" yaml:"perfsnap,omitempty"`
+
+	// Collectors 其他采集器的结果，按采集器名称索引
+	Collectors map[string]*collector.Data `json:"collectors,omitempty" yaml:"collectors,omitempty"`
 }
 
 // CollectMetadata 收集元数据
@@ -211,18 +664,29 @@ type CollectMetadata struct {
 	CollectTypes []string  `json:"collect_types" yaml:"collect_types"`
 	Duration     float64   `json:"duration_seconds" yaml:"duration_seconds"`
 	Version      string    `json:"version" yaml:"version"`
+	Bundle       string    `json:"bundle,omitempty" yaml:"bundle,omitempty"` // 从支持包回放时的包路径
 }
 
-// getCollectTypes 获取收集类型列表
-func getCollectTypes(config, perf bool) []string {
-	var types []string
-	if config {
-		types = append(types, "config")
-	}
-	if perf {
-		types = append(types, "performance")
+// collectResultVersion 收集结果格式版本
+const collectResultVersion = "0.8.0-dev"
+
+// addCollectorData 将采集器结果加入收集结果
+// NodeProbe 和 PerfSnap 的结果保持原有输出结构，便于 analyze 命令读取
+func addCollectorData(result *CollectResult, name string, data *collector.Data) error {
+	switch raw := data.Raw.(type) {
+	case *collector.NodeProbeData:
+		result.NodeProbe = raw
+		// 变更日志已由采集器在修改节点前写入，这里只提示路径
+		if raw.Remediation != nil && raw.Remediation.Path != "" && !quiet {
+			path := raw.Remediation.Path
+			fmt.Printf("📝 优化变更日志已保存到: %s（可用 clusterreport optimize rollback %s 回滚）\n", path, path)
+		}
+	case *collector.PerfSnapData:
+		result.PerfSnap = raw
+	default:
+		result.Collectors[name] = data
 	}
-	return types
+	return nil
 }
 
 // outputResult 输出结果
@@ -294,6 +758,28 @@ func outputTable(result *CollectResult) error {
 		fmt.Printf("  网络接口: %d 个\n", len(result.PerfSnap.NetworkStats))
 		fmt.Printf("  检测到的问题: %d 个\n", len(result.PerfSnap.Issues))
 		fmt.Printf("  优化建议: %d 条\n", len(result.PerfSnap.Recommendations))
+		if result.PerfSnap.FlameGraphPath != "" {
+			fmt.Printf("  火焰图: %s\n", result.PerfSnap.FlameGraphPath)
+			fmt.Printf("  pprof: %s\n", result.PerfSnap.PprofPath)
+			fmt.Printf("  折叠栈: %s\n", result.PerfSnap.FoldedPath)
+		}
+		if result.PerfSnap.ProfileError != "" {
+			fmt.Printf("  火焰图生成失败: %s\n", result.PerfSnap.ProfileError)
+		}
+
+		if series := result.PerfSnap.Series; series != nil {
+			fmt.Printf("\n📈 时间序列: %d 个采样点 (间隔 %.0f 秒, 共 %.0f 秒)\n", len(series.Points), series.Interval, series.Duration)
+			names := make([]string, 0, len(series.Summary))
+			for name := range series.Summary {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("  %-32s %10s %10s %10s %10s\n", "指标", "min", "avg", "max", "p95")
+			for _, name := range names {
+				sum := series.Summary[name]
+				fmt.Printf("  %-32s %10.2f %10.2f %10.2f %10.2f\n", name, sum.Min, sum.Avg, sum.Max, sum.P95)
+			}
+		}
 
 		// 显示问题
 		if len(result.PerfSnap.Issues) > 0 {
@@ -308,7 +794,39 @@ func outputTable(result *CollectResult) error {
 		}
 	}
 
+	// 其他采集器摘要
+	if len(result.Collectors) > 0 {
+		fmt.Println("\n🧩 其他采集器:")
+		for name, data := range result.Collectors {
+			fmt.Printf("  %s: %d 项指标\n", name, len(data.Metrics))
+		}
+	}
+
 	fmt.Println("\n💡 提示: 使用 --format json 或 --format yaml 获取完整数据")
 
 	return nil
 }
+
+// secondsDuration 时长标志，兼容旧的整数秒写法（--duration 30）和 10m 形式
+type secondsDuration time.Duration
+
+func (d *secondsDuration) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *secondsDuration) Set(value string) error {
+	if secs, err := strconv.Atoi(value); err == nil {
+		*d = secondsDuration(time.Duration(secs) * time.Second)
+		return nil
+	}
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("无效的时长 %q，应为秒数或 10m 形式", value)
+	}
+	*d = secondsDuration(v)
+	return nil
+}
+
+func (d *secondsDuration) Type() string {
+	return "duration"
+}
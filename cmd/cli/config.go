@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -256,6 +257,10 @@ type AppSSHConfig struct {
 	DefaultKey  string `yaml:"default_key,omitempty"`
 	DefaultUser string `yaml:"default_user,omitempty"`
 	DefaultPort int    `yaml:"default_port,omitempty"`
+	KnownHosts  string `yaml:"known_hosts,omitempty"`
+
+	// InsecureIgnoreHostKey 不校验主机密钥，仅用于测试环境
+	InsecureIgnoreHostKey bool `yaml:"insecure_ignore_host_key,omitempty"`
 }
 
 // validateAppConfig 验证配置
@@ -325,6 +330,12 @@ func validateAppConfig(config *AppConfig) []string {
 		errors = append(errors, "重试次数不能为负数")
 	}
 
+	if config.Collector.Timeout != "" {
+		if timeout, err := time.ParseDuration(config.Collector.Timeout); err != nil || timeout <= 0 {
+			errors = append(errors, fmt.Sprintf("收集超时时间无效: %s", config.Collector.Timeout))
+		}
+	}
+
 	return errors
 }
 
@@ -382,10 +393,10 @@ collector:
   # 并发收集的节点数
   parallel: 10
   
-  # 收集超时时间
+  # 单个节点每次尝试的超时时间，超时的节点标记为 timeout
   timeout: 5m
   
-  # 失败重试次数
+  # 遇到临时性网络错误时的重试次数（认证失败不重试），重试间隔按指数退避
   retry: 3
 
 # SSH 全局配置（可选，会被集群配置覆盖）
@@ -393,5 +404,9 @@ ssh:
   default_key: ~/.ssh/id_rsa
   default_user: root
   default_port: 22
+  # 校验节点主机密钥的 known_hosts 文件，新节点需先用 ssh-keyscan 加入
+  known_hosts: ~/.ssh/known_hosts
+  # 设为 true 时不校验主机密钥，连接可能被中间人劫持，仅用于测试环境
+  # insecure_ignore_host_key: false
 `
 }
@@ -211,6 +211,8 @@ body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,Arial,sans-s
 .severity.warning{background:#ffc107;color:#333}
 .suggestion-item{background:#e7f3ff;border-left:4px solid #0066cc;padding:15px 20px;margin-bottom:10px;border-radius:4px}
 .no-issues{text-align:center;padding:40px;color:#28a745;font-size:18px}
+.flamegraph-container{overflow-x:auto}
+.flamegraph-container svg{max-width:100%%;height:auto}
 .footer{background:#f8f9fa;padding:20px;text-align:center;color:#666;font-size:14px;border-top:1px solid #eee}
 </style>
 </head>
@@ -267,6 +269,15 @@ body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,Arial,sans-s
 		html.WriteString("</div>")
 	}
 
+	// 火焰图：SVG 直接内嵌，报告单文件即可查看
+	if result.FlameGraphPath != "" {
+		if svg, err := os.ReadFile(result.FlameGraphPath); err == nil {
+			html.WriteString("<div class=\"section\"><h2>🔥 CPU 火焰图</h2><div class=\"flamegraph-container\">")
+			html.Write(svg)
+			html.WriteString("</div></div>")
+		}
+	}
+
 	html.WriteString("</div><div class=\"footer\">由 ClusterReport 生成</div></div></body></html>")
 	return html.String()
 }
@@ -310,6 +321,10 @@ func buildMarkdownReport(result *analyzer.AnalysisResult) string {
 		md.WriteString("\n")
 	}
 
+	if result.FlameGraphPath != "" {
+		md.WriteString(fmt.Sprintf("## 🔥 CPU 火焰图\n\n![CPU 火焰图](%s)\n\n", result.FlameGraphPath))
+	}
+
 	md.WriteString("---\n\n*由 ClusterReport 生成*\n")
 	return md.String()
 }
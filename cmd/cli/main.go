@@ -123,11 +123,12 @@ func initAppConfig() {
 // collectCommand 数据采集命令
 func (app *Application) collectCommand() *cobra.Command {
 	var (
-		cluster  string
-		nodes    []string
-		output   string
-		parallel int
-		timeout  time.Duration
+		cluster      string
+		nodes        []string
+		output       string
+		outputFormat string
+		parallel     int
+		timeout      time.Duration
 	)
 
 	cmd := &cobra.Command{
@@ -157,6 +158,20 @@ func (app *Application) collectCommand() *cobra.Command {
 			// 创建系统采集器
 			verbose := viper.GetBool("verbose")
 			collector := NewSystemCollector(CollectorConfig{}, verbose)
+			policy, err := nodeRetryPolicy(cmd)
+			if err != nil {
+				return err
+			}
+			collector.SetRetryPolicy(policy)
+
+			// 流式输出时每个节点完成后立即写出
+			switch outputFormat {
+			case "":
+			case "ndjson":
+				return streamCollection(ctx, collector, targetNodes, parallel, output)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
 
 			// 并发采集多个节点
 			results := collector.CollectMultiple(ctx, targetNodes, parallel)
@@ -179,6 +194,7 @@ func (app *Application) collectCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&cluster, "cluster", "C", "", "cluster name from config")
 	cmd.Flags().StringSliceVarP(&nodes, "nodes", "n", []string{}, "nodes to collect from (default: localhost)")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "output file path (JSON format)")
+	cmd.Flags().StringVar(&outputFormat, "output-format", "", "streaming output format: ndjson (one line per node, written as soon as it finishes)")
 	cmd.Flags().IntVarP(&parallel, "parallel", "p", 10, "parallel workers")
 	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 5*time.Minute, "collection timeout")
 
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/devops-toolkit/clusterreport/pkg/collector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// optimize 命令标志
+	optimizeDryRun     bool
+	optimizeCluster    string
+	optimizeJournalDir string
+	optimizeProfile    string
+	optimizeProfileDir string
+)
+
+// optimizeCmd 代表 optimize 命令
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "检查并优化节点配置，支持预览和回滚",
+	Long: `优化命令根据 NodeProbe 的检查结果生成修复计划并在节点上执行。
+
+计划包括：
+  • 将 powersave 模式的 CPU 调整为 performance
+  • 加载缺失的内核模块
+  • 设置系统时区
+
+也可以通过 --profile 使用声明式调优配置（内置 kubernetes-node、database-host、low-latency，
+或 optimize.profile_dir 目录中的 YAML 文件），逐项对比内核参数、CPU 调频、内核模块、THP、
+磁盘调度器和资源限制，报告不一致项并按配置修复。
+
+每次执行都会保存一份变更日志，记录每项修改前的原值，可用 optimize rollback 恢复。
+
+示例:
+  # 只显示将要执行的修改
+  clusterreport optimize --dry-run
+
+  # 在本地节点执行优化，变更日志保存到 ./journal
+  clusterreport optimize --journal-dir ./journal
+
+  # 通过 SSH 优化集群所有节点
+  clusterreport optimize --cluster production
+
+  # 查看节点与 database-host 配置的差异
+  clusterreport optimize --profile database-host --dry-run
+
+  # 按 kubernetes-node 配置优化集群所有节点
+  clusterreport optimize --profile kubernetes-node --cluster production
+
+  # 列出可用的调优配置
+  clusterreport optimize profiles
+
+  # 按变更日志回滚
+  clusterreport optimize rollback ./journal/node1-20240101-120000.json`,
+	RunE: runOptimize,
+}
+
+// optimizeRollbackCmd 代表 optimize rollback 命令
+var optimizeRollbackCmd = &cobra.Command{
+	Use:   "rollback <journal>",
+	Short: "按变更日志将节点恢复为修改前的配置",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runOptimizeRollback,
+}
+
+// optimizeProfilesCmd 代表 optimize profiles 命令
+var optimizeProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "列出可用的调优配置",
+	Args:  cobra.NoArgs,
+	RunE:  runOptimizeProfiles,
+}
+
+func init() {
+	rootCmd.AddCommand(optimizeCmd)
+	optimizeCmd.AddCommand(optimizeRollbackCmd, optimizeProfilesCmd)
+
+	optimizeCmd.PersistentFlags().BoolVar(&optimizeDryRun, "dry-run", false, "只显示将要执行的修改，不实际执行")
+	optimizeCmd.PersistentFlags().StringVarP(&optimizeCluster, "cluster", "C", "", "配置文件中的集群名称（通过 SSH 连接节点）")
+	optimizeCmd.Flags().StringVar(&optimizeJournalDir, "journal-dir", "", "变更日志目录（默认使用配置 optimize.journal_dir）")
+	optimizeCmd.Flags().StringVar(&optimizeProfile, "profile", "", "调优配置名称或 YAML 文件路径")
+	optimizeCmd.PersistentFlags().StringVar(&optimizeProfileDir, "profile-dir", "", "自定义调优配置目录（默认使用配置 optimize.profile_dir）")
+}
+
+// optimizeTarget 待优化的节点
+type optimizeTarget struct {
+	name     string
+	executor collector.Executor
+}
+
+// runOptimize 执行 optimize 命令
+func runOptimize(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var profile *collector.TuningProfile
+	if optimizeProfile != "" {
+		p, err := collector.LoadTuningProfile(optimizeProfile, profileDir())
+		if err != nil {
+			return err
+		}
+		profile = p
+		fmt.Printf("📐 调优配置: %s", profile.Name)
+		if profile.Description != "" {
+			fmt.Printf(" - %s", profile.Description)
+		}
+		fmt.Println()
+	}
+
+	targets, closeAll, err := optimizeTargets(ctx)
+	defer closeAll()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, target := range targets {
+		plan, err := optimizePlan(ctx, target, profile)
+		if err != nil {
+			return fmt.Errorf("节点 %s: %w", target.name, err)
+		}
+		if len(plan.Actions) == 0 {
+			fmt.Println("  ✅ 无需优化")
+			continue
+		}
+		for _, action := range plan.Actions {
+			fmt.Printf("  • %s: %s -> %s\n", action.Description, displayValue(action.From), displayValue(action.To))
+		}
+		if optimizeDryRun {
+			continue
+		}
+
+		// 变更日志在修改前写入并随每项修改更新，日志无法保存时立即停止
+		remediator := collector.NewRemediator(target.executor)
+		remediator.SetJournalDir(journalDir(optimizeJournalDir))
+		journal, applyErr := remediator.Apply(ctx, plan)
+		printJournal(journal)
+		if errors.Is(applyErr, collector.ErrJournalNotSaved) {
+			return fmt.Errorf("节点 %s: %w", target.name, applyErr)
+		}
+		fmt.Printf("  📝 变更日志: %s\n", journal.Path)
+
+		if applyErr != nil {
+			fmt.Printf("  ❌ %v\n", applyErr)
+			failed++
+		}
+	}
+
+	if optimizeDryRun {
+		fmt.Println("\n(dry-run) 未做任何修改")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个节点的部分优化未能完成", failed)
+	}
+	return nil
+}
+
+// optimizePlan 生成节点的修复计划并输出节点信息；未指定调优配置时使用 NodeProbe 的内置检查
+func optimizePlan(ctx context.Context, target optimizeTarget, profile *collector.TuningProfile) (*collector.RemediationPlan, error) {
+	if profile == nil {
+		probe := collector.NewNodeProbeCollector(false)
+		probe.SetExecutor(target.executor)
+		plan := probe.Plan()
+		fmt.Printf("🖥  节点 %s (%s)\n", plan.Node, target.name)
+		return plan, nil
+	}
+
+	report, err := profile.Evaluate(ctx, target.executor)
+	if err != nil {
+		return nil, err
+	}
+
+	compliant := 0
+	for _, check := range report.Checks {
+		if check.Compliant {
+			compliant++
+		}
+	}
+	drift := report.Drift()
+	fmt.Printf("🖥  节点 %s (%s): %d 项检查，%d 项符合，%d 项不一致\n",
+		report.Node, target.name, len(report.Checks), compliant, len(drift))
+	for _, check := range report.Checks {
+		if check.Error != "" {
+			fmt.Printf("  ⚠️  %s: 无法读取 (%s)\n", check.Action.Description, check.Error)
+		}
+	}
+	return report.Plan(), nil
+}
+
+// runOptimizeProfiles 执行 optimize profiles 命令
+func runOptimizeProfiles(cmd *cobra.Command, args []string) error {
+	profiles, err := collector.TuningProfiles(profileDir())
+	if err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		fmt.Printf("%-20s %s\n", p.Name, p.Description)
+	}
+	return nil
+}
+
+// runOptimizeRollback 执行 optimize rollback 命令
+func runOptimizeRollback(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	path := args[0]
+
+	journal, err := collector.LoadJournal(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🖥  节点 %s (%s)，变更时间 %s\n", journal.Node, journal.Target, journal.CreatedAt.Format("2006-01-02 15:04:05"))
+	pending := 0
+	for i := len(journal.Entries) - 1; i >= 0; i-- {
+		entry := journal.Entries[i]
+		if entry.Status != collector.JournalApplied && entry.Status != collector.JournalPending && entry.Status != collector.JournalRollbackFailed {
+			continue
+		}
+		fmt.Printf("  • %s: %s -> %s\n", entry.Action.Description, displayValue(entry.Action.To), displayValue(entry.Action.From))
+		pending++
+	}
+	if pending == 0 {
+		fmt.Println("  ✅ 没有需要回滚的修改")
+		return nil
+	}
+	if optimizeDryRun {
+		fmt.Println("\n(dry-run) 未做任何修改")
+		return nil
+	}
+
+	executor, closeFn, err := journalExecutor(ctx, journal.Target)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	rollbackErr := collector.NewRemediator(executor).Rollback(ctx, journal)
+	printJournal(journal)
+
+	// 回写状态，重复执行时跳过已回滚的条目
+	if err := collector.WriteJournal(path, journal); err != nil {
+		return err
+	}
+	if rollbackErr != nil {
+		return fmt.Errorf("回滚未完成: %w", rollbackErr)
+	}
+	fmt.Println("  ✅ 回滚完成")
+	return nil
+}
+
+// optimizeTargets 返回待优化的节点，未指定集群时只包含本地节点
+func optimizeTargets(ctx context.Context) ([]optimizeTarget, func(), error) {
+	var closers []func() error
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	if optimizeCluster == "" {
+		return []optimizeTarget{{name: "localhost", executor: collector.NewLocalExecutor(collector.DefaultExecOptions())}}, closeAll, nil
+	}
+
+	cluster, err := findClusterConfig(optimizeCluster)
+	if err != nil {
+		return nil, closeAll, err
+	}
+
+	var targets []optimizeTarget
+	for _, node := range cluster.Nodes {
+		executor, closeFn, err := nodeExecutor(ctx, cluster, node)
+		if err != nil {
+			return nil, closeAll, err
+		}
+		closers = append(closers, closeFn)
+		targets = append(targets, optimizeTarget{name: node, executor: executor})
+	}
+	return targets, closeAll, nil
+}
+
+// journalExecutor 返回变更日志所属节点的执行器
+func journalExecutor(ctx context.Context, target string) (collector.Executor, func() error, error) {
+	var cluster *ClusterConfig
+	if optimizeCluster != "" {
+		c, err := findClusterConfig(optimizeCluster)
+		if err != nil {
+			return nil, nil, err
+		}
+		cluster = c
+	}
+	return nodeExecutor(ctx, cluster, target)
+}
+
+// nodeExecutor 返回节点的执行器，远程节点通过 SSH 连接
+func nodeExecutor(ctx context.Context, cluster *ClusterConfig, node string) (collector.Executor, func() error, error) {
+	if !hasRemoteNodes([]string{node}) {
+		return collector.NewLocalExecutor(collector.DefaultExecOptions()), func() error { return nil }, nil
+	}
+
+	sshConfig, port, err := clusterSSHConfig(cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	address := node
+	if _, _, err := net.SplitHostPort(node); err != nil {
+		address = net.JoinHostPort(node, strconv.Itoa(port))
+	}
+
+	executor, err := collector.DialSSHExecutor(ctx, address, sshConfig, collector.DefaultExecOptions())
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接节点 %s 失败: %w", node, err)
+	}
+	return executor, executor.Close, nil
+}
+
+// journalDir 返回变更日志目录
+func journalDir(flagValue string) string {
+	return firstNonEmpty(flagValue, viper.GetString("optimize.journal_dir"), "journal")
+}
+
+// profileDir 返回自定义调优配置目录
+func profileDir() string {
+	return firstNonEmpty(optimizeProfileDir, viper.GetString("optimize.profile_dir"))
+}
+
+// printJournal 输出变更日志中每项修改的状态
+func printJournal(journal *collector.Journal) {
+	for _, entry := range journal.Entries {
+		line := fmt.Sprintf("  [%s] %s", entry.Status, entry.Action.Description)
+		if entry.Error != "" {
+			line += ": " + entry.Error
+		}
+		fmt.Println(line)
+	}
+}
+
+// displayValue 返回便于单行显示的值，空值显示为 "-"，多行内容以 "; " 连接
+func displayValue(value string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, "\n", "; ")
+}
@@ -48,10 +48,12 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件路径 (默认: ./config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "详细输出模式")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "静默模式（仅输出错误）")
+	rootCmd.PersistentFlags().String("known-hosts", "~/.ssh/known_hosts", "校验远程节点主机密钥使用的 known_hosts 文件")
 
 	// 绑定到 viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+	viper.BindPFlag("ssh.known_hosts", rootCmd.PersistentFlags().Lookup("known-hosts"))
 }
 
 // initConfig 读取配置文件和环境变量
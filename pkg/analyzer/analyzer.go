@@ -3,6 +3,7 @@ package analyzer
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/devops-toolkit/clusterreport/pkg/collector"
@@ -23,6 +24,12 @@ type AnalysisResult struct {
 	Issues      []Issue                `json:"issues"`
 	Metrics     map[string]interface{} `json:"metrics"`
 	Suggestions []string               `json:"suggestions"`
+
+	// SkippedSections 因缺少采集数据而跳过分析的部分
+	SkippedSections []string `json:"skipped_sections,omitempty"`
+
+	// FlameGraphPath 采集时生成的 CPU 火焰图 SVG，HTML 报告中内嵌显示
+	FlameGraphPath string `json:"flame_graph_path,omitempty"`
 }
 
 // Issue 问题描述
@@ -103,17 +110,29 @@ func (ba *BaseAnalyzer) Analyze(data interface{}) (*AnalysisResult, error) {
 		Suggestions: []string{},
 	}
 
-	// 分析 CPU
-	ba.analyzeCPU(metrics, result)
+	// 缺少数据的部分直接跳过，避免把缺失值当作 0 分析
+	if ba.hasSection(metrics, result, collector.SectionCPU) {
+		// 分析 CPU
+		ba.analyzeCPU(metrics, result)
+
+		// 分析 Load Average
+		ba.analyzeLoadAvg(metrics, result)
+	}
 
-	// 分析内存
-	ba.analyzeMemory(metrics, result)
+	if ba.hasSection(metrics, result, collector.SectionMemory) {
+		// 分析内存
+		ba.analyzeMemory(metrics, result)
+	}
 
-	// 分析磁盘
-	ba.analyzeDisk(metrics, result)
+	if ba.hasSection(metrics, result, collector.SectionDisk) {
+		// 分析磁盘
+		ba.analyzeDisk(metrics, result)
+	}
 
-	// 分析 Load Average
-	ba.analyzeLoadAvg(metrics, result)
+	if len(metrics.DiskQueues) > 0 {
+		// 分析磁盘队列参数
+		ba.analyzeDiskQueues(metrics, result)
+	}
 
 	// 计算总体评分和状态
 	ba.calculateOverallStatus(result)
@@ -121,6 +140,15 @@ func (ba *BaseAnalyzer) Analyze(data interface{}) (*AnalysisResult, error) {
 	return result, nil
 }
 
+// hasSection 判断分区数据是否可用，不可用时记录到 SkippedSections
+func (ba *BaseAnalyzer) hasSection(metrics *collector.SystemMetrics, result *AnalysisResult, section string) bool {
+	if metrics.HasSection(section) {
+		return true
+	}
+	result.SkippedSections = append(result.SkippedSections, section)
+	return false
+}
+
 // analyzeCPU 分析 CPU 使用情况
 func (ba *BaseAnalyzer) analyzeCPU(metrics *collector.SystemMetrics, result *AnalysisResult) {
 	cpuUsage := metrics.CPU.Usage
@@ -185,6 +213,21 @@ func (ba *BaseAnalyzer) analyzeMemory(metrics *collector.SystemMetrics, result *
 // analyzeDisk 分析磁盘使用情况
 func (ba *BaseAnalyzer) analyzeDisk(metrics *collector.SystemMetrics, result *AnalysisResult) {
 	for _, disk := range metrics.Disk {
+		// 无响应的挂载点没有容量数据，且会让访问它的进程卡死
+		if disk.Stale {
+			result.Issues = append(result.Issues, Issue{
+				Severity:    "critical",
+				Category:    "disk",
+				Description: fmt.Sprintf("挂载点 %s (%s %s) 无响应", disk.MountPoint, disk.FSType, disk.Device),
+				Value:       "stale",
+				Threshold:   "statfs timeout",
+			})
+			result.Score -= 20
+			result.Suggestions = append(result.Suggestions,
+				fmt.Sprintf("检查 %s 的存储服务器或 FUSE 进程，必要时执行 umount -l %s", disk.Device, disk.MountPoint))
+			continue
+		}
+
 		diskUsage := disk.UsedPercent
 
 		metricKey := fmt.Sprintf("disk_usage_%s", disk.MountPoint)
@@ -213,11 +256,118 @@ func (ba *BaseAnalyzer) analyzeDisk(metrics *collector.SystemMetrics, result *An
 			result.Suggestions = append(result.Suggestions,
 				fmt.Sprintf("监控 %s 磁盘使用，计划清理策略", disk.MountPoint))
 		}
+
+		ba.analyzeInodes(disk, result)
+	}
+}
+
+// analyzeInodes 分析 inode 使用情况，与磁盘容量使用相同的阈值
+// 大量小文件可能在容量充足时耗尽 inode，导致无法创建文件
+func (ba *BaseAnalyzer) analyzeInodes(disk collector.DiskMetrics, result *AnalysisResult) {
+	// btrfs 等动态分配 inode 的文件系统没有 inode 使用率
+	if disk.InodesUsedPercent == 0 {
+		return
+	}
+
+	inodeUsage := disk.InodesUsedPercent
+	result.Metrics[fmt.Sprintf("inode_usage_%s", disk.MountPoint)] = inodeUsage
+
+	if inodeUsage >= ba.config.DiskCriticalThreshold {
+		result.Issues = append(result.Issues, Issue{
+			Severity:    "critical",
+			Category:    "disk",
+			Description: fmt.Sprintf("磁盘 %s inode 使用率严重过高", disk.MountPoint),
+			Value:       fmt.Sprintf("%.2f%%", inodeUsage),
+			Threshold:   fmt.Sprintf("%.2f%%", ba.config.DiskCriticalThreshold),
+		})
+		result.Score -= 20
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("清理 %s 上的大量小文件（如会话、缓存文件）", disk.MountPoint))
+	} else if inodeUsage >= ba.config.DiskWarningThreshold {
+		result.Issues = append(result.Issues, Issue{
+			Severity:    "warning",
+			Category:    "disk",
+			Description: fmt.Sprintf("磁盘 %s inode 使用率偏高", disk.MountPoint),
+			Value:       fmt.Sprintf("%.2f%%", inodeUsage),
+			Threshold:   fmt.Sprintf("%.2f%%", ba.config.DiskWarningThreshold),
+		})
+		result.Score -= 10
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("排查 %s 上小文件的来源，避免 inode 耗尽", disk.MountPoint))
+	}
+}
+
+// hddMinReadAheadKB 机械数据盘的最小预读（KB），内核默认的 128KB 对顺序读为主的数据盘过小
+const hddMinReadAheadKB = 512
+
+// analyzeDiskQueues 检查 I/O 调度器和预读是否与磁盘类型匹配
+// NVMe 自身有多队列和深队列，内核调度器只增加开销；机械盘则依赖调度器合并和排序请求
+func (ba *BaseAnalyzer) analyzeDiskQueues(metrics *collector.SystemMetrics, result *AnalysisResult) {
+	var nvmeScheduled, hddUnscheduled, hddReadAhead []string
+
+	for _, q := range metrics.DiskQueues {
+		if q.Type != collector.BlockTypeDisk {
+			continue
+		}
+		switch q.Media {
+		case collector.MediaNVMe:
+			if q.Scheduler != "" && q.Scheduler != "none" {
+				result.Issues = append(result.Issues, Issue{
+					Severity:    "warning",
+					Category:    "disk",
+					Description: fmt.Sprintf("NVMe 磁盘 %s 使用了 I/O 调度器", q.Device),
+					Value:       q.Scheduler,
+					Threshold:   "none",
+				})
+				result.Score -= 5
+				nvmeScheduled = append(nvmeScheduled, q.Device)
+			}
+		case collector.MediaHDD:
+			if q.Scheduler == "none" && len(q.Schedulers) > 0 {
+				result.Issues = append(result.Issues, Issue{
+					Severity:    "warning",
+					Category:    "disk",
+					Description: fmt.Sprintf("机械盘 %s 未使用 I/O 调度器", q.Device),
+					Value:       q.Scheduler,
+					Threshold:   "mq-deadline / bfq",
+				})
+				result.Score -= 5
+				hddUnscheduled = append(hddUnscheduled, q.Device)
+			}
+			if q.DataDisk && q.ReadAheadKB > 0 && q.ReadAheadKB < hddMinReadAheadKB {
+				result.Issues = append(result.Issues, Issue{
+					Severity:    "warning",
+					Category:    "disk",
+					Description: fmt.Sprintf("机械数据盘 %s 预读过小", q.Device),
+					Value:       fmt.Sprintf("%d KB", q.ReadAheadKB),
+					Threshold:   fmt.Sprintf("%d KB", hddMinReadAheadKB),
+				})
+				result.Score -= 5
+				hddReadAhead = append(hddReadAhead, q.Device)
+			}
+		}
+	}
+
+	if len(nvmeScheduled) > 0 {
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("将 %s 的调度器设为 none（echo none > /sys/block/<dev>/queue/scheduler），并通过 udev 规则持久化", strings.Join(nvmeScheduled, ", ")))
+	}
+	if len(hddUnscheduled) > 0 {
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("为机械盘 %s 启用 mq-deadline 或 bfq 调度器，避免随机写饿死读请求", strings.Join(hddUnscheduled, ", ")))
+	}
+	if len(hddReadAhead) > 0 {
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("将机械数据盘 %s 的 /sys/block/<dev>/queue/read_ahead_kb 调大到 %d 以上并通过 udev 规则持久化，提升顺序读吞吐", strings.Join(hddReadAhead, ", "), hddMinReadAheadKB))
 	}
 }
 
 // analyzeLoadAvg 分析系统负载
 func (ba *BaseAnalyzer) analyzeLoadAvg(metrics *collector.SystemMetrics, result *AnalysisResult) {
+	if metrics.CPU.Cores <= 0 {
+		return
+	}
+
 	cores := float64(metrics.CPU.Cores)
 	load1 := metrics.CPU.LoadAvg1
 	load5 := metrics.CPU.LoadAvg5
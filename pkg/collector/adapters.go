@@ -0,0 +1,373 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// boundExecutor 将执行器绑定到上下文，使旧采集器内部的 context.Background() 调用也能被取消
+type boundExecutor struct {
+	Executor
+	ctx context.Context
+}
+
+// withContext 返回绑定了 ctx 的执行器
+func withContext(ctx context.Context, executor Executor) Executor {
+	return &boundExecutor{Executor: executor, ctx: ctx}
+}
+
+// merge 以绑定的上下文为基础，保留调用方指定的单条命令超时和输出上限
+func (e *boundExecutor) merge(ctx context.Context) context.Context {
+	merged := e.ctx
+	if d, ok := ctx.Value(commandTimeoutKey{}).(time.Duration); ok {
+		merged = WithCommandTimeout(merged, d)
+	}
+	if n, ok := ctx.Value(maxOutputKey{}).(int); ok {
+		merged = WithMaxOutputBytes(merged, n)
+	}
+	return merged
+}
+
+func (e *boundExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return e.Executor.Run(e.merge(ctx), name, args...)
+}
+
+func (e *boundExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	return e.Executor.RunCombined(e.merge(ctx), name, args...)
+}
+
+func (e *boundExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	return e.Executor.ReadFile(e.merge(ctx), path)
+}
+
+func (e *boundExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	return e.Executor.WriteFile(e.merge(ctx), path, data)
+}
+
+func (e *boundExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	return e.Executor.Glob(e.merge(ctx), pattern)
+}
+
+// structToMetrics 将结构化结果转换为指标映射，键与 JSON 字段名一致
+func structToMetrics(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]interface{})
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// newData 根据旧采集器的结果构建 Data
+func newData(collectorName string, dataType DataType, node Node, raw interface{}) (*Data, error) {
+	metrics, err := structToMetrics(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s result: %w", collectorName, err)
+	}
+
+	return &Data{
+		Node:      node.Name,
+		Type:      dataType,
+		Collector: collectorName,
+		Timestamp: time.Now(),
+		Metrics:   metrics,
+		Raw:       raw,
+	}, nil
+}
+
+// validateConfig 校验通用采集器配置
+func validateConfig(config Config) error {
+	if config.Interval < 0 {
+		return fmt.Errorf("interval must not be negative: %d", config.Interval)
+	}
+	return nil
+}
+
+// SystemInfoAdapter 将 SystemCollector 适配为 Collector
+type SystemInfoAdapter struct {
+	config Config
+}
+
+// NewSystemInfoAdapter 创建系统信息采集器适配器
+func NewSystemInfoAdapter(config Config) *SystemInfoAdapter {
+	return &SystemInfoAdapter{config: config}
+}
+
+// Name 返回采集器名称
+func (a *SystemInfoAdapter) Name() string {
+	return "system"
+}
+
+// Collect 在节点上采集系统信息
+func (a *SystemInfoAdapter) Collect(ctx context.Context, node Node) (*Data, error) {
+	sc := NewSystemCollector(a.config, false)
+	info, err := sc.CollectWithExecutor(ctx, node.executor())
+	if err != nil {
+		return nil, err
+	}
+	return newData(a.Name(), DataTypeSystem, node, info)
+}
+
+// Validate 校验配置
+func (a *SystemInfoAdapter) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (a *SystemInfoAdapter) SupportedTypes() []DataType {
+	return []DataType{DataTypeSystem}
+}
+
+// MetricsAdapter 将 MetricsCollector 适配为 Collector
+type MetricsAdapter struct {
+	config         Config
+	sampleInterval time.Duration
+	rateInterval   time.Duration
+}
+
+// NewMetricsAdapter 创建指标采集器适配器
+func NewMetricsAdapter(config Config) *MetricsAdapter {
+	return &MetricsAdapter{config: config}
+}
+
+// Name 返回采集器名称
+func (a *MetricsAdapter) Name() string {
+	return "metrics"
+}
+
+// Collect 在节点上采集系统指标
+func (a *MetricsAdapter) Collect(ctx context.Context, node Node) (*Data, error) {
+	mc := NewMetricsCollectorWithOptions(a.config, MetricsOptions{
+		CPUSampleInterval: a.sampleInterval,
+		RateInterval:      a.rateInterval,
+	})
+	mc.SetExecutor(withContext(ctx, node.executor()))
+
+	metrics, err := mc.CollectMetrics()
+	// 上下文取消时各命令的错误只是表象，直接返回取消原因
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(a.Name(), DataTypeMetrics, node, metrics)
+}
+
+// Validate 校验配置
+func (a *MetricsAdapter) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (a *MetricsAdapter) SupportedTypes() []DataType {
+	return []DataType{DataTypeMetrics}
+}
+
+// Configure 应用配置项：cpu_sample_interval、rate_interval
+func (a *MetricsAdapter) Configure(options map[string]interface{}) error {
+	sampleInterval, err := optionDuration(options, "cpu_sample_interval", a.sampleInterval)
+	if err != nil {
+		return err
+	}
+	rateInterval, err := optionDuration(options, "rate_interval", a.rateInterval)
+	if err != nil {
+		return err
+	}
+	a.sampleInterval = sampleInterval
+	a.rateInterval = rateInterval
+	return nil
+}
+
+// NodeProbeAdapter 将 NodeProbeCollector 适配为 Collector
+type NodeProbeAdapter struct {
+	autoOptimize bool
+	journalDir   string
+}
+
+// NewNodeProbeAdapter 创建 NodeProbe 采集器适配器
+func NewNodeProbeAdapter(autoOptimize bool) *NodeProbeAdapter {
+	return &NodeProbeAdapter{autoOptimize: autoOptimize}
+}
+
+// Name 返回采集器名称
+func (a *NodeProbeAdapter) Name() string {
+	return "nodeprobe"
+}
+
+// Collect 在节点上采集配置信息
+func (a *NodeProbeAdapter) Collect(ctx context.Context, node Node) (*Data, error) {
+	c := NewNodeProbeCollector(a.autoOptimize)
+	c.SetJournalDir(a.journalDir)
+	c.SetExecutor(withContext(ctx, node.executor()))
+
+	data, err := c.Collect()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(a.Name(), DataTypeConfig, node, data)
+}
+
+// Validate 校验配置
+func (a *NodeProbeAdapter) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (a *NodeProbeAdapter) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：auto_optimize、journal_dir
+func (a *NodeProbeAdapter) Configure(options map[string]interface{}) error {
+	autoOptimize, err := optionBool(options, "auto_optimize", a.autoOptimize)
+	if err != nil {
+		return err
+	}
+	journalDir, err := optionString(options, "journal_dir", a.journalDir)
+	if err != nil {
+		return err
+	}
+	a.autoOptimize = autoOptimize
+	a.journalDir = journalDir
+	return nil
+}
+
+// PerfSnapAdapter 将 PerfSnapCollector 适配为 Collector
+type PerfSnapAdapter struct {
+	duration      int
+	generateFlame bool
+	backend       string
+	interval      time.Duration
+	profile       ProfileOptions
+}
+
+// NewPerfSnapAdapter 创建 PerfSnap 采集器适配器
+func NewPerfSnapAdapter(duration int, generateFlame bool) *PerfSnapAdapter {
+	return &PerfSnapAdapter{
+		duration:      duration,
+		generateFlame: generateFlame,
+		backend:       PerfSnapBackendNative,
+	}
+}
+
+// Name 返回采集器名称
+func (a *PerfSnapAdapter) Name() string {
+	return "perfsnap"
+}
+
+// Collect 在节点上采集性能快照
+func (a *PerfSnapAdapter) Collect(ctx context.Context, node Node) (*Data, error) {
+	c := NewPerfSnapCollectorWithOptions(a.duration, a.generateFlame)
+	if err := c.SetBackend(a.backend); err != nil {
+		return nil, err
+	}
+	if err := c.SetInterval(a.interval); err != nil {
+		return nil, err
+	}
+	c.SetProfileOptions(a.profile)
+	c.SetExecutor(withContext(ctx, node.executor()))
+
+	data, err := c.Collect()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(a.Name(), DataTypePerformance, node, data)
+}
+
+// Validate 校验配置
+func (a *PerfSnapAdapter) Validate(config Config) error {
+	if a.duration <= 0 {
+		return fmt.Errorf("perfsnap duration must be positive: %d", a.duration)
+	}
+	if err := validatePerfSnapInterval(a.interval, a.duration); err != nil {
+		return err
+	}
+	return validateConfig(config)
+}
+
+// MinDuration 返回单次采集至少需要的时间
+// 生成火焰图时还要再运行一次 perf record，随后的 perf script 最多使用 perfScriptTimeout
+func (a *PerfSnapAdapter) MinDuration() time.Duration {
+	d := time.Duration(a.duration) * time.Second
+	if a.generateFlame {
+		return 2*d + perfScriptTimeout(a.duration)
+	}
+	return d
+}
+
+// SupportedTypes 返回支持的数据类型
+func (a *PerfSnapAdapter) SupportedTypes() []DataType {
+	return []DataType{DataTypePerformance}
+}
+
+// Configure 应用配置项：duration（秒或 "10m" 形式）、interval、flame_graph、backend，
+// 以及 perf 采样结果的输出选项 profile_dir、profile_pids、profile_top_processes
+func (a *PerfSnapAdapter) Configure(options map[string]interface{}) error {
+	duration, err := optionDuration(options, "duration", time.Duration(a.duration)*time.Second)
+	if err != nil {
+		return err
+	}
+	interval, err := optionDuration(options, "interval", a.interval)
+	if err != nil {
+		return err
+	}
+	generateFlame, err := optionBool(options, "flame_graph", a.generateFlame)
+	if err != nil {
+		return err
+	}
+	backend, err := optionString(options, "backend", a.backend)
+	if err != nil {
+		return err
+	}
+	if err := validatePerfSnapBackend(backend); err != nil {
+		return fmt.Errorf("option backend: %w", err)
+	}
+	profileDir, err := optionString(options, "profile_dir", a.profile.OutputDir)
+	if err != nil {
+		return err
+	}
+	profilePIDs, err := optionIntList(options, "profile_pids", a.profile.PIDs)
+	if err != nil {
+		return err
+	}
+	topProcesses, err := optionBool(options, "profile_top_processes", a.profile.TopProcesses)
+	if err != nil {
+		return err
+	}
+	a.duration = int(duration / time.Second)
+	a.interval = interval
+	a.generateFlame = generateFlame
+	a.backend = backend
+	a.profile = ProfileOptions{
+		OutputDir:    profileDir,
+		PIDs:         profilePIDs,
+		TopProcesses: topProcesses,
+	}
+	return nil
+}
+
+// 注册内置采集器
+func init() {
+	Register("system", NewSystemInfoAdapter(Config{}))
+	Register("metrics", NewMetricsAdapter(Config{}))
+	Register("nodeprobe", NewNodeProbeAdapter(false))
+	Register("perfsnap", NewPerfSnapAdapter(5, false))
+	Register("cputopology", NewCPUTopologyCollector())
+	Register("memory", NewMemoryDetailCollector())
+	Register("inventory", NewInventoryCollector())
+	Register("blockdevices", NewBlockDeviceCollector())
+	Register("diskqueue", NewDiskQueueCollector())
+}
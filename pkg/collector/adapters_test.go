@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNodeExecute(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("sh -c 'uptime | cut -d, -f1'", "up 3 days\n")
+
+	node := Node{Name: "node-a", Executor: executor}
+	output, err := node.Execute(context.Background(), "uptime | cut -d, -f1")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if output != "up 3 days\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+func TestSystemInfoAdapter(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	for cmdline, output := range testRemoteResponses {
+		executor.SetCommand(cmdline, output)
+	}
+	executor.SetFile("/proc/cpuinfo", testRemoteResponses["cat /proc/cpuinfo"])
+	executor.SetFile("/proc/loadavg", testRemoteResponses["cat /proc/loadavg"])
+
+	var c Collector = NewSystemInfoAdapter(Config{})
+	data, err := c.Collect(context.Background(), Node{Name: "node-a", Executor: executor})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if data.Node != "node-a" || data.Type != DataTypeSystem || data.Collector != "system" {
+		t.Errorf("Unexpected envelope: %+v", data)
+	}
+	if data.Metrics["hostname"] != "node-a" {
+		t.Errorf("Expected hostname metric, got %v", data.Metrics["hostname"])
+	}
+	if _, ok := data.Raw.(*SystemInfo); !ok {
+		t.Errorf("Expected raw *SystemInfo, got %T", data.Raw)
+	}
+}
+
+func TestMetricsAdapterCancelled(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommandResult("free -b", FakeCommand{Delay: 5 * time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewMetricsAdapter(Config{}).Collect(ctx, Node{Name: "node-a", Executor: executor})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPerfSnapAdapterValidate(t *testing.T) {
+	if err := NewPerfSnapAdapter(0, false).Validate(Config{}); err == nil {
+		t.Error("Expected error for zero duration")
+	}
+	if err := NewPerfSnapAdapter(30, false).Validate(Config{Interval: -1}); err == nil {
+		t.Error("Expected error for negative interval")
+	}
+	if types := NewPerfSnapAdapter(30, false).SupportedTypes(); len(types) != 1 || types[0] != DataTypePerformance {
+		t.Errorf("Unexpected supported types %v", types)
+	}
+}
@@ -0,0 +1,535 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 块设备类型
+const (
+	BlockTypeDisk      = "disk"
+	BlockTypePartition = "part"
+	BlockTypeRAID      = "raid"
+	BlockTypeLVM       = "lvm"
+	BlockTypeMultipath = "mpath"
+	BlockTypeCrypt     = "crypt"
+	BlockTypeDM        = "dm"
+)
+
+// 磁盘介质
+const (
+	MediaHDD  = "HDD"
+	MediaSSD  = "SSD"
+	MediaNVMe = "NVMe"
+)
+
+// swapMountPoint 交换分区在 MountPoints 中的表示
+const swapMountPoint = "[SWAP]"
+
+// ignoredBlockPrefixes 不属于存储拓扑的虚拟块设备
+var ignoredBlockPrefixes = []string{"loop", "ram", "zram", "fd", "sr"}
+
+// BlockTopology 节点的块设备拓扑
+type BlockTopology struct {
+	Devices     []BlockDevice     `json:"devices" yaml:"devices"`
+	Filesystems []BlockFilesystem `json:"filesystems,omitempty" yaml:"filesystems,omitempty"`
+	Warnings    []string          `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// BlockDevice 一个块设备及其在拓扑中的上下级关系
+type BlockDevice struct {
+	Name         string         `json:"name" yaml:"name"` // 内核名称，如 sda、nvme0n1p1、md0、dm-0
+	Path         string         `json:"path" yaml:"path"` // 设备路径，device-mapper 设备为 /dev/mapper/<name>
+	Type         string         `json:"type" yaml:"type"`
+	Media        string         `json:"media,omitempty" yaml:"media,omitempty"` // 仅磁盘：HDD、SSD 或 NVMe
+	Size         uint64         `json:"size_bytes" yaml:"size_bytes"`
+	Model        string         `json:"model,omitempty" yaml:"model,omitempty"`
+	Vendor       string         `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	Serial       string         `json:"serial,omitempty" yaml:"serial,omitempty"`
+	WWID         string         `json:"wwid,omitempty" yaml:"wwid,omitempty"`
+	Parents      []string       `json:"parents,omitempty" yaml:"parents,omitempty"`   // 所在的磁盘或组成它的设备
+	Children     []string       `json:"children,omitempty" yaml:"children,omitempty"` // 分区和构建在它之上的设备
+	BackingDisks []string       `json:"backing_disks,omitempty" yaml:"backing_disks,omitempty"`
+	MountPoints  []string       `json:"mountpoints,omitempty" yaml:"mountpoints,omitempty"`
+	RAID         *RAIDInfo      `json:"raid,omitempty" yaml:"raid,omitempty"`
+	LVM          *LVMInfo       `json:"lvm,omitempty" yaml:"lvm,omitempty"`
+	Multipath    *MultipathInfo `json:"multipath,omitempty" yaml:"multipath,omitempty"`
+}
+
+// RAIDInfo md 软 RAID 阵列的状态，来自 /proc/mdstat
+type RAIDInfo struct {
+	Level         string   `json:"level" yaml:"level"`
+	State         string   `json:"state" yaml:"state"` // active、inactive 等
+	Devices       int      `json:"devices" yaml:"devices"`
+	ActiveDevices int      `json:"active_devices" yaml:"active_devices"`
+	Degraded      bool     `json:"degraded" yaml:"degraded"`
+	SyncAction    string   `json:"sync_action,omitempty" yaml:"sync_action,omitempty"` // resync、recovery、reshape、check
+	SyncProgress  float64  `json:"sync_progress,omitempty" yaml:"sync_progress,omitempty"`
+	Members       []string `json:"members" yaml:"members"`
+	Failed        []string `json:"failed,omitempty" yaml:"failed,omitempty"`
+	Spares        []string `json:"spares,omitempty" yaml:"spares,omitempty"`
+}
+
+// LVMInfo LVM 成员关系；Role 为 pv 时 LV 为空
+type LVMInfo struct {
+	Role string `json:"role" yaml:"role"` // pv 或 lv
+	VG   string `json:"vg" yaml:"vg"`
+	LV   string `json:"lv,omitempty" yaml:"lv,omitempty"`
+}
+
+// MultipathInfo dm-multipath 关系；Role 为 map 时 Paths 为各条路径，为 path 时 Map 为所属的多路径设备
+type MultipathInfo struct {
+	Role  string   `json:"role" yaml:"role"` // map 或 path
+	WWID  string   `json:"wwid,omitempty" yaml:"wwid,omitempty"`
+	Map   string   `json:"map,omitempty" yaml:"map,omitempty"`
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+// BlockFilesystem 挂载的文件系统及其所在的物理磁盘
+type BlockFilesystem struct {
+	MountPoint string   `json:"mountpoint" yaml:"mountpoint"`
+	Device     string   `json:"device" yaml:"device"` // 块设备内核名称
+	FSType     string   `json:"fstype" yaml:"fstype"`
+	Disks      []string `json:"disks" yaml:"disks"`
+}
+
+// systemMountPoints 所在磁盘视为系统盘的挂载点
+var systemMountPoints = map[string]bool{"/": true, "/boot": true, "/boot/efi": true, swapMountPoint: true}
+
+// SystemDisks 返回承载根文件系统、/boot 和 swap 的物理磁盘
+func (t *BlockTopology) SystemDisks() map[string]bool {
+	system := make(map[string]bool)
+	for _, fs := range t.Filesystems {
+		if systemMountPoints[fs.MountPoint] {
+			for _, disk := range fs.Disks {
+				system[disk] = true
+			}
+		}
+	}
+	for _, dev := range t.Devices {
+		for _, mp := range dev.MountPoints {
+			if mp == swapMountPoint {
+				for _, disk := range dev.BackingDisks {
+					system[disk] = true
+				}
+			}
+		}
+	}
+	return system
+}
+
+// Device 按内核名称查找设备
+func (t *BlockTopology) Device(name string) *BlockDevice {
+	for i := range t.Devices {
+		if t.Devices[i].Name == name {
+			return &t.Devices[i]
+		}
+	}
+	return nil
+}
+
+// BlockDeviceCollector 读取 /sys/block 和 /proc/mdstat 采集块设备拓扑
+type BlockDeviceCollector struct {
+	procRoot string
+	sysRoot  string
+}
+
+// NewBlockDeviceCollector 创建块设备拓扑采集器
+func NewBlockDeviceCollector() *BlockDeviceCollector {
+	return &BlockDeviceCollector{
+		procRoot: DefaultProcRoot,
+		sysRoot:  DefaultSysRoot,
+	}
+}
+
+// Name 返回采集器名称
+func (c *BlockDeviceCollector) Name() string {
+	return "blockdevices"
+}
+
+// Collect 在节点上采集块设备拓扑
+func (c *BlockDeviceCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	topology, err := CollectBlockTopology(ctx, node.executor(), c.procRoot, c.sysRoot)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(c.Name(), DataTypeConfig, node, topology)
+}
+
+// Validate 校验配置
+func (c *BlockDeviceCollector) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *BlockDeviceCollector) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：proc_root、sys_root
+func (c *BlockDeviceCollector) Configure(options map[string]interface{}) error {
+	procRoot, err := optionString(options, "proc_root", c.procRoot)
+	if err != nil {
+		return err
+	}
+	sysRoot, err := optionString(options, "sys_root", c.sysRoot)
+	if err != nil {
+		return err
+	}
+	c.procRoot = procRoot
+	c.sysRoot = sysRoot
+	return nil
+}
+
+// CollectBlockTopology 从 sysRoot/block、procRoot/mdstat 和挂载表构建块设备拓扑
+// /sys/block 不可读时返回错误，mdstat 和挂载表缺失时只记录警告
+func CollectBlockTopology(ctx context.Context, executor Executor, procRoot, sysRoot string) (*BlockTopology, error) {
+	blockDir := filepath.Join(sysRoot, "block")
+	sizes, err := executor.Glob(ctx, filepath.Join(blockDir, "*", "size"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block devices: %w", err)
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("no block devices found under %s", blockDir)
+	}
+
+	var warnings parseWarnings
+	topology := &BlockTopology{}
+	read := func(elem ...string) string {
+		data, err := executor.ReadFile(ctx, filepath.Join(elem...))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	list := func(pattern string) []string {
+		matches, err := executor.Glob(ctx, pattern)
+		if err != nil {
+			warnings.add("sysfs", err.Error())
+			return nil
+		}
+		names := make([]string, 0, len(matches))
+		for _, m := range matches {
+			names = append(names, filepath.Base(m))
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	mapperNames := make(map[string]string) // dm 名称 -> dm-N
+	for _, sizeFile := range sizes {
+		dir := filepath.Dir(sizeFile)
+		name := filepath.Base(dir)
+		if ignoredBlockDevice(name) {
+			continue
+		}
+
+		dev := BlockDevice{
+			Name:    name,
+			Path:    "/dev/" + name,
+			Type:    BlockTypeDisk,
+			Size:    sectorsToBytes(read(sizeFile)),
+			Parents: list(filepath.Join(dir, "slaves", "*")),
+		}
+
+		switch {
+		case strings.HasPrefix(name, "dm-"):
+			mapper := read(dir, "dm", "name")
+			if mapper != "" {
+				dev.Path = "/dev/mapper/" + mapper
+				mapperNames[mapper] = name
+			}
+			classifyDM(&dev, read(dir, "dm", "uuid"), mapper)
+		case strings.HasPrefix(name, "md") && len(dev.Parents) > 0:
+			dev.Type = BlockTypeRAID
+		default:
+			dev.Model = read(dir, "device", "model")
+			dev.Vendor = read(dir, "device", "vendor")
+			dev.Serial = read(dir, "device", "serial")
+			dev.WWID = firstNonEmptyString(read(dir, "wwid"), read(dir, "device", "wwid"))
+			switch {
+			case strings.HasPrefix(name, "nvme"):
+				dev.Media = MediaNVMe
+			case read(dir, "queue", "rotational") == "1":
+				dev.Media = MediaHDD
+			default:
+				dev.Media = MediaSSD
+			}
+		}
+		topology.Devices = append(topology.Devices, dev)
+
+		// 分区位于磁盘目录下，含有 partition 文件
+		partFiles, err := executor.Glob(ctx, filepath.Join(dir, "*", "partition"))
+		if err != nil {
+			warnings.add("sysfs", err.Error())
+		}
+		for _, partFile := range partFiles {
+			partDir := filepath.Dir(partFile)
+			partName := filepath.Base(partDir)
+			topology.Devices = append(topology.Devices, BlockDevice{
+				Name:    partName,
+				Path:    "/dev/" + partName,
+				Type:    BlockTypePartition,
+				Size:    sectorsToBytes(read(partDir, "size")),
+				Parents: []string{name},
+			})
+		}
+	}
+
+	if data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "mdstat")); err == nil {
+		for name, raid := range parseMdstat(string(data)) {
+			if dev := topology.Device(name); dev != nil {
+				raid := raid
+				dev.Type = BlockTypeRAID
+				dev.RAID = &raid
+			}
+		}
+	}
+
+	topology.link()
+
+	if mounts, err := readMountTable(ctx, executor, procRoot); err != nil {
+		warnings.add("mounts", err.Error())
+	} else {
+		for _, mount := range mounts {
+			dev := topology.resolveDevice(mount.Device, mapperNames)
+			if dev == nil {
+				continue
+			}
+			dev.MountPoints = append(dev.MountPoints, mount.MountPoint)
+			topology.Filesystems = append(topology.Filesystems, BlockFilesystem{
+				MountPoint: mount.MountPoint,
+				Device:     dev.Name,
+				FSType:     mount.FSType,
+				Disks:      dev.BackingDisks,
+			})
+		}
+	}
+	if data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "swaps")); err == nil {
+		for _, line := range strings.Split(string(data), "\n")[1:] {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			if dev := topology.resolveDevice(fields[0], mapperNames); dev != nil {
+				dev.MountPoints = append(dev.MountPoints, swapMountPoint)
+			}
+		}
+	}
+
+	topology.Warnings = []string(warnings)
+	return topology, nil
+}
+
+// link 补全反向关系、LVM 和多路径成员信息，并计算每个设备所在的物理磁盘
+func (t *BlockTopology) link() {
+	sort.Slice(t.Devices, func(i, j int) bool { return t.Devices[i].Name < t.Devices[j].Name })
+
+	for i := range t.Devices {
+		dev := &t.Devices[i]
+		for _, parentName := range dev.Parents {
+			parent := t.Device(parentName)
+			if parent == nil {
+				continue
+			}
+			parent.Children = append(parent.Children, dev.Name)
+
+			switch {
+			case dev.LVM != nil && dev.LVM.Role == "lv":
+				parent.LVM = &LVMInfo{Role: "pv", VG: dev.LVM.VG}
+			case dev.Multipath != nil && dev.Multipath.Role == "map":
+				dev.Multipath.Paths = append(dev.Multipath.Paths, parentName)
+				parent.Multipath = &MultipathInfo{Role: "path", WWID: dev.Multipath.WWID, Map: dev.Name}
+			}
+		}
+	}
+
+	for i := range t.Devices {
+		t.Devices[i].BackingDisks = t.backingDisks(t.Devices[i].Name, make(map[string]bool))
+	}
+}
+
+// backingDisks 沿 Parents 向下查找物理磁盘，多路径设备本身视为一块磁盘
+func (t *BlockTopology) backingDisks(name string, visited map[string]bool) []string {
+	if visited[name] {
+		return nil
+	}
+	visited[name] = true
+
+	dev := t.Device(name)
+	if dev == nil {
+		return nil
+	}
+	if dev.Type == BlockTypeDisk || dev.Type == BlockTypeMultipath {
+		return []string{dev.Name}
+	}
+
+	var disks []string
+	seen := make(map[string]bool)
+	for _, parent := range dev.Parents {
+		for _, disk := range t.backingDisks(parent, visited) {
+			if !seen[disk] {
+				seen[disk] = true
+				disks = append(disks, disk)
+			}
+		}
+	}
+	sort.Strings(disks)
+	return disks
+}
+
+// resolveDevice 将挂载表中的设备路径解析为块设备
+func (t *BlockTopology) resolveDevice(path string, mapperNames map[string]string) *BlockDevice {
+	if !strings.HasPrefix(path, "/dev/") {
+		return nil
+	}
+	if mapper := strings.TrimPrefix(path, "/dev/mapper/"); mapper != path {
+		if name, ok := mapperNames[mapper]; ok {
+			return t.Device(name)
+		}
+		return nil
+	}
+	// /dev/<vg>/<lv> 形式的 LVM 路径
+	if parts := strings.Split(strings.TrimPrefix(path, "/dev/"), "/"); len(parts) == 2 {
+		for i := range t.Devices {
+			if lvm := t.Devices[i].LVM; lvm != nil && lvm.Role == "lv" && lvm.VG == parts[0] && lvm.LV == parts[1] {
+				return &t.Devices[i]
+			}
+		}
+		return nil
+	}
+	return t.Device(filepath.Base(path))
+}
+
+// classifyDM 根据 dm/uuid 的前缀确定 device-mapper 设备的类型
+func classifyDM(dev *BlockDevice, uuid, mapper string) {
+	prefix, rest, _ := strings.Cut(uuid, "-")
+	switch prefix {
+	case "LVM":
+		dev.Type = BlockTypeLVM
+		vg, lv := splitLVMName(mapper)
+		dev.LVM = &LVMInfo{Role: "lv", VG: vg, LV: lv}
+	case "mpath":
+		dev.Type = BlockTypeMultipath
+		dev.Multipath = &MultipathInfo{Role: "map", WWID: rest}
+	case "CRYPT":
+		dev.Type = BlockTypeCrypt
+	default:
+		if strings.HasPrefix(prefix, "part") {
+			dev.Type = BlockTypePartition
+		} else {
+			dev.Type = BlockTypeDM
+		}
+	}
+}
+
+// splitLVMName 将 device-mapper 名称拆分为 VG 和 LV，名称中的 "-" 被转义为 "--"
+func splitLVMName(mapper string) (vg, lv string) {
+	for i := 0; i < len(mapper); i++ {
+		if mapper[i] != '-' {
+			continue
+		}
+		if i+1 < len(mapper) && mapper[i+1] == '-' {
+			i++
+			continue
+		}
+		return strings.ReplaceAll(mapper[:i], "--", "-"), strings.ReplaceAll(mapper[i+1:], "--", "-")
+	}
+	return strings.ReplaceAll(mapper, "--", "-"), ""
+}
+
+// mdstatArray 匹配 mdstat 中阵列的第一行，如 "md0 : active raid1 sdb1[1] sda1[0]"
+var mdstatArray = regexp.MustCompile(`^(md\S*)\s*:\s*(\S+)\s+(?:\((?:auto-)?read-only\)\s+)?(\S+)\s*(.*)$`)
+
+// mdstatStatus 匹配成员状态，如 "[2/1] [U_]"
+var mdstatStatus = regexp.MustCompile(`\[(\d+)/(\d+)\]\s+\[([U_]+)\]`)
+
+// mdstatSync 匹配同步进度，如 "recovery = 12.6%"
+var mdstatSync = regexp.MustCompile(`(resync|recovery|reshape|check|repair)\s*=\s*([\d.]+)%`)
+
+// parseMdstat 解析 /proc/mdstat
+func parseMdstat(data string) map[string]RAIDInfo {
+	arrays := make(map[string]RAIDInfo)
+	var current string
+
+	for _, line := range strings.Split(data, "\n") {
+		if m := mdstatArray.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			raid := RAIDInfo{State: m[2], Level: m[3]}
+			// inactive 阵列没有级别，第三列即为成员
+			members := strings.Fields(m[4])
+			if raid.State == "inactive" {
+				members = append([]string{raid.Level}, members...)
+				raid.Level = ""
+			}
+			for _, member := range members {
+				name, _, _ := strings.Cut(member, "[")
+				raid.Members = append(raid.Members, name)
+				switch {
+				case strings.HasSuffix(member, "(F)"):
+					raid.Failed = append(raid.Failed, name)
+				case strings.HasSuffix(member, "(S)"):
+					raid.Spares = append(raid.Spares, name)
+				}
+			}
+			sort.Strings(raid.Members)
+			sort.Strings(raid.Failed)
+			sort.Strings(raid.Spares)
+			arrays[current] = raid
+			continue
+		}
+		if current == "" || !strings.HasPrefix(line, " ") {
+			if strings.TrimSpace(line) == "" {
+				current = ""
+			}
+			continue
+		}
+
+		raid := arrays[current]
+		if m := mdstatStatus.FindStringSubmatch(line); m != nil {
+			raid.Devices, _ = strconv.Atoi(m[1])
+			raid.ActiveDevices, _ = strconv.Atoi(m[2])
+			raid.Degraded = strings.Contains(m[3], "_")
+		}
+		if m := mdstatSync.FindStringSubmatch(line); m != nil {
+			raid.SyncAction = m[1]
+			raid.SyncProgress, _ = strconv.ParseFloat(m[2], 64)
+		}
+		arrays[current] = raid
+	}
+	return arrays
+}
+
+// ignoredBlockDevice 判断是否为不参与存储拓扑的虚拟设备
+func ignoredBlockDevice(name string) bool {
+	for _, prefix := range ignoredBlockPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectorsToBytes 将 sysfs size 文件中的 512 字节扇区数转换为字节
+func sectorsToBytes(value string) uint64 {
+	n, _ := strconv.ParseUint(value, 10, 64)
+	return n * 512
+}
+
+// firstNonEmptyString 返回第一个非空字符串
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBlockTopologyFromFixture(t *testing.T) {
+	topology, err := CollectBlockTopology(context.Background(), NewLocalExecutor(DefaultExecOptions()),
+		"testdata/blockdev/proc", "testdata/blockdev/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topology.Warnings) > 0 {
+		t.Errorf("Unexpected warnings: %v", topology.Warnings)
+	}
+	if topology.Device("loop0") != nil {
+		t.Error("Expected loop devices to be ignored")
+	}
+
+	sda := topology.Device("sda")
+	if sda == nil || sda.Type != BlockTypeDisk || sda.Media != MediaSSD || sda.Size != 480<<30 || sda.Model != "INTEL SSDSC2KB48" {
+		t.Fatalf("Unexpected sda: %+v", sda)
+	}
+	if strings.Join(sda.Children, ",") != "sda1,sda2" {
+		t.Errorf("Expected sda partitions as children, got %v", sda.Children)
+	}
+	if sde := topology.Device("sde"); sde == nil || sde.Media != MediaHDD {
+		t.Errorf("Expected rotational disk to be HDD, got %+v", sde)
+	}
+	if nvme := topology.Device("nvme0n1"); nvme == nil || nvme.Media != MediaNVMe || nvme.Serial != "S4YPNE0R900123" ||
+		nvme.LVM == nil || nvme.LVM.Role != "pv" || nvme.LVM.VG != "data" {
+		t.Errorf("Expected NVMe disk as PV of VG data, got %+v", nvme)
+	}
+
+	md0 := topology.Device("md0")
+	if md0 == nil || md0.RAID == nil || md0.RAID.Level != "raid1" || md0.RAID.Degraded {
+		t.Fatalf("Unexpected md0: %+v", md0)
+	}
+	md1 := topology.Device("md1").RAID
+	if md1 == nil || !md1.Degraded || md1.ActiveDevices != 2 || md1.SyncAction != "recovery" || md1.SyncProgress != 12.6 ||
+		strings.Join(md1.Failed, ",") != "sdg" {
+		t.Errorf("Expected degraded raid5 in recovery, got %+v", md1)
+	}
+
+	root := topology.Device("dm-0")
+	if root == nil || root.Type != BlockTypeLVM || root.LVM.VG != "sys" || root.LVM.LV != "root" || root.Path != "/dev/mapper/sys-root" {
+		t.Fatalf("Unexpected root LV: %+v", root)
+	}
+	if wal := topology.Device("dm-1"); wal == nil || wal.LVM.LV != "pg-wal" {
+		t.Errorf("Expected escaped LV name to be decoded, got %+v", wal)
+	}
+
+	mpath := topology.Device("dm-2")
+	if mpath == nil || mpath.Type != BlockTypeMultipath || strings.Join(mpath.Multipath.Paths, ",") != "sdc,sdd" {
+		t.Fatalf("Unexpected multipath map: %+v", mpath)
+	}
+	if sdc := topology.Device("sdc"); sdc.Multipath == nil || sdc.Multipath.Map != "dm-2" {
+		t.Errorf("Expected sdc to be a multipath path, got %+v", sdc)
+	}
+
+	// 每个文件系统都能追溯到物理磁盘
+	want := map[string]string{
+		"/":              "sda,sdb",
+		"/boot":          "sda",
+		"/var/lib/pgwal": "nvme0n1",
+		"/srv/san":       "dm-2",
+	}
+	if len(topology.Filesystems) != len(want) {
+		t.Fatalf("Expected %d block filesystems, got %+v", len(want), topology.Filesystems)
+	}
+	for _, fs := range topology.Filesystems {
+		if got := strings.Join(fs.Disks, ","); got != want[fs.MountPoint] {
+			t.Errorf("Expected %s on %s, got %s", fs.MountPoint, want[fs.MountPoint], got)
+		}
+	}
+	if sdb1 := topology.Device("sdb1"); strings.Join(sdb1.MountPoints, ",") != swapMountPoint {
+		t.Errorf("Expected sdb1 to be swap, got %v", sdb1.MountPoints)
+	}
+}
+
+func TestSplitLVMName(t *testing.T) {
+	tests := map[string][2]string{
+		"vg0-root":          {"vg0", "root"},
+		"data-pg--wal":      {"data", "pg-wal"},
+		"my--vg-my--lv--01": {"my-vg", "my-lv-01"},
+	}
+	for mapper, want := range tests {
+		if vg, lv := splitLVMName(mapper); vg != want[0] || lv != want[1] {
+			t.Errorf("splitLVMName(%q) = %q, %q, want %v", mapper, vg, lv, want)
+		}
+	}
+}
+
+func TestClassifyNodeDisks(t *testing.T) {
+	topology, err := CollectBlockTopology(context.Background(), NewLocalExecutor(DefaultExecOptions()),
+		"testdata/blockdev/proc", "testdata/blockdev/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sda/sdb 承载根文件系统和 swap，sdc/sdd 是 dm-2 的路径
+	dataDisks, total := classifyNodeDisks(topology)
+	if total != 7 {
+		t.Errorf("Expected 7 disks counting the multipath map once, got %d", total)
+	}
+	want := "/dev/mapper/mpatha 10T mpath,/dev/nvme0n1 3.8T NVMe,/dev/sde 7.8T HDD,/dev/sdf 7.8T HDD,/dev/sdg 7.8T HDD"
+	if got := strings.Join(dataDisks, ","); got != want {
+		t.Errorf("Unexpected data disks:\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,554 @@
+package collector
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BundleVersion 支持包格式版本
+const BundleVersion = 1
+
+// 支持包中的固定文件
+const (
+	BundleManifestFile = "manifest.json"
+	bundleCallsFile    = "raw/calls.json"
+)
+
+// 执行器调用类型
+const (
+	bundleOpRun         = "run"
+	bundleOpRunCombined = "run_combined"
+	bundleOpRead        = "read"
+	bundleOpWrite       = "write"
+	bundleOpGlob        = "glob"
+)
+
+// BundleManifest 支持包清单，记录采集上下文和包内每个文件的哈希
+type BundleManifest struct {
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Node       string            `json:"node"`
+	Target     string            `json:"target"`
+	Collectors []BundleCollector `json:"collectors"`
+	Files      []BundleFile      `json:"files"`
+}
+
+// BundleCollector 采集时运行的采集器及其选项，回放时按相同选项重新解析
+type BundleCollector struct {
+	Name    string                 `json:"name"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// BundleFile 包内文件的大小和 SHA-256
+type BundleFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BundleCall 一次执行器调用的记录
+type BundleCall struct {
+	Op      string   `json:"op"`
+	Key     string   `json:"key"`              // 命令行、文件路径或通配符
+	Output  string   `json:"output,omitempty"` // 输出或文件内容在包内的路径
+	Matches []string `json:"matches,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Missing bool     `json:"missing,omitempty"` // 文件或命令不存在
+	// Truncated 输出超过执行器上限被截断
+	Truncated bool `json:"truncated,omitempty"`
+	// At 调用完成的时间，回放时作为采样时间
+	At time.Time `json:"at"`
+}
+
+// NewBundleCollector 创建采集器记录；time.Duration 选项转换为 "5s" 形式，避免 JSON 中按纳秒整数回读
+func NewBundleCollector(name string, options map[string]interface{}) BundleCollector {
+	normalized := make(map[string]interface{}, len(options))
+	for k, v := range options {
+		if d, ok := v.(time.Duration); ok {
+			v = d.String()
+		}
+		normalized[k] = v
+	}
+	return BundleCollector{Name: name, Options: normalized}
+}
+
+// RecordingExecutor 透传到底层执行器，同时记录所有命令输出和读取的文件，用于生成离线支持包
+type RecordingExecutor struct {
+	executor Executor
+
+	mu       sync.Mutex
+	calls    []BundleCall
+	blobs    map[string][]byte
+	versions map[string][]string // 文件路径 -> 包内各版本内容的路径
+}
+
+// NewRecordingExecutor 创建记录执行器
+func NewRecordingExecutor(executor Executor) *RecordingExecutor {
+	return &RecordingExecutor{
+		executor: executor,
+		blobs:    make(map[string][]byte),
+		versions: make(map[string][]string),
+	}
+}
+
+// Run 执行命令并记录输出
+func (e *RecordingExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := e.executor.Run(ctx, name, args...)
+	e.record(BundleCall{Op: bundleOpRun, Key: bundleCommandKey(name, args...)}, []byte(output), err)
+	return output, err
+}
+
+// RunCombined 执行命令并记录合并输出
+func (e *RecordingExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := e.executor.RunCombined(ctx, name, args...)
+	e.record(BundleCall{Op: bundleOpRunCombined, Key: bundleCommandKey(name, args...)}, []byte(output), err)
+	return output, err
+}
+
+// ReadFile 读取文件并记录内容
+func (e *RecordingExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	data, err := e.executor.ReadFile(ctx, path)
+	e.record(BundleCall{Op: bundleOpRead, Key: path}, data, err)
+	return data, err
+}
+
+// WriteFile 写入文件并记录写入的内容
+func (e *RecordingExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	err := e.executor.WriteFile(ctx, path, data)
+	e.record(BundleCall{Op: bundleOpWrite, Key: path}, data, err)
+	return err
+}
+
+// Glob 列出文件并记录匹配结果
+func (e *RecordingExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	matches, err := e.executor.Glob(ctx, pattern)
+	e.record(BundleCall{Op: bundleOpGlob, Key: pattern, Matches: matches}, nil, err)
+	return matches, err
+}
+
+// Target 返回底层执行器的目标
+func (e *RecordingExecutor) Target() string {
+	return e.executor.Target()
+}
+
+// Calls 返回已记录的调用
+func (e *RecordingExecutor) Calls() []BundleCall {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]BundleCall(nil), e.calls...)
+}
+
+func (e *RecordingExecutor) record(call BundleCall, data []byte, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	call.At = time.Now()
+	if err != nil {
+		call.Error = err.Error()
+		call.Missing = errors.Is(err, os.ErrNotExist) || errors.Is(err, exec.ErrNotFound)
+		call.Truncated = errors.Is(err, ErrOutputTruncated)
+	}
+	if len(data) > 0 {
+		call.Output = e.store(call, data)
+	}
+	e.calls = append(e.calls, call)
+}
+
+// store 保存内容并返回包内路径
+// 命令输出按调用顺序编号；文件按原路径存放，内容变化时追加 .2、.3 等后缀
+func (e *RecordingExecutor) store(call BundleCall, data []byte) string {
+	if call.Op != bundleOpRead && call.Op != bundleOpWrite {
+		name := fmt.Sprintf("raw/commands/%04d_%s.txt", len(e.calls)+1, bundleSlug(call.Key))
+		e.blobs[name] = append([]byte(nil), data...)
+		return name
+	}
+
+	dir := "raw/files"
+	if call.Op == bundleOpWrite {
+		dir = "raw/written"
+	}
+	base := dir + path.Clean("/"+call.Key)
+	versions := e.versions[base]
+	for _, name := range versions {
+		if bytes.Equal(e.blobs[name], data) {
+			return name
+		}
+	}
+
+	name := base
+	if len(versions) > 0 {
+		name = fmt.Sprintf("%s.%d", base, len(versions)+1)
+	}
+	e.blobs[name] = append([]byte(nil), data...)
+	e.versions[base] = append(versions, name)
+	return name
+}
+
+// perfDataPlaceholder 替换命令键中 perf 数据文件路径的占位符
+const perfDataPlaceholder = "<perf.data>"
+
+// bundleCommandKey 返回命令在支持包中的键
+// perf 的 -o/-i 参数是每次运行新建的临时文件，替换为占位符，回放时才能与记录匹配
+func bundleCommandKey(name string, args ...string) string {
+	if name == "perf" {
+		normalized := append([]string(nil), args...)
+		for i := 0; i+1 < len(normalized); i++ {
+			if normalized[i] == "--" {
+				break
+			}
+			if normalized[i] == "-o" || normalized[i] == "-i" {
+				normalized[i+1] = perfDataPlaceholder
+			}
+		}
+		args = normalized
+	}
+	return commandLine(name, args...)
+}
+
+// bundleSlug 将命令行转换为可读的文件名片段
+func bundleSlug(cmdline string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, cmdline)
+	slug = strings.Trim(slug, "_")
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	return slug
+}
+
+// WriteBundle 将记录的原始数据、附加文件（如解析后的 JSON）和清单写为 tar.gz 支持包
+func WriteBundle(w io.Writer, manifest BundleManifest, rec *RecordingExecutor, extra map[string][]byte) error {
+	rec.mu.Lock()
+	files := make(map[string][]byte, len(rec.blobs)+len(extra)+1)
+	for name, data := range rec.blobs {
+		files[name] = data
+	}
+	calls, err := json.MarshalIndent(rec.calls, "", "  ")
+	rec.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle calls: %w", err)
+	}
+	files[bundleCallsFile] = calls
+	for name, data := range extra {
+		files[name] = data
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest.Version = BundleVersion
+	manifest.Files = manifest.Files[:0]
+	for _, name := range names {
+		sum := sha256.Sum256(files[name])
+		manifest.Files = append(manifest.Files, BundleFile{
+			Path:   name,
+			Size:   int64(len(files[name])),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	// 清单放在最前面，便于直接用 tar 查看
+	if err := write(BundleManifestFile, manifestData); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	for _, name := range names {
+		if err := write(name, files[name]); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// Bundle 已读取并校验过的支持包
+type Bundle struct {
+	Manifest BundleManifest
+	files    map[string][]byte
+}
+
+// OpenBundle 读取支持包文件
+func OpenBundle(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+	return ReadBundle(f)
+}
+
+// ReadBundle 读取支持包并按清单校验每个文件的大小和哈希
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle entry %s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	manifestData, ok := files[BundleManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("bundle has no %s", BundleManifestFile)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", manifest.Version)
+	}
+
+	for _, f := range manifest.Files {
+		data, ok := files[f.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", f.Path)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("bundle file %s does not match manifest checksum", f.Path)
+		}
+	}
+
+	return &Bundle{Manifest: manifest, files: files}, nil
+}
+
+// File 返回包内文件内容
+func (b *Bundle) File(name string) ([]byte, bool) {
+	data, ok := b.files[name]
+	return data, ok
+}
+
+// Node 返回在包内原始数据上回放的节点
+func (b *Bundle) Node() (Node, error) {
+	executor, err := b.Executor()
+	if err != nil {
+		return Node{}, err
+	}
+	return Node{Name: b.Manifest.Node, Address: b.Manifest.Target, Executor: executor}, nil
+}
+
+// Executor 返回按记录顺序回放命令输出和文件内容的执行器
+func (b *Bundle) Executor() (*ReplayExecutor, error) {
+	var calls []BundleCall
+	if data, ok := b.files[bundleCallsFile]; ok {
+		if err := json.Unmarshal(data, &calls); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", bundleCallsFile, err)
+		}
+	}
+
+	e := &ReplayExecutor{
+		target:  b.Manifest.Target,
+		results: make(map[string][]replayResult),
+		next:    make(map[string]int),
+	}
+	for _, call := range calls {
+		if call.Op == bundleOpWrite {
+			continue
+		}
+		result := replayResult{call: call}
+		if call.Output != "" {
+			data, ok := b.files[call.Output]
+			if !ok {
+				return nil, fmt.Errorf("bundle is missing %s", call.Output)
+			}
+			result.data = data
+		}
+		key := replayKey(call.Op, call.Key)
+		e.results[key] = append(e.results[key], result)
+	}
+	return e, nil
+}
+
+// ReplayExecutor 回放支持包中记录的执行器调用
+// 同一调用按记录顺序依次返回结果，超出记录次数后重复最后一次；不允许写入
+// 回放不真正等待：时钟取最近回放的调用的记录时间，Sleep 只推进时钟
+type ReplayExecutor struct {
+	target string
+
+	mu      sync.Mutex
+	results map[string][]replayResult
+	next    map[string]int
+	now     time.Time
+}
+
+type replayResult struct {
+	call BundleCall
+	data []byte
+}
+
+func replayKey(op, key string) string {
+	return op + "\x00" + key
+}
+
+// replay 返回下一条记录，没有记录时 ok 为 false
+func (e *ReplayExecutor) replay(op, key string) (replayResult, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	k := replayKey(op, key)
+	results := e.results[k]
+	if len(results) == 0 {
+		return replayResult{}, false
+	}
+	i := e.next[k]
+	if i >= len(results) {
+		i = len(results) - 1
+	} else {
+		e.next[k] = i + 1
+	}
+	// 时钟只前进：重复回放最后一条记录时不会回退
+	if at := results[i].call.At; !at.IsZero() && (e.now.IsZero() || at.After(e.now)) {
+		e.now = at
+	}
+	return results[i], true
+}
+
+// Now 返回回放时钟；旧版支持包没有记录时间，从当前时间开始
+func (e *ReplayExecutor) Now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.now.IsZero() {
+		e.now = time.Now()
+	}
+	return e.now
+}
+
+// Sleep 推进回放时钟，不真正等待
+func (e *ReplayExecutor) Sleep(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.now.IsZero() {
+		e.now = time.Now()
+	}
+	e.now = e.now.Add(d)
+}
+
+// err 还原记录的错误，保留“不存在”和截断的错误类型以便采集器按原逻辑处理
+func (r replayResult) err(notFound error) error {
+	if r.call.Error == "" {
+		return nil
+	}
+	if r.call.Missing {
+		return fmt.Errorf("%s: %w", r.call.Error, notFound)
+	}
+	if r.call.Truncated {
+		return truncatedError(r.call.Key, len(r.data), r.data)
+	}
+	return errors.New(r.call.Error)
+}
+
+func (e *ReplayExecutor) run(op, cmdline string) (string, error) {
+	result, ok := e.replay(op, cmdline)
+	if !ok {
+		return "", fmt.Errorf("%s: not captured in bundle: %w", cmdline, exec.ErrNotFound)
+	}
+	return string(result.data), result.err(exec.ErrNotFound)
+}
+
+// Run 返回记录的命令输出
+func (e *ReplayExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(bundleOpRun, bundleCommandKey(name, args...))
+}
+
+// RunCombined 返回记录的命令合并输出
+func (e *ReplayExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(bundleOpRunCombined, bundleCommandKey(name, args...))
+}
+
+// ReadFile 返回记录的文件内容
+func (e *ReplayExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	result, ok := e.replay(bundleOpRead, path)
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	if err := result.err(os.ErrNotExist); err != nil {
+		if result.call.Truncated {
+			return append([]byte(nil), result.data...), err
+		}
+		return nil, err
+	}
+	return append([]byte(nil), result.data...), nil
+}
+
+// WriteFile 回放时不允许修改节点
+func (e *ReplayExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	return fmt.Errorf("write %s: bundle replay is read-only", path)
+}
+
+// Glob 返回记录的匹配结果
+func (e *ReplayExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	result, ok := e.replay(bundleOpGlob, pattern)
+	if !ok {
+		return nil, nil
+	}
+	return append([]string(nil), result.call.Matches...), result.err(os.ErrNotExist)
+}
+
+// Target 返回采集时的执行目标
+func (e *ReplayExecutor) Target() string {
+	return e.target
+}
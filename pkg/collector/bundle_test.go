@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBundleRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeExecutor("node-a")
+	fake.SetCommand("hostname", "node-a\n")
+	fake.SetCommandResult("sar -n DEV 1 1", FakeCommand{Output: "partial\n", Err: errors.New("exit status 1")})
+	fake.SetFile("/proc/loadavg", "0.10 0.20 0.30 1/100 42\n")
+	fake.SetFile("/proc/stat", "cpu  100 0 50 850 0 0 0 0 0 0\n")
+
+	rec := NewRecordingExecutor(fake)
+	rec.Run(ctx, "hostname")
+	rec.Run(ctx, "sar", "-n", "DEV", "1", "1")
+	rec.Run(ctx, "missing-tool")
+	rec.ReadFile(ctx, "/proc/loadavg")
+	rec.ReadFile(ctx, "/proc/loadavg")
+	rec.ReadFile(ctx, "/proc/stat")
+	fake.SetFile("/proc/stat", "cpu  200 0 80 1620 0 0 0 0 0 0\n")
+	rec.ReadFile(ctx, "/proc/stat")
+	rec.ReadFile(ctx, "/proc/missing")
+	rec.Glob(ctx, "/proc/*")
+
+	manifest := BundleManifest{
+		CreatedAt:  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Node:       "node-a",
+		Target:     rec.Target(),
+		Collectors: []BundleCollector{NewBundleCollector("perfsnap", map[string]interface{}{"interval": 5 * time.Second})},
+	}
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, manifest, rec, map[string][]byte{"result.json": []byte("{}")}); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+
+	bundle, err := ReadBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+	if bundle.Manifest.Node != "node-a" || bundle.Manifest.Version != BundleVersion {
+		t.Errorf("Unexpected manifest: %+v", bundle.Manifest)
+	}
+	if got := bundle.Manifest.Collectors[0].Options["interval"]; got != "5s" {
+		t.Errorf("Expected duration option stored as string, got %v", got)
+	}
+
+	// 相同内容只保存一份，内容变化时保存新版本
+	for _, name := range []string{"result.json", "raw/files/proc/loadavg", "raw/files/proc/stat", "raw/files/proc/stat.2"} {
+		if _, ok := bundle.File(name); !ok {
+			t.Errorf("Expected bundle file %s", name)
+		}
+	}
+	if _, ok := bundle.File("raw/files/proc/loadavg.2"); ok {
+		t.Error("Expected identical file content to be stored once")
+	}
+
+	replay, err := bundle.Executor()
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+	if out, err := replay.Run(ctx, "hostname"); err != nil || out != "node-a\n" {
+		t.Errorf("Unexpected hostname replay: %q, %v", out, err)
+	}
+	if out, err := replay.Run(ctx, "sar", "-n", "DEV", "1", "1"); err == nil || out != "partial\n" {
+		t.Errorf("Expected recorded output and error, got %q, %v", out, err)
+	}
+	if _, err := replay.Run(ctx, "missing-tool"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected exec.ErrNotFound, got %v", err)
+	}
+	if _, err := replay.ReadFile(ctx, "/proc/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+
+	// 同一文件按记录顺序返回，超出后重复最后一次
+	var stats []string
+	for i := 0; i < 3; i++ {
+		data, _ := replay.ReadFile(ctx, "/proc/stat")
+		stats = append(stats, strings.Fields(string(data))[1])
+	}
+	if !reflect.DeepEqual(stats, []string{"100", "200", "200"}) {
+		t.Errorf("Unexpected replay order: %v", stats)
+	}
+
+	if matches, _ := replay.Glob(ctx, "/proc/*"); len(matches) != 2 {
+		t.Errorf("Expected recorded glob matches, got %v", matches)
+	}
+	if err := replay.WriteFile(ctx, "/proc/sys/vm/swappiness", []byte("1\n")); err == nil {
+		t.Error("Expected replay to be read-only")
+	}
+}
+
+func TestReadBundleChecksumMismatch(t *testing.T) {
+	rec := NewRecordingExecutor(NewFakeExecutor("node-a"))
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, BundleManifest{Node: "node-a"}, rec, map[string][]byte{"result.json": []byte(`{"ok":true}`)}); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+
+	// 保留清单，篡改 result.json 后重新打包
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		if header.Name == "result.json" {
+			data = []byte(`{"ok":false}`)
+			header.Size = int64(len(data))
+		}
+		tw.WriteHeader(header)
+		tw.Write(data)
+	}
+	tw.Close()
+	gw.Close()
+
+	if _, err := ReadBundle(&out); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+}
+
+func TestBundleReplayUsesRecordedTime(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeExecutor("node-a")
+	rec := NewRecordingExecutor(fake)
+	for i := 0; i < 3; i++ {
+		fake.SetFile("/proc/stat", fmt.Sprintf("cpu  %d 0 50 %d 0 0 0 0 0 0\n", 100*(i+1), 850*(i+1)))
+		rec.ReadFile(ctx, "/proc/stat")
+	}
+	// 采集时三次采样相隔 5 秒
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range rec.calls {
+		rec.calls[i].At = start.Add(time.Duration(i) * 5 * time.Second)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, BundleManifest{Node: "node-a", Target: "node-a"}, rec, nil); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+	bundle, err := ReadBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+	replay, err := bundle.Executor()
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	c := NewPerfSnapCollectorWithOptions(10, false)
+	c.SetExecutor(replay)
+	if err := c.SetInterval(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	began := time.Now()
+	data := &PerfSnapData{}
+	if err := c.collectNative(data); err != nil {
+		t.Fatalf("collectNative failed: %v", err)
+	}
+	if elapsed := time.Since(began); elapsed > 2*time.Second {
+		t.Errorf("Expected replay without sleeping, took %v", elapsed)
+	}
+
+	series := data.Series
+	if series == nil || len(series.Points) != 2 || series.Duration != 10 {
+		t.Fatalf("Expected 2 points over 10s, got %+v", series)
+	}
+	for i, point := range series.Points {
+		if want := start.Add(time.Duration(i+1) * 5 * time.Second); !point.Timestamp.Equal(want) {
+			t.Errorf("Point %d: expected recorded time %v, got %v", i, want, point.Timestamp)
+		}
+	}
+}
+
+func TestBundlePerfDataPathNormalized(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeExecutor("node-a")
+	fake.SetCommand("perf record -F 99 -a -g -o /tmp/clusterreport_perf.AAAAAA -- sleep 5", "")
+	fake.SetCommand("perf script -i /tmp/clusterreport_perf.AAAAAA", "stacks\n")
+
+	rec := NewRecordingExecutor(fake)
+	rec.Run(ctx, "perf", "record", "-F", "99", "-a", "-g", "-o", "/tmp/clusterreport_perf.AAAAAA", "--", "sleep", "5")
+	rec.Run(ctx, "perf", "script", "-i", "/tmp/clusterreport_perf.AAAAAA")
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, BundleManifest{Node: "node-a", Target: "node-a"}, rec, nil); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+	bundle, err := ReadBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+	replay, err := bundle.Executor()
+	if err != nil {
+		t.Fatalf("Executor failed: %v", err)
+	}
+
+	// 回放时的临时文件名不同，仍应匹配到记录
+	if _, err := replay.Run(ctx, "perf", "record", "-F", "99", "-a", "-g", "-o", "/tmp/clusterreport_perf.BBBBBB", "--", "sleep", "5"); err != nil {
+		t.Errorf("Expected perf record to replay with a different data path, got %v", err)
+	}
+	if out, err := replay.Run(ctx, "perf", "script", "-i", "/tmp/clusterreport_perf.BBBBBB"); err != nil || out != "stacks\n" {
+		t.Errorf("Expected perf script to replay with a different data path, got %q, %v", out, err)
+	}
+	// -- 之后是被采样的命令，参数不做替换
+	if got := bundleCommandKey("perf", "record", "-o", "/tmp/x", "--", "tool", "-o", "out"); got != "perf record -o '<perf.data>' -- tool -o out" {
+		t.Errorf("Unexpected normalized key: %s", got)
+	}
+}
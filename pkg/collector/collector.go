@@ -1,8 +1,10 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -13,10 +15,82 @@ type Config struct {
 	Enabled  bool     `yaml:"enabled"`
 }
 
+// LegacyCollector 旧版采集器接口，不感知节点和取消
+// 新的采集器应实现 Collector 接口；旧版采集器通过 LegacyAdapter 注册
+type LegacyCollector interface {
+	Collect() (interface{}, error)
+	Name() string
+}
+
+// DataType 采集数据类型
+type DataType string
+
+const (
+	DataTypeSystem      DataType = "system"      // 系统基础信息（SystemCollector）
+	DataTypeMetrics     DataType = "metrics"     // 系统资源指标（MetricsCollector）
+	DataTypeConfig      DataType = "config"      // 节点配置（NodeProbe）
+	DataTypePerformance DataType = "performance" // 性能快照（PerfSnap）
+	DataTypeCustom      DataType = "custom"      // 自定义数据
+)
+
+// Node 采集目标节点
+type Node struct {
+	Name     string            `json:"name"`
+	Address  string            `json:"address,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Executor Executor          `json:"-"` // 节点上的命令执行器，为空时在本地执行
+}
+
+// LocalNode 返回代表本机的节点
+func LocalNode() Node {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "localhost"
+	}
+	return Node{
+		Name:     name,
+		Address:  "localhost",
+		Executor: NewLocalExecutor(DefaultExecOptions()),
+	}
+}
+
+// executor 返回节点的执行器，未设置时使用本地执行器
+func (n Node) executor() Executor {
+	if n.Executor != nil {
+		return n.Executor
+	}
+	return NewLocalExecutor(DefaultExecOptions())
+}
+
+// Execute 在节点上通过 sh -c 执行 shell 命令，返回标准输出和标准错误的合并内容
+func (n Node) Execute(ctx context.Context, command string) (string, error) {
+	return n.executor().RunCombined(ctx, "sh", "-c", command)
+}
+
+// Data 采集数据
+type Data struct {
+	Node      string                 `json:"node"`
+	Type      DataType               `json:"type"`
+	Collector string                 `json:"collector,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Metrics   map[string]interface{} `json:"metrics"`
+	Raw       interface{}            `json:"raw,omitempty"` // 采集器的原始结构化结果
+}
+
 // Collector 采集器接口
+// 采集器在指定节点上采集数据，所有命令都应通过 Node 的执行器执行并遵守 ctx 的取消
 type Collector interface {
-	Collect() (interface{}, error)
+	// Name 采集器名称
 	Name() string
+
+	// Collect 在节点上采集数据
+	Collect(ctx context.Context, node Node) (*Data, error)
+
+	// Validate 校验采集器配置
+	Validate(config Config) error
+
+	// SupportedTypes 采集器产生的数据类型
+	SupportedTypes() []DataType
 }
 
 // BaseCollector 基础采集器
@@ -65,7 +139,7 @@ type Report struct {
 	Timestamp time.Time   `json:"timestamp"`
 	Collector string      `json:"collector"`
 	Data      interface{} `json:"data"`
-	Status    string      `json:"status"`
+	Status    string      `json:"status"` // success, partial, failed
 	Error     string      `json:"error,omitempty"`
 }
 
@@ -84,6 +158,22 @@ func (bc *BaseCollector) GenerateReport() (*Report, error) {
 		return report, err
 	}
 
+	// 部分分区采集失败时数据仍然可用，标记为 partial
+	if metrics, ok := data.(*SystemMetrics); ok && metrics.Partial() {
+		report.Status = "partial"
+		report.Error = metrics.sectionError()
+	}
+
 	report.Data = data
 	return report, nil
 }
+
+// parseWarnings 采集过程中命令输出的解析问题，按工具名加前缀后随采集结果输出
+type parseWarnings []string
+
+// add 记录一个工具的解析问题
+func (w *parseWarnings) add(tool string, warnings ...string) {
+	for _, warning := range warnings {
+		*w = append(*w, tool+": "+warning)
+	}
+}
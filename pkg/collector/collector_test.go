@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"runtime"
 	"testing"
 	"time"
 )
@@ -290,3 +291,53 @@ func BenchmarkCollectCPUMetrics(b *testing.B) {
 		_, _ = mc.collectCPUMetrics()
 	}
 }
+
+func TestCollectMetricsPartialFailure(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("metrics sections are only collected on Linux")
+	}
+
+	// 模拟 /proc/meminfo 不可读的节点
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/proc/stat", "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 100 0 50 800 50 0 0 0 0 0\n")
+	executor.SetFile("/proc/loadavg", "0.50 0.40 0.30 1/200 12345\n")
+	executor.SetFile("/proc/net/dev", "Inter-|   Receive\n face |bytes\n"+
+		"  eth0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n")
+	executor.SetFile("/proc/self/mountinfo", "22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n"+
+		"23 22 0:21 / /proc rw,nosuid shared:12 - proc proc rw\n")
+	executor.SetCommand("stat -f -c '%S %b %f %a %c %d' -- /", "4096 100 75 70 1000 900\n")
+	executor.SetCommand("ps -eo state", "S\nR\nS\n")
+	executor.SetFile("/proc/diskstats", "   8       0 sda 100 0 800 50 200 0 1600 100 0 120 150\n")
+
+	mc := NewMetricsCollectorWithOptions(Config{}, MetricsOptions{CPUSampleInterval: time.Millisecond})
+	mc.SetExecutor(executor)
+
+	metrics, err := mc.CollectMetrics()
+	if err != nil {
+		t.Fatalf("Expected partial result, got error: %v", err)
+	}
+
+	if !metrics.Partial() || metrics.HasSection(SectionMemory) {
+		t.Errorf("Expected memory section error, got %+v", metrics.SectionErrors)
+	}
+	if len(metrics.SectionErrors) != 1 {
+		t.Errorf("Expected only memory to fail, got %+v", metrics.SectionErrors)
+	}
+	if metrics.CPU.LoadAvg1 != 0.5 || len(metrics.Disk) != 1 || len(metrics.Network) != 1 ||
+		len(metrics.DiskIO) != 1 || metrics.Process.Total != 2 {
+		t.Errorf("Expected other sections to be kept, got %+v", metrics)
+	}
+}
+
+func TestCollectMetricsAllSectionsFailed(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("metrics sections are only collected on Linux")
+	}
+
+	mc := NewMetricsCollector(Config{})
+	mc.SetExecutor(NewFakeExecutor("node-a"))
+
+	if _, err := mc.CollectMetrics(); err == nil {
+		t.Error("Expected error when every section fails")
+	}
+}
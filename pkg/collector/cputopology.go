@@ -0,0 +1,560 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 漏洞缓解状态，对应 /sys/devices/system/cpu/vulnerabilities 中每个文件的前缀
+const (
+	VulnStatusNotAffected   = "not_affected"
+	VulnStatusMitigated     = "mitigated"
+	VulnStatusSMTVulnerable = "smt_vulnerable" // 已缓解，但开启 SMT 时仍可跨超线程利用
+	VulnStatusVulnerable    = "vulnerable"
+	VulnStatusUnknown       = "unknown"
+)
+
+// isaFeatures 需要单独关注的指令集扩展，x86 取自 flags，ARM 取自 Features
+var isaFeatures = []string{
+	"sse4_2", "avx", "avx2", "fma", "avx512f", "avx512bw", "avx512vl", "avx512_vnni", "avx512_bf16",
+	"amx_tile", "aes", "sha_ni", "vaes",
+	"asimd", "sha2", "atomics", "sve", "sve2", "bf16", "i8mm",
+}
+
+// armImplementers ARM CPU implementer 编号到厂商名称的映射
+var armImplementers = map[string]string{
+	"0x41": "ARM",
+	"0x42": "Broadcom",
+	"0x43": "Cavium",
+	"0x46": "Fujitsu",
+	"0x48": "HiSilicon",
+	"0x4e": "NVIDIA",
+	"0x51": "Qualcomm",
+	"0x61": "Apple",
+	"0xc0": "Ampere",
+}
+
+// armParts 常见服务器 ARM 核心的 implementer/part 到型号的映射
+var armParts = map[string]string{
+	"0x41/0xd03": "Cortex-A53",
+	"0x41/0xd08": "Cortex-A72",
+	"0x41/0xd0c": "Neoverse-N1",
+	"0x41/0xd40": "Neoverse-V1",
+	"0x41/0xd49": "Neoverse-N2",
+	"0x41/0xd4f": "Neoverse-V2",
+	"0x48/0xd01": "Kunpeng-920",
+	"0xc0/0xac3": "Ampere-1",
+	"0xc0/0xac4": "Ampere-1a",
+}
+
+// CPUTopology CPU 拓扑、特性和漏洞缓解状态
+type CPUTopology struct {
+	Vendor          string             `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	Model           string             `json:"model" yaml:"model"`
+	Stepping        string             `json:"stepping,omitempty" yaml:"stepping,omitempty"`
+	Microcode       string             `json:"microcode,omitempty" yaml:"microcode,omitempty"`
+	OnlineCPUs      int                `json:"online_cpus" yaml:"online_cpus"`
+	Sockets         int                `json:"sockets" yaml:"sockets"`
+	CoresPerSocket  int                `json:"cores_per_socket" yaml:"cores_per_socket"`
+	ThreadsPerCore  int                `json:"threads_per_core" yaml:"threads_per_core"`
+	NUMANodes       []CPUNUMANode      `json:"numa_nodes,omitempty" yaml:"numa_nodes,omitempty"`
+	Caches          []CPUCache         `json:"caches,omitempty" yaml:"caches,omitempty"`
+	Frequency       CPUFrequency       `json:"frequency" yaml:"frequency"`
+	ISA             []string           `json:"isa,omitempty" yaml:"isa,omitempty"` // isaFeatures 中节点支持的扩展
+	Flags           []string           `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Vulnerabilities []CPUVulnerability `json:"vulnerabilities,omitempty" yaml:"vulnerabilities,omitempty"`
+	Signature       string             `json:"signature" yaml:"signature"` // 型号和拓扑摘要，用于比较集群内的硬件规格
+	Warnings        []string           `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// CPUNUMANode NUMA 节点及其 CPU
+type CPUNUMANode struct {
+	ID   int    `json:"id" yaml:"id"`
+	CPUs string `json:"cpus" yaml:"cpus"` // cpulist 格式，如 0-15,32-47
+}
+
+// CPUCache 一级缓存的规格，Size 为单个实例的大小
+type CPUCache struct {
+	Level     int    `json:"level" yaml:"level"`
+	Type      string `json:"type" yaml:"type"` // Data、Instruction 或 Unified
+	Size      uint64 `json:"size_bytes" yaml:"size_bytes"`
+	Instances int    `json:"instances" yaml:"instances"`
+}
+
+// CPUFrequency CPU0 的频率范围（MHz）和调频设置
+type CPUFrequency struct {
+	MinMHz     float64 `json:"min_mhz,omitempty" yaml:"min_mhz,omitempty"`
+	MaxMHz     float64 `json:"max_mhz,omitempty" yaml:"max_mhz,omitempty"`
+	CurrentMHz float64 `json:"current_mhz,omitempty" yaml:"current_mhz,omitempty"`
+	Driver     string  `json:"driver,omitempty" yaml:"driver,omitempty"`
+	Governor   string  `json:"governor,omitempty" yaml:"governor,omitempty"`
+}
+
+// CPUVulnerability 一项 CPU 漏洞的内核报告
+type CPUVulnerability struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// Vulnerable 返回未缓解的漏洞名称
+func (t *CPUTopology) Vulnerable() []string {
+	var names []string
+	for _, v := range t.Vulnerabilities {
+		if v.Status == VulnStatusVulnerable {
+			names = append(names, v.Name)
+		}
+	}
+	return names
+}
+
+// CPUTopologyCollector 读取 sysfs 和 /proc/cpuinfo 采集 CPU 拓扑
+// 不依赖 lscpu，x86 和 ARM 节点输出相同的结构
+type CPUTopologyCollector struct {
+	procRoot string
+	sysRoot  string
+}
+
+// NewCPUTopologyCollector 创建 CPU 拓扑采集器
+func NewCPUTopologyCollector() *CPUTopologyCollector {
+	return &CPUTopologyCollector{
+		procRoot: DefaultProcRoot,
+		sysRoot:  DefaultSysRoot,
+	}
+}
+
+// Name 返回采集器名称
+func (c *CPUTopologyCollector) Name() string {
+	return "cputopology"
+}
+
+// Collect 在节点上采集 CPU 拓扑
+func (c *CPUTopologyCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	topology, err := CollectCPUTopology(ctx, node.executor(), c.procRoot, c.sysRoot)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(c.Name(), DataTypeConfig, node, topology)
+}
+
+// Validate 校验配置
+func (c *CPUTopologyCollector) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *CPUTopologyCollector) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：proc_root、sys_root（在容器中采集宿主机时使用）
+func (c *CPUTopologyCollector) Configure(options map[string]interface{}) error {
+	procRoot, err := optionString(options, "proc_root", c.procRoot)
+	if err != nil {
+		return err
+	}
+	sysRoot, err := optionString(options, "sys_root", c.sysRoot)
+	if err != nil {
+		return err
+	}
+	c.procRoot = procRoot
+	c.sysRoot = sysRoot
+	return nil
+}
+
+// CollectCPUTopology 从 procRoot 和 sysRoot 读取 CPU 拓扑
+// 只有 /proc/cpuinfo 不可读时返回错误，sysfs 中缺失的部分记录到 Warnings
+func CollectCPUTopology(ctx context.Context, executor Executor, procRoot, sysRoot string) (*CPUTopology, error) {
+	data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "cpuinfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cpuinfo: %w", err)
+	}
+	processors := parseCPUInfoBlocks(string(data))
+	if len(processors) == 0 {
+		return nil, fmt.Errorf("no processors found in cpuinfo")
+	}
+
+	var warnings parseWarnings
+	cpuDir := filepath.Join(sysRoot, "devices", "system", "cpu")
+	readSys := func(elem ...string) (string, error) {
+		data, err := executor.ReadFile(ctx, filepath.Join(append([]string{cpuDir}, elem...)...))
+		return strings.TrimSpace(string(data)), err
+	}
+
+	topology := &CPUTopology{}
+	first := processors[0]
+	topology.Vendor = cpuVendor(first)
+	topology.Model = cpuModel(first)
+	topology.Stepping = first["stepping"]
+	topology.Microcode = first["microcode"]
+
+	// 在线 CPU 列表，sysfs 不可用时以 cpuinfo 中的处理器为准
+	online := make([]int, 0, len(processors))
+	if list, err := readSys("online"); err == nil {
+		if online, err = parseCPUList(list); err != nil {
+			warnings.add("cpu/online", err.Error())
+		}
+	}
+	if len(online) == 0 {
+		for i, p := range processors {
+			id, err := strconv.Atoi(p["processor"])
+			if err != nil {
+				id = i
+			}
+			online = append(online, id)
+		}
+	}
+	topology.OnlineCPUs = len(online)
+
+	cores, sockets := cpuCoresFromCPUInfo(processors)
+	if cores == 0 {
+		cores, sockets = cpuCoresFromSysfs(online, readSys, &warnings)
+	}
+	if sockets > 0 && cores > 0 {
+		topology.Sockets = sockets
+		topology.CoresPerSocket = cores / sockets
+		topology.ThreadsPerCore = topology.OnlineCPUs / cores
+	}
+
+	topology.NUMANodes = readNUMANodes(ctx, executor, sysRoot, &warnings)
+	topology.Caches = readCPUCaches(ctx, executor, cpuDir, topology.OnlineCPUs, &warnings)
+	topology.Frequency = readCPUFrequency(readSys, first)
+
+	flags := first["flags"]
+	if flags == "" {
+		flags = first["features"]
+	}
+	topology.Flags = strings.Fields(flags)
+	sort.Strings(topology.Flags)
+	topology.ISA = selectISAFeatures(topology.Flags)
+
+	topology.Vulnerabilities = readCPUVulnerabilities(ctx, executor, cpuDir, &warnings)
+
+	topology.Signature = fmt.Sprintf("%s %dS/%dC/%dT", topology.Model, topology.Sockets, topology.CoresPerSocket, topology.ThreadsPerCore)
+	topology.Warnings = []string(warnings)
+	return topology, nil
+}
+
+// parseCPUInfoBlocks 将 /proc/cpuinfo 按空行拆分为每个处理器的键值对，键统一为小写
+// ARM 内核在所有处理器之后还会输出一段不含 processor 的全局信息，合并到每个处理器中
+func parseCPUInfoBlocks(data string) []map[string]string {
+	var (
+		blocks  []map[string]string
+		current map[string]string
+		global  = make(map[string]string)
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if _, ok := current["processor"]; ok {
+			blocks = append(blocks, current)
+		} else {
+			for k, v := range current {
+				global[k] = v
+			}
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			if strings.TrimSpace(line) == "" {
+				flush()
+			}
+			continue
+		}
+		if current == nil {
+			current = make(map[string]string)
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	flush()
+
+	for _, block := range blocks {
+		for k, v := range global {
+			if _, ok := block[k]; !ok {
+				block[k] = v
+			}
+		}
+	}
+	return blocks
+}
+
+// cpuVendor 返回 CPU 厂商
+func cpuVendor(p map[string]string) string {
+	if vendor := p["vendor_id"]; vendor != "" {
+		return vendor
+	}
+	if name, ok := armImplementers[strings.ToLower(p["cpu implementer"])]; ok {
+		return name
+	}
+	return p["cpu implementer"]
+}
+
+// cpuModel 返回 CPU 型号，ARM 上 cpuinfo 没有 model name 时按 implementer/part 推断
+func cpuModel(p map[string]string) string {
+	for _, key := range []string{"model name", "cpu model", "processor name"} {
+		if model := p[key]; model != "" {
+			return model
+		}
+	}
+
+	implementer := strings.ToLower(p["cpu implementer"])
+	part := strings.ToLower(p["cpu part"])
+	if implementer == "" || part == "" {
+		return p["hardware"]
+	}
+	if model, ok := armParts[implementer+"/"+part]; ok {
+		return model
+	}
+	vendor := armImplementers[implementer]
+	if vendor == "" {
+		vendor = "implementer " + implementer
+	}
+	return fmt.Sprintf("%s part %s", vendor, part)
+}
+
+// cpuCoresFromCPUInfo 根据 x86 cpuinfo 中的 physical id 和 core id 计算物理核心数和插槽数
+// 任一处理器缺少这两项时返回 0
+func cpuCoresFromCPUInfo(processors []map[string]string) (cores, sockets int) {
+	coreSet := make(map[string]bool)
+	socketSet := make(map[string]bool)
+	for _, p := range processors {
+		socket, ok1 := p["physical id"]
+		core, ok2 := p["core id"]
+		if !ok1 || !ok2 {
+			return 0, 0
+		}
+		socketSet[socket] = true
+		coreSet[socket+"/"+core] = true
+	}
+	return len(coreSet), len(socketSet)
+}
+
+// cpuCoresFromSysfs 根据 sysfs 中每个 CPU 的 topology 计算物理核心数和插槽数
+func cpuCoresFromSysfs(online []int, readSys func(elem ...string) (string, error), warnings *parseWarnings) (cores, sockets int) {
+	coreSet := make(map[string]bool)
+	socketSet := make(map[string]bool)
+	for _, cpu := range online {
+		dir := fmt.Sprintf("cpu%d", cpu)
+		socket, err := readSys(dir, "topology", "physical_package_id")
+		if err != nil {
+			warnings.add("cpu/topology", err.Error())
+			return 0, 0
+		}
+		core, err := readSys(dir, "topology", "core_id")
+		if err != nil {
+			warnings.add("cpu/topology", err.Error())
+			return 0, 0
+		}
+		socketSet[socket] = true
+		coreSet[socket+"/"+core] = true
+	}
+	return len(coreSet), len(socketSet)
+}
+
+// readNUMANodes 读取每个 NUMA 节点的 CPU 列表
+func readNUMANodes(ctx context.Context, executor Executor, sysRoot string, warnings *parseWarnings) []CPUNUMANode {
+	dirs, err := executor.Glob(ctx, filepath.Join(sysRoot, "devices", "system", "node", "node[0-9]*"))
+	if err != nil {
+		warnings.add("node", err.Error())
+		return nil
+	}
+
+	var nodes []CPUNUMANode
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		data, err := executor.ReadFile(ctx, filepath.Join(dir, "cpulist"))
+		if err != nil {
+			warnings.add("node", err.Error())
+			continue
+		}
+		nodes = append(nodes, CPUNUMANode{ID: id, CPUs: strings.TrimSpace(string(data))})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// readCPUCaches 读取 CPU0 的各级缓存，实例数按共享该缓存的 CPU 数推算
+func readCPUCaches(ctx context.Context, executor Executor, cpuDir string, onlineCPUs int, warnings *parseWarnings) []CPUCache {
+	dirs, err := executor.Glob(ctx, filepath.Join(cpuDir, "cpu0", "cache", "index[0-9]*"))
+	if err != nil {
+		warnings.add("cache", err.Error())
+		return nil
+	}
+
+	var caches []CPUCache
+	for _, dir := range dirs {
+		read := func(name string) string {
+			data, err := executor.ReadFile(ctx, filepath.Join(dir, name))
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSpace(string(data))
+		}
+
+		level, err := strconv.Atoi(read("level"))
+		if err != nil {
+			warnings.add("cache", fmt.Sprintf("%s: invalid level", filepath.Base(dir)))
+			continue
+		}
+		cache := CPUCache{Level: level, Type: read("type"), Instances: 1}
+		if cache.Size, err = parseCacheSize(read("size")); err != nil {
+			warnings.add("cache", fmt.Sprintf("%s: %v", filepath.Base(dir), err))
+		}
+		if shared, err := parseCPUList(read("shared_cpu_list")); err == nil && len(shared) > 0 && onlineCPUs > 0 {
+			cache.Instances = (onlineCPUs + len(shared) - 1) / len(shared)
+		}
+		caches = append(caches, cache)
+	}
+	sort.Slice(caches, func(i, j int) bool {
+		if caches[i].Level != caches[j].Level {
+			return caches[i].Level < caches[j].Level
+		}
+		return caches[i].Type < caches[j].Type
+	})
+	return caches
+}
+
+// readCPUFrequency 读取 CPU0 的 cpufreq 设置，虚拟机上没有 cpufreq 时只有 cpuinfo 中的当前频率
+func readCPUFrequency(readSys func(elem ...string) (string, error), first map[string]string) CPUFrequency {
+	var freq CPUFrequency
+	khz := func(name string) float64 {
+		value, err := readSys("cpu0", "cpufreq", name)
+		if err != nil {
+			return 0
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0
+		}
+		return n / 1000
+	}
+
+	freq.MinMHz = khz("cpuinfo_min_freq")
+	freq.MaxMHz = khz("cpuinfo_max_freq")
+	freq.CurrentMHz = khz("scaling_cur_freq")
+	if freq.CurrentMHz == 0 {
+		freq.CurrentMHz, _ = strconv.ParseFloat(first["cpu mhz"], 64)
+	}
+	freq.Driver, _ = readSys("cpu0", "cpufreq", "scaling_driver")
+	freq.Governor, _ = readSys("cpu0", "cpufreq", "scaling_governor")
+	return freq
+}
+
+// readCPUVulnerabilities 读取内核报告的 CPU 漏洞缓解状态
+func readCPUVulnerabilities(ctx context.Context, executor Executor, cpuDir string, warnings *parseWarnings) []CPUVulnerability {
+	files, err := executor.Glob(ctx, filepath.Join(cpuDir, "vulnerabilities", "*"))
+	if err != nil {
+		warnings.add("vulnerabilities", err.Error())
+		return nil
+	}
+
+	var vulns []CPUVulnerability
+	for _, file := range files {
+		data, err := executor.ReadFile(ctx, file)
+		if err != nil {
+			warnings.add("vulnerabilities", err.Error())
+			continue
+		}
+		detail := strings.TrimSpace(string(data))
+		vulns = append(vulns, CPUVulnerability{
+			Name:   filepath.Base(file),
+			Status: vulnerabilityStatus(detail),
+			Detail: detail,
+		})
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].Name < vulns[j].Name })
+	return vulns
+}
+
+// vulnerabilityStatus 按内核输出的前缀归类漏洞状态
+// itlb_multihit 等虚拟化相关的漏洞带 "KVM: " 前缀；缓解措施后的 "SMT vulnerable" 单独归类
+func vulnerabilityStatus(detail string) string {
+	detail = strings.TrimPrefix(detail, "KVM: ")
+	switch {
+	case strings.HasPrefix(detail, "Mitigation") && strings.Contains(detail, "SMT vulnerable"):
+		return VulnStatusSMTVulnerable
+	case strings.HasPrefix(detail, "Not affected"):
+		return VulnStatusNotAffected
+	case strings.HasPrefix(detail, "Mitigation"):
+		return VulnStatusMitigated
+	case strings.HasPrefix(detail, "Vulnerable"):
+		return VulnStatusVulnerable
+	default:
+		return VulnStatusUnknown
+	}
+}
+
+// selectISAFeatures 返回 flags 中需要关注的指令集扩展，按 isaFeatures 的顺序
+func selectISAFeatures(flags []string) []string {
+	present := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		present[f] = true
+	}
+
+	var isa []string
+	seen := make(map[string]bool)
+	for _, f := range isaFeatures {
+		if present[f] && !seen[f] {
+			seen[f] = true
+			isa = append(isa, f)
+		}
+	}
+	return isa
+}
+
+// parseCPUList 解析内核的 cpulist 格式，如 "0-3,8,10-11"
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q", list)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil || end < start {
+				return nil, fmt.Errorf("invalid cpu list %q", list)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// parseCacheSize 解析 sysfs 缓存大小，如 "32K"、"1024K"、"32M"
+func parseCacheSize(size string) (uint64, error) {
+	multiplier := uint64(1)
+	switch {
+	case strings.HasSuffix(size, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(size, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(size, "G"):
+		multiplier = 1 << 30
+	}
+	n, err := strconv.ParseUint(strings.TrimRight(size, "KMG"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache size %q", size)
+	}
+	return n * multiplier, nil
+}
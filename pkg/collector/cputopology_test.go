@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCPUTopologyFromFixture(t *testing.T) {
+	topology, err := CollectCPUTopology(context.Background(), NewLocalExecutor(DefaultExecOptions()), "testdata/proc", "testdata/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topology.Warnings) > 0 {
+		t.Errorf("Unexpected warnings: %v", topology.Warnings)
+	}
+
+	if topology.Vendor != "GenuineIntel" || !strings.Contains(topology.Model, "Gold 6330") || topology.Microcode != "0xd000389" {
+		t.Errorf("Unexpected identity: %+v", topology)
+	}
+	if topology.OnlineCPUs != 8 || topology.Sockets != 2 || topology.CoresPerSocket != 2 || topology.ThreadsPerCore != 2 {
+		t.Errorf("Expected 8 CPUs as 2S/2C/2T, got %d CPUs %dS/%dC/%dT",
+			topology.OnlineCPUs, topology.Sockets, topology.CoresPerSocket, topology.ThreadsPerCore)
+	}
+	if len(topology.NUMANodes) != 2 || topology.NUMANodes[1].CPUs != "2-3,6-7" {
+		t.Errorf("Unexpected NUMA nodes: %+v", topology.NUMANodes)
+	}
+
+	if len(topology.Caches) != 4 {
+		t.Fatalf("Expected 4 caches, got %+v", topology.Caches)
+	}
+	l3 := topology.Caches[3]
+	if l3.Level != 3 || l3.Size != 43008<<10 || l3.Instances != 2 {
+		t.Errorf("Expected one 42MiB L3 per socket, got %+v", l3)
+	}
+	if l1 := topology.Caches[0]; l1.Level != 1 || l1.Type != "Data" || l1.Instances != 4 {
+		t.Errorf("Expected L1d per core, got %+v", l1)
+	}
+
+	freq := topology.Frequency
+	if freq.MinMHz != 800 || freq.MaxMHz != 3100 || freq.Governor != "powersave" {
+		t.Errorf("Unexpected frequency: %+v", freq)
+	}
+	if got := strings.Join(topology.ISA, ","); got != "sse4_2,avx,avx2,fma,avx512f,avx512bw,avx512vl,aes,sha_ni" {
+		t.Errorf("Unexpected ISA features: %s", got)
+	}
+
+	if len(topology.Vulnerabilities) != 6 {
+		t.Fatalf("Expected 6 vulnerabilities, got %+v", topology.Vulnerabilities)
+	}
+	// itlb_multihit 带 KVM 前缀；l1tf 已缓解但 SMT 仍有风险，不算未缓解
+	if got := strings.Join(topology.Vulnerable(), ","); got != "itlb_multihit,mds" {
+		t.Errorf("Expected itlb_multihit and mds vulnerable, got %s", got)
+	}
+	for _, v := range topology.Vulnerabilities {
+		if v.Name == "l1tf" && v.Status != VulnStatusSMTVulnerable {
+			t.Errorf("Expected l1tf to be SMT vulnerable, got %+v", v)
+		}
+	}
+	if topology.Signature != "Intel(R) Xeon(R) Gold 6330 CPU @ 2.00GHz 2S/2C/2T" {
+		t.Errorf("Unexpected signature: %s", topology.Signature)
+	}
+}
+
+func TestCPUTopologyARM(t *testing.T) {
+	// ARM 的 cpuinfo 没有 model name 和 physical id，拓扑来自 sysfs
+	cpuinfo := ""
+	for _, id := range []string{"0", "1", "2", "3"} {
+		cpuinfo += "processor\t: " + id + "\n" +
+			"BogoMIPS\t: 50.00\n" +
+			"Features\t: fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics sve\n" +
+			"CPU implementer\t: 0x41\n" +
+			"CPU part\t: 0xd40\n\n"
+	}
+
+	executor := NewFakeExecutor("arm-node")
+	executor.SetFile("/proc/cpuinfo", cpuinfo)
+	executor.SetFile("/sys/devices/system/cpu/online", "0-3\n")
+	for i, socket := range []string{"0", "0", "1", "1"} {
+		dir := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/topology/", i)
+		executor.SetFile(dir+"physical_package_id", socket+"\n")
+		executor.SetFile(dir+"core_id", fmt.Sprintf("%d\n", i))
+	}
+
+	topology, err := CollectCPUTopology(context.Background(), executor, "/proc", "/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topology.Vendor != "ARM" || topology.Model != "Neoverse-V1" {
+		t.Errorf("Expected model from implementer/part, got %s %s", topology.Vendor, topology.Model)
+	}
+	if topology.Sockets != 2 || topology.CoresPerSocket != 2 || topology.ThreadsPerCore != 1 {
+		t.Errorf("Expected 2S/2C/1T from sysfs, got %dS/%dC/%dT", topology.Sockets, topology.CoresPerSocket, topology.ThreadsPerCore)
+	}
+	if got := strings.Join(topology.ISA, ","); got != "aes,asimd,sha2,atomics,sve" {
+		t.Errorf("Unexpected ISA features: %s", got)
+	}
+	if model := parseCPUModel(cpuinfo); model != "Neoverse-V1" {
+		t.Errorf("Expected parseCPUModel to handle ARM, got %q", model)
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-2,8,10-11\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cpus) != 6 || cpus[3] != 8 || cpus[5] != 11 {
+		t.Errorf("Unexpected cpus: %v", cpus)
+	}
+	if _, err := parseCPUList("3-1"); err == nil {
+		t.Error("Expected error for reversed range")
+	}
+}
+
+func TestVulnerabilityStatus(t *testing.T) {
+	tests := map[string]string{
+		"Not affected":                                  VulnStatusNotAffected,
+		"Mitigation: Enhanced IBRS":                     VulnStatusMitigated,
+		"Mitigation: Clear CPU buffers; SMT vulnerable": VulnStatusSMTVulnerable,
+		"Vulnerable: Clear CPU buffers attempted, no microcode; SMT vulnerable": VulnStatusVulnerable,
+		"KVM: Mitigation: Split huge pages":                                     VulnStatusMitigated,
+		"KVM: Mitigation: VMX disabled":                                         VulnStatusMitigated,
+		"KVM: Vulnerable":                                                       VulnStatusVulnerable,
+		"Unknown: Dependent on hypervisor status":                               VulnStatusUnknown,
+	}
+	for detail, want := range tests {
+		if got := vulnerabilityStatus(detail); got != want {
+			t.Errorf("vulnerabilityStatus(%q) = %s, want %s", detail, got, want)
+		}
+	}
+}
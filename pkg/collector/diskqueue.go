@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiskQueueReport 节点上所有块设备的 I/O 队列参数
+type DiskQueueReport struct {
+	Devices  []DiskQueue `json:"devices" yaml:"devices"`
+	Warnings []string    `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// DiskQueue 一个块设备的 /sys/block/<dev>/queue 参数
+type DiskQueue struct {
+	Device         string   `json:"device" yaml:"device"`
+	Type           string   `json:"type" yaml:"type"`                       // 同 BlockDevice.Type
+	Media          string   `json:"media,omitempty" yaml:"media,omitempty"` // 仅磁盘：HDD、SSD 或 NVMe
+	Rotational     bool     `json:"rotational" yaml:"rotational"`
+	DataDisk       bool     `json:"data_disk" yaml:"data_disk"` // 磁盘不承载根文件系统、/boot 和 swap
+	Scheduler      string   `json:"scheduler" yaml:"scheduler"`
+	Schedulers     []string `json:"schedulers,omitempty" yaml:"schedulers,omitempty"` // 可选的调度器
+	NrRequests     int      `json:"nr_requests" yaml:"nr_requests"`
+	ReadAheadKB    int      `json:"read_ahead_kb" yaml:"read_ahead_kb"`
+	MaxSectorsKB   int      `json:"max_sectors_kb" yaml:"max_sectors_kb"`
+	MaxHWSectorsKB int      `json:"max_hw_sectors_kb,omitempty" yaml:"max_hw_sectors_kb,omitempty"`
+	WriteCache     string   `json:"write_cache,omitempty" yaml:"write_cache,omitempty"` // write back 或 write through
+}
+
+// Device 按内核名称查找设备
+func (r *DiskQueueReport) Device(name string) *DiskQueue {
+	for i := range r.Devices {
+		if r.Devices[i].Device == name {
+			return &r.Devices[i]
+		}
+	}
+	return nil
+}
+
+// DiskQueueCollector 读取 /sys/block/*/queue 采集 I/O 调度器、队列深度和预读等参数
+type DiskQueueCollector struct {
+	procRoot string
+	sysRoot  string
+}
+
+// NewDiskQueueCollector 创建磁盘队列参数采集器
+func NewDiskQueueCollector() *DiskQueueCollector {
+	return &DiskQueueCollector{
+		procRoot: DefaultProcRoot,
+		sysRoot:  DefaultSysRoot,
+	}
+}
+
+// Name 返回采集器名称
+func (c *DiskQueueCollector) Name() string {
+	return "diskqueue"
+}
+
+// Collect 在节点上采集磁盘队列参数
+func (c *DiskQueueCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	report, err := CollectDiskQueues(ctx, node.executor(), c.procRoot, c.sysRoot)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(c.Name(), DataTypeConfig, node, report)
+}
+
+// Validate 校验配置
+func (c *DiskQueueCollector) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *DiskQueueCollector) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：proc_root、sys_root
+func (c *DiskQueueCollector) Configure(options map[string]interface{}) error {
+	procRoot, err := optionString(options, "proc_root", c.procRoot)
+	if err != nil {
+		return err
+	}
+	sysRoot, err := optionString(options, "sys_root", c.sysRoot)
+	if err != nil {
+		return err
+	}
+	c.procRoot = procRoot
+	c.sysRoot = sysRoot
+	return nil
+}
+
+// CollectDiskQueues 读取 sysRoot/block/*/queue 下的队列参数
+// 设备类型和是否为数据盘来自块设备拓扑，拓扑不可用时只记录警告
+func CollectDiskQueues(ctx context.Context, executor Executor, procRoot, sysRoot string) (*DiskQueueReport, error) {
+	blockDir := filepath.Join(sysRoot, "block")
+	files, err := executor.Glob(ctx, filepath.Join(blockDir, "*", "queue", "read_ahead_kb"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block device queues: %w", err)
+	}
+
+	var warnings parseWarnings
+	report := &DiskQueueReport{}
+
+	topology, err := CollectBlockTopology(ctx, executor, procRoot, sysRoot)
+	if err != nil {
+		warnings.add("blockdevices", err.Error())
+		topology = &BlockTopology{}
+	}
+	system := topology.SystemDisks()
+
+	for _, file := range files {
+		queueDir := filepath.Dir(file)
+		name := filepath.Base(filepath.Dir(queueDir))
+		if ignoredBlockDevice(name) {
+			continue
+		}
+
+		read := func(attr string) string {
+			data, err := executor.ReadFile(ctx, filepath.Join(queueDir, attr))
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSpace(string(data))
+		}
+		readInt := func(attr string) int {
+			value := read(attr)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				warnings.add("sysfs", fmt.Sprintf("%s: invalid %s %q", name, attr, value))
+			}
+			return n
+		}
+
+		queue := DiskQueue{
+			Device:         name,
+			Type:           BlockTypeDisk,
+			Rotational:     read("rotational") == "1",
+			NrRequests:     readInt("nr_requests"),
+			ReadAheadKB:    readInt("read_ahead_kb"),
+			MaxSectorsKB:   readInt("max_sectors_kb"),
+			MaxHWSectorsKB: readInt("max_hw_sectors_kb"),
+			WriteCache:     read("write_cache"),
+		}
+		queue.Scheduler, queue.Schedulers = parseSelected(read("scheduler"))
+
+		if dev := topology.Device(name); dev != nil {
+			queue.Type = dev.Type
+			queue.Media = dev.Media
+			switch {
+			case dev.Multipath != nil && dev.Multipath.Role == "path":
+				queue.DataDisk = !system[dev.Multipath.Map]
+			case dev.Type == BlockTypeDisk || dev.Type == BlockTypeMultipath:
+				queue.DataDisk = !system[name]
+			}
+		} else if len(topology.Devices) == 0 {
+			// 没有拓扑时按名称和 rotational 推断介质
+			switch {
+			case strings.HasPrefix(name, "nvme"):
+				queue.Media = MediaNVMe
+			case queue.Rotational:
+				queue.Media = MediaHDD
+			default:
+				queue.Media = MediaSSD
+			}
+		}
+		report.Devices = append(report.Devices, queue)
+	}
+	if len(report.Devices) == 0 {
+		return nil, fmt.Errorf("no block device queues found under %s", blockDir)
+	}
+
+	sort.Slice(report.Devices, func(i, j int) bool { return report.Devices[i].Device < report.Devices[j].Device })
+	report.Warnings = []string(warnings)
+	return report, nil
+}
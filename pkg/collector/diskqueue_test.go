@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCollectDiskQueues(t *testing.T) {
+	report, err := CollectDiskQueues(context.Background(), NewLocalExecutor(DefaultExecOptions()),
+		"testdata/blockdev/proc", "testdata/blockdev/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Warnings) > 0 {
+		t.Errorf("Unexpected warnings: %v", report.Warnings)
+	}
+	if report.Device("loop0") != nil {
+		t.Error("Expected loop devices to be ignored")
+	}
+
+	nvme := report.Device("nvme0n1")
+	if nvme == nil || nvme.Media != MediaNVMe || nvme.Scheduler != "mq-deadline" || nvme.NrRequests != 1023 ||
+		nvme.ReadAheadKB != 128 || nvme.WriteCache != "write back" || !nvme.DataDisk {
+		t.Fatalf("Unexpected nvme0n1 queue: %+v", nvme)
+	}
+	if strings.Join(nvme.Schedulers, ",") != "mq-deadline,kyber,none" {
+		t.Errorf("Unexpected available schedulers: %v", nvme.Schedulers)
+	}
+
+	if sda := report.Device("sda"); sda == nil || sda.DataDisk || sda.Media != MediaSSD || sda.MaxSectorsKB != 1280 || sda.MaxHWSectorsKB != 32767 {
+		t.Errorf("Expected sda to be the SSD system disk, got %+v", sda)
+	}
+	if sdf := report.Device("sdf"); sdf == nil || !sdf.Rotational || !sdf.DataDisk || sdf.Scheduler != "none" {
+		t.Errorf("Expected sdf to be an HDD data disk without scheduler, got %+v", sdf)
+	}
+	if sdc := report.Device("sdc"); sdc == nil || !sdc.DataDisk || sdc.WriteCache != "write through" {
+		t.Errorf("Expected multipath path sdc to be a data disk, got %+v", sdc)
+	}
+	if md1 := report.Device("md1"); md1 == nil || md1.Type != BlockTypeRAID || md1.DataDisk || md1.ReadAheadKB != 8192 || md1.Scheduler != "none" {
+		t.Errorf("Unexpected md1 queue: %+v", md1)
+	}
+}
@@ -0,0 +1,666 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultCommandTimeout 单条命令的默认超时时间
+	DefaultCommandTimeout = 30 * time.Second
+	// DefaultMaxOutputBytes 单条命令输出的默认上限，超出时返回 ErrOutputTruncated
+	DefaultMaxOutputBytes = 8 << 20
+)
+
+// ErrCommandTimeout 命令执行超时
+var ErrCommandTimeout = errors.New("command timed out")
+
+// ErrOutputTruncated 命令输出或文件内容超过上限被截断
+var ErrOutputTruncated = errors.New("output truncated")
+
+// OutputTruncatedError 输出超过上限的错误，保留截断前的部分输出
+// 调用方可以用 errors.Is(err, ErrOutputTruncated) 判断，并按需使用 Output
+type OutputTruncatedError struct {
+	Command string
+	Limit   int
+	Output  []byte
+}
+
+func (e *OutputTruncatedError) Error() string {
+	return fmt.Sprintf("%s: %v at %d bytes", e.Command, ErrOutputTruncated, e.Limit)
+}
+
+// Unwrap 返回 ErrOutputTruncated
+func (e *OutputTruncatedError) Unwrap() error {
+	return ErrOutputTruncated
+}
+
+// truncatedError 生成输出被截断的错误
+func truncatedError(command string, limit int, output []byte) error {
+	return &OutputTruncatedError{Command: command, Limit: limit, Output: output}
+}
+
+// commandLocale 执行命令时使用的区域设置，避免节点的区域设置改变数字格式、时间格式和表头
+var commandLocale = []string{"LC_ALL=C", "LANG=C"}
+
+// Executor 命令执行器接口
+// 采集器通过 Executor 执行命令和读写文件，从而可以透明地运行在本地、远程或测试环境中
+type Executor interface {
+	// Run 执行命令，返回标准输出
+	Run(ctx context.Context, name string, args ...string) (string, error)
+	// RunCombined 执行命令，返回标准输出和标准错误的合并内容
+	RunCombined(ctx context.Context, name string, args ...string) (string, error)
+	// ReadFile 读取文件内容
+	ReadFile(ctx context.Context, path string) ([]byte, error)
+	// WriteFile 写入文件内容（覆盖）
+	WriteFile(ctx context.Context, path string, data []byte) error
+	// Glob 按通配符列出文件
+	Glob(ctx context.Context, pattern string) ([]string, error)
+	// Target 返回执行目标描述，如 localhost 或 host:port
+	Target() string
+}
+
+// ExecOptions 执行器选项
+type ExecOptions struct {
+	Timeout        time.Duration // 单条命令超时时间
+	MaxOutputBytes int           // 单条命令输出上限（字节）
+}
+
+// DefaultExecOptions 返回默认执行器选项
+func DefaultExecOptions() ExecOptions {
+	return ExecOptions{
+		Timeout:        DefaultCommandTimeout,
+		MaxOutputBytes: DefaultMaxOutputBytes,
+	}
+}
+
+// normalize 用默认值补全未设置的选项
+func (o ExecOptions) normalize() ExecOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultCommandTimeout
+	}
+	if o.MaxOutputBytes <= 0 {
+		o.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	return o
+}
+
+type commandTimeoutKey struct{}
+
+// WithCommandTimeout 为上下文中的命令指定超时时间，覆盖执行器的默认值
+// 用于 perf record 这类已知耗时较长的命令
+func WithCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, commandTimeoutKey{}, timeout)
+}
+
+type maxOutputKey struct{}
+
+// WithMaxOutputBytes 为上下文中的命令指定输出上限，覆盖执行器的默认值
+// 用于 perf script 这类输出远大于普通命令的场景
+func WithMaxOutputBytes(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, maxOutputKey{}, limit)
+}
+
+// outputLimit 返回单条命令的输出上限
+func outputLimit(ctx context.Context, opts ExecOptions) int {
+	if n, ok := ctx.Value(maxOutputKey{}).(int); ok && n > 0 {
+		return n
+	}
+	return opts.MaxOutputBytes
+}
+
+// commandContext 为单条命令创建带超时的上下文
+func commandContext(ctx context.Context, opts ExecOptions) (context.Context, context.CancelFunc) {
+	timeout := opts.Timeout
+	if d, ok := ctx.Value(commandTimeoutKey{}).(time.Duration); ok && d > 0 {
+		timeout = d
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError 根据上下文状态生成超时或取消错误
+func timeoutError(ctx context.Context, command string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", command, ErrCommandTimeout)
+	}
+	return fmt.Errorf("%s: %w", command, ctx.Err())
+}
+
+// limitedBuffer 只保留前 limit 字节的缓冲区，超出部分丢弃并记录截断
+// 设置了 w 时输出直接写入 w 而不在内存中保留，用于 perf script 这类大输出
+type limitedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	w         io.Writer
+	n         int
+	limit     int
+	truncated bool
+	err       error // 写入 w 失败的错误
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if remain := b.limit - b.n; remain < len(p) {
+		if remain > 0 {
+			b.write(p[:remain])
+		}
+		b.truncated = true
+		// 返回完整长度，避免子进程因写入失败而提前退出
+		return len(p), nil
+	}
+	b.write(p)
+	return len(p), nil
+}
+
+func (b *limitedBuffer) write(p []byte) {
+	b.n += len(p)
+	if b.w == nil {
+		b.buf.Write(p)
+		return
+	}
+	if b.err == nil {
+		_, b.err = b.w.Write(p)
+	}
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// result 返回已保留的输出；命令成功但输出被截断时返回 ErrOutputTruncated
+func (b *limitedBuffer) result(command string, err error) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	output := b.buf.String()
+	if err == nil && b.err != nil {
+		err = fmt.Errorf("%s: failed to write output: %w", command, b.err)
+	}
+	if err == nil && b.truncated {
+		err = truncatedError(command, b.limit, []byte(output))
+	}
+	return output, err
+}
+
+// streamExecutor 能将命令输出直接写入 io.Writer 的执行器
+type streamExecutor interface {
+	runStream(ctx context.Context, w io.Writer, name string, args ...string) error
+}
+
+// runStream 执行命令并将标准输出写入 w，本地和 SSH 执行器不在内存中缓冲完整输出
+// 其他执行器（如测试和支持包记录）退回 Run；输出超过上限时返回 ErrOutputTruncated
+func runStream(ctx context.Context, executor Executor, w io.Writer, name string, args ...string) error {
+	if bound, ok := executor.(*boundExecutor); ok {
+		ctx = bound.merge(ctx)
+		executor = bound.Executor
+	}
+	if stream, ok := executor.(streamExecutor); ok {
+		return stream.runStream(ctx, w, name, args...)
+	}
+	output, err := executor.Run(ctx, name, args...)
+	if _, werr := io.WriteString(w, output); werr != nil && err == nil {
+		err = fmt.Errorf("%s: failed to write output: %w", commandLine(name, args...), werr)
+	}
+	return err
+}
+
+// commandLine 将命令和参数拼接为 shell 命令行，必要时对参数加引号
+func commandLine(name string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(name))
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote 对 shell 参数加单引号，安全字符组成的参数保持原样
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./=:,+@%", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// LocalExecutor 本地执行器
+type LocalExecutor struct {
+	opts ExecOptions
+}
+
+// NewLocalExecutor 创建本地执行器
+func NewLocalExecutor(opts ExecOptions) *LocalExecutor {
+	return &LocalExecutor{opts: opts.normalize()}
+}
+
+// Target 返回执行目标
+func (e *LocalExecutor) Target() string {
+	return "localhost"
+}
+
+// Run 执行本地命令，返回标准输出
+func (e *LocalExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, false, nil, name, args...)
+}
+
+// RunCombined 执行本地命令，返回标准输出和标准错误
+func (e *LocalExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, true, nil, name, args...)
+}
+
+func (e *LocalExecutor) runStream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	_, err := e.run(ctx, false, w, name, args...)
+	return err
+}
+
+// run 执行本地命令；w 非空时标准输出写入 w，返回的输出为空
+func (e *LocalExecutor) run(ctx context.Context, combined bool, w io.Writer, name string, args ...string) (string, error) {
+	ctx, cancel := commandContext(ctx, e.opts)
+	defer cancel()
+
+	stdout := &limitedBuffer{w: w, limit: outputLimit(ctx, e.opts)}
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), commandLocale...)
+	cmd.Stdout = stdout
+	if combined {
+		cmd.Stderr = stdout
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	// 在独立 goroutine 中等待，避免处于 D 状态（如卡死的 NFS）的进程阻塞调用方
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return stdout.result(commandLine(name, args...), err)
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		return "", timeoutError(ctx, commandLine(name, args...))
+	}
+}
+
+// ReadFile 读取本地文件，受超时和输出上限约束，超出上限时返回部分内容和 ErrOutputTruncated
+func (e *LocalExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := commandContext(ctx, e.opts)
+	defer cancel()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		f, err := os.Open(path)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer f.Close()
+		// 多读一个字节以判断文件是否超出上限
+		limit := outputLimit(ctx, e.opts)
+		data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+		if err == nil && len(data) > limit {
+			data = data[:limit]
+			err = truncatedError("read "+path, limit, data)
+		}
+		done <- result{data: data, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, timeoutError(ctx, "read "+path)
+	}
+}
+
+// WriteFile 写入本地文件
+func (e *LocalExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	return os.WriteFile(path, data, 0644)
+}
+
+// Glob 按通配符列出本地文件
+func (e *LocalExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// executorClock 执行器可选实现的时钟
+// 回放支持包的执行器用它返回采集时记录的时间，并跳过采样间隔的等待
+type executorClock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// clockOf 返回执行器的时钟，执行器未提供时使用系统时钟
+func clockOf(executor Executor) executorClock {
+	if bound, ok := executor.(*boundExecutor); ok {
+		executor = bound.Executor
+	}
+	if clock, ok := executor.(executorClock); ok {
+		return clock
+	}
+	return realClock{}
+}
+
+// isLocal 判断执行器最终是否在本地执行
+func isLocal(executor Executor) bool {
+	if bound, ok := executor.(*boundExecutor); ok {
+		executor = bound.Executor
+	}
+	_, ok := executor.(*LocalExecutor)
+	return ok
+}
+
+// SSHExecutor 基于 SSH 连接的远程执行器
+type SSHExecutor struct {
+	client *ssh.Client
+	target string
+	opts   ExecOptions
+}
+
+// NewSSHExecutor 基于已建立的 SSH 连接创建远程执行器
+func NewSSHExecutor(client *ssh.Client, target string, opts ExecOptions) *SSHExecutor {
+	return &SSHExecutor{
+		client: client,
+		target: target,
+		opts:   opts.normalize(),
+	}
+}
+
+// DialSSHExecutor 建立 SSH 连接并创建远程执行器，使用完毕后需调用 Close
+func DialSSHExecutor(ctx context.Context, host string, sshConfig *ssh.ClientConfig, opts ExecOptions) (*SSHExecutor, error) {
+	client, err := dialSSH(ctx, host, sshConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewSSHExecutor(client, host, opts), nil
+}
+
+// Close 关闭 SSH 连接
+func (e *SSHExecutor) Close() error {
+	return e.client.Close()
+}
+
+// Target 返回执行目标
+func (e *SSHExecutor) Target() string {
+	return e.target
+}
+
+// Run 执行远程命令，返回标准输出
+func (e *SSHExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, commandLine(name, args...), false, nil, nil)
+}
+
+// RunCombined 执行远程命令，返回标准输出和标准错误
+func (e *SSHExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, commandLine(name, args...), true, nil, nil)
+}
+
+func (e *SSHExecutor) runStream(ctx context.Context, w io.Writer, name string, args ...string) error {
+	_, err := e.run(ctx, commandLine(name, args...), false, nil, w)
+	return err
+}
+
+// run 执行远程命令；w 非空时标准输出写入 w，返回的输出为空
+func (e *SSHExecutor) run(ctx context.Context, command string, combined bool, stdin io.Reader, w io.Writer) (string, error) {
+	ctx, cancel := commandContext(ctx, e.opts)
+	defer cancel()
+
+	session, err := e.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	stdout := &limitedBuffer{w: w, limit: outputLimit(ctx, e.opts)}
+	session.Stdout = stdout
+	if combined {
+		session.Stderr = stdout
+	}
+	if stdin != nil {
+		session.Stdin = stdin
+	}
+
+	// 很多 sshd 不接受 Setenv，区域设置以环境变量前缀的形式传入
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(strings.Join(commandLocale, " ") + " " + command)
+	}()
+
+	select {
+	case err := <-done:
+		return stdout.result(command, err)
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		session.Close()
+		return "", timeoutError(ctx, command)
+	}
+}
+
+// ReadFile 读取远程文件，超出上限时返回部分内容和 ErrOutputTruncated
+func (e *SSHExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	output, err := e.Run(ctx, "cat", path)
+	if errors.Is(err, ErrOutputTruncated) {
+		return []byte(output), err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output), nil
+}
+
+// WriteFile 写入远程文件
+func (e *SSHExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	_, err := e.run(ctx, "cat > "+shellQuote(path), false, bytes.NewReader(data), nil)
+	return err
+}
+
+// Glob 按通配符列出远程文件
+// pattern 由 shell 展开，只应传入采集器内部的固定模式
+func (e *SSHExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	script := fmt.Sprintf(`for f in %s; do [ -e "$f" ] && echo "$f"; done; true`, pattern)
+	output, err := e.Run(ctx, "sh", "-c", script)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// FakeCommand 模拟命令的执行结果
+type FakeCommand struct {
+	Output string        // 命令输出
+	Err    error         // 命令返回的错误
+	Delay  time.Duration // 模拟执行耗时，用于测试超时
+}
+
+// FakeExecutor 脚本化的模拟执行器，用于测试和离线回放
+// 命令以 "name arg1 arg2" 形式的命令行作为键
+type FakeExecutor struct {
+	mu       sync.Mutex
+	target   string
+	opts     ExecOptions
+	commands map[string]FakeCommand
+	files    map[string][]byte
+	written  map[string][]byte
+	calls    []string
+}
+
+// NewFakeExecutor 创建模拟执行器
+func NewFakeExecutor(target string) *FakeExecutor {
+	return &FakeExecutor{
+		target:   target,
+		opts:     DefaultExecOptions(),
+		commands: make(map[string]FakeCommand),
+		files:    make(map[string][]byte),
+		written:  make(map[string][]byte),
+	}
+}
+
+// SetOptions 设置执行器选项
+func (e *FakeExecutor) SetOptions(opts ExecOptions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.opts = opts.normalize()
+}
+
+// SetCommand 设置命令的模拟输出
+func (e *FakeExecutor) SetCommand(cmdline string, output string) {
+	e.SetCommandResult(cmdline, FakeCommand{Output: output})
+}
+
+// SetCommandResult 设置命令的模拟结果
+func (e *FakeExecutor) SetCommandResult(cmdline string, result FakeCommand) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commands[cmdline] = result
+}
+
+// SetFile 设置文件的模拟内容
+func (e *FakeExecutor) SetFile(path string, content string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.files[path] = []byte(content)
+}
+
+// Written 返回通过 WriteFile 写入的内容
+func (e *FakeExecutor) Written(path string) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	data, ok := e.written[path]
+	return data, ok
+}
+
+// Calls 返回已执行的命令行和文件访问记录
+func (e *FakeExecutor) Calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+// Target 返回执行目标
+func (e *FakeExecutor) Target() string {
+	return e.target
+}
+
+// Run 返回模拟命令的输出
+func (e *FakeExecutor) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, commandLine(name, args...))
+}
+
+// RunCombined 返回模拟命令的输出
+func (e *FakeExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	return e.run(ctx, commandLine(name, args...))
+}
+
+func (e *FakeExecutor) run(ctx context.Context, cmdline string) (string, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, cmdline)
+	result, ok := e.commands[cmdline]
+	opts := e.opts
+	e.mu.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("%s: %w", cmdline, exec.ErrNotFound)
+	}
+
+	if result.Delay > 0 {
+		ctx, cancel := commandContext(ctx, opts)
+		defer cancel()
+		select {
+		case <-time.After(result.Delay):
+		case <-ctx.Done():
+			return "", timeoutError(ctx, cmdline)
+		}
+	}
+
+	output := result.Output
+	if limit := outputLimit(ctx, opts); len(output) > limit {
+		output = output[:limit]
+		if result.Err == nil {
+			return output, truncatedError(cmdline, limit, []byte(output))
+		}
+	}
+	return output, result.Err
+}
+
+// ReadFile 返回模拟文件内容
+func (e *FakeExecutor) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, "read "+path)
+
+	data, ok := e.files[path]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	if limit := outputLimit(ctx, e.opts); len(data) > limit {
+		data = append([]byte(nil), data[:limit]...)
+		return data, truncatedError("read "+path, limit, data)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile 记录写入的内容，并更新模拟文件
+func (e *FakeExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, "write "+path)
+
+	e.written[path] = append([]byte(nil), data...)
+	e.files[path] = append([]byte(nil), data...)
+	return nil
+}
+
+// Glob 按通配符匹配模拟文件
+func (e *FakeExecutor) Glob(ctx context.Context, pattern string) ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var matches []string
+	for path := range e.files {
+		if ok, err := filepath.Match(pattern, path); err != nil {
+			return nil, err
+		} else if ok {
+			matches = append(matches, path)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
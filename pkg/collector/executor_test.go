@@ -0,0 +1,347 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalExecutorTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	executor := NewLocalExecutor(ExecOptions{Timeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	_, err := executor.Run(context.Background(), "sleep", "5")
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Fatalf("Expected ErrCommandTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected command to be abandoned quickly, took %v", elapsed)
+	}
+}
+
+func TestLocalExecutorCommandTimeoutOverride(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	executor := NewLocalExecutor(ExecOptions{Timeout: 50 * time.Millisecond})
+	ctx := WithCommandTimeout(context.Background(), 5*time.Second)
+
+	if _, err := executor.Run(ctx, "sleep", "0.2"); err != nil {
+		t.Fatalf("Expected overridden timeout to allow command, got %v", err)
+	}
+}
+
+func TestLocalExecutorOutputCap(t *testing.T) {
+	if _, err := exec.LookPath("head"); err != nil {
+		t.Skip("head not available")
+	}
+
+	executor := NewLocalExecutor(ExecOptions{Timeout: 5 * time.Second, MaxOutputBytes: 1024})
+
+	output, err := executor.Run(context.Background(), "head", "-c", "100000", "/dev/zero")
+	var truncated *OutputTruncatedError
+	if !errors.As(err, &truncated) || truncated.Limit != 1024 || len(truncated.Output) != 1024 {
+		t.Fatalf("Expected OutputTruncatedError at 1024 bytes, got %v", err)
+	}
+	if len(output) != 1024 {
+		t.Errorf("Expected partial output capped at 1024 bytes, got %d", len(output))
+	}
+
+	// 恰好等于上限的输出不算截断
+	if _, err := executor.Run(context.Background(), "head", "-c", "1024", "/dev/zero"); err != nil {
+		t.Errorf("Expected output at the limit to succeed, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "big")
+	if err := os.WriteFile(path, make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := executor.ReadFile(context.Background(), path)
+	if !errors.Is(err, ErrOutputTruncated) || len(data) != 1024 {
+		t.Errorf("Expected ReadFile to return 1024 bytes and ErrOutputTruncated, got %d (err %v)", len(data), err)
+	}
+
+	// 流式输出写入 writer，同样受上限约束
+	var buf bytes.Buffer
+	err = runStream(context.Background(), executor, &buf, "head", "-c", "100000", "/dev/zero")
+	if !errors.Is(err, ErrOutputTruncated) || buf.Len() != 1024 {
+		t.Errorf("Expected streamed output capped at 1024 bytes with ErrOutputTruncated, got %d (err %v)", buf.Len(), err)
+	}
+}
+
+func TestLocalExecutorCLocale(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	executor := NewLocalExecutor(DefaultExecOptions())
+	output, err := executor.Run(context.Background(), "sh", "-c", "echo $LC_ALL")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "C" {
+		t.Errorf("Expected LC_ALL=C, got %q", output)
+	}
+}
+
+func TestFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("uname -r", "5.15.0\n")
+	executor.SetCommandResult("dmesg", FakeCommand{Delay: time.Second})
+	executor.SetFile("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor", "powersave\n")
+	executor.SetFile("/sys/devices/system/cpu/cpu1/cpufreq/scaling_governor", "powersave\n")
+	executor.SetCommand("journalctl -k", strings.Repeat("x", 200))
+	executor.SetFile("/proc/slabinfo", strings.Repeat("y", 200))
+	executor.SetOptions(ExecOptions{Timeout: 50 * time.Millisecond, MaxOutputBytes: 100})
+
+	ctx := context.Background()
+
+	if output, err := executor.Run(ctx, "uname", "-r"); err != nil || output != "5.15.0\n" {
+		t.Errorf("Unexpected scripted output %q, err %v", output, err)
+	}
+
+	if _, err := executor.Run(ctx, "free", "-b"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unscripted command, got %v", err)
+	}
+
+	if _, err := executor.Run(ctx, "dmesg"); !errors.Is(err, ErrCommandTimeout) {
+		t.Errorf("Expected ErrCommandTimeout for slow command, got %v", err)
+	}
+
+	if output, err := executor.Run(ctx, "journalctl", "-k"); !errors.Is(err, ErrOutputTruncated) || len(output) != 100 {
+		t.Errorf("Expected truncated command output, got %d bytes (err %v)", len(output), err)
+	}
+	if data, err := executor.ReadFile(ctx, "/proc/slabinfo"); !errors.Is(err, ErrOutputTruncated) || len(data) != 100 {
+		t.Errorf("Expected truncated file content, got %d bytes (err %v)", len(data), err)
+	}
+	if output, err := executor.Run(WithMaxOutputBytes(ctx, 1000), "journalctl", "-k"); err != nil || len(output) != 200 {
+		t.Errorf("Expected overridden limit to keep full output, got %d bytes (err %v)", len(output), err)
+	}
+	if output, err := withContext(ctx, executor).Run(WithMaxOutputBytes(ctx, 1000), "journalctl", "-k"); err != nil || len(output) != 200 {
+		t.Errorf("Expected overridden limit to survive context binding, got %d bytes (err %v)", len(output), err)
+	}
+
+	files, err := executor.Glob(ctx, "/sys/devices/system/cpu/cpu*/cpufreq/scaling_governor")
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected 2 governor files, got %v (err %v)", files, err)
+	}
+
+	if err := executor.WriteFile(ctx, files[0], []byte("performance\n")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, _ := executor.ReadFile(ctx, files[0]); string(data) != "performance\n" {
+		t.Errorf("Expected written content to be readable, got %q", data)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"uname":           "uname",
+		"--sort=-%cpu":    "--sort=-%cpu",
+		"NAME,SIZE,TYPE":  "NAME,SIZE,TYPE",
+		"free -b | grep":  "'free -b | grep'",
+		"it's":            `'it'\''s'`,
+		"":                "''",
+		"/proc/loadavg":   "/proc/loadavg",
+		"err,crit,alert":  "err,crit,alert",
+		"$(rm -rf /tmp)":  "'$(rm -rf /tmp)'",
+		"a;b":             "'a;b'",
+		"--value":         "--value",
+		"Asia/Shanghai":   "Asia/Shanghai",
+		"--property=Zone": "--property=Zone",
+	}
+
+	for input, want := range tests {
+		if got := shellQuote(input); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestSSHExecutor(t *testing.T) {
+	clientPub, keyFile := newTestClientKey(t)
+	server := newTestSSHServer(t, "admin", clientPub, map[string]string{
+		"hostname":                   "node-a\n",
+		"cat /proc/loadavg":          "0.10 0.20 0.30 1/100 42\n",
+		"ps aux --sort=-%cpu":        "USER PID\n",
+		"printenv 'HOME PATH'":       "quoted\n",
+		"dmesg -T -l err,crit,alert": strings.Repeat("x", 4096),
+		"cat /proc/slabinfo":         strings.Repeat("y", 4096),
+	})
+
+	sshConfig, err := NewSSHClientConfig(SSHOptions{Username: "admin", KeyFile: keyFile, Timeout: 5 * time.Second, KnownHostsFile: server.KnownHosts(t)})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+
+	ctx := context.Background()
+	executor, err := DialSSHExecutor(ctx, server.Addr(), sshConfig, ExecOptions{MaxOutputBytes: 1000})
+	if err != nil {
+		t.Fatalf("DialSSHExecutor failed: %v", err)
+	}
+	defer executor.Close()
+
+	if output, err := executor.Run(ctx, "hostname"); err != nil || strings.TrimSpace(output) != "node-a" {
+		t.Errorf("Unexpected hostname %q, err %v", output, err)
+	}
+	if data, err := executor.ReadFile(ctx, "/proc/loadavg"); err != nil || !strings.HasPrefix(string(data), "0.10") {
+		t.Errorf("Unexpected loadavg %q, err %v", data, err)
+	}
+	if _, err := executor.Run(ctx, "ps", "aux", "--sort=-%cpu"); err != nil {
+		t.Errorf("Expected unquoted safe arguments, got %v", err)
+	}
+	if _, err := executor.Run(ctx, "printenv", "HOME PATH"); err != nil {
+		t.Errorf("Expected argument with space to be quoted, got %v", err)
+	}
+	if output, err := executor.Run(ctx, "dmesg", "-T", "-l", "err,crit,alert"); !errors.Is(err, ErrOutputTruncated) || len(output) != 1000 {
+		t.Errorf("Expected output capped at 1000 bytes with ErrOutputTruncated, got %d (err %v)", len(output), err)
+	}
+	if data, err := executor.ReadFile(ctx, "/proc/slabinfo"); !errors.Is(err, ErrOutputTruncated) || len(data) != 1000 {
+		t.Errorf("Expected file capped at 1000 bytes with ErrOutputTruncated, got %d (err %v)", len(data), err)
+	}
+	if _, err := executor.Run(ctx, "missing-command"); err == nil {
+		t.Error("Expected error for failing remote command")
+	}
+
+	// 远程命令应强制使用 C 区域设置
+	for _, command := range server.Commands() {
+		if !strings.HasPrefix(command, "LC_ALL=C LANG=C ") {
+			t.Errorf("Expected C locale prefix, got %q", command)
+		}
+	}
+}
+
+func TestPerfSnapCollectorWithFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("hostname", "node-a\n")
+	executor.SetCommand("uptime -p", "up 2 hours\n")
+	executor.SetFile("/proc/loadavg", "12.50 8.00 4.00 3/300 999\n")
+	executor.SetCommand("free -m", "              total        used        free      shared  buff/cache   available\n"+
+		"Mem:          16000       15000         500          10         500         800\n")
+	executor.SetCommand("vmstat 1 2", "procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----\n"+
+		" r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st\n"+
+		" 1  0      0 812344   2108 5123456    0    0     5    14   45   67  3  1 96  0  0\n"+
+		" 9  1      0 809112   2108 5123460    0    0     0   512 3120 8890 22  6 70  2  0\n")
+	// 非 C 区域设置下的逗号小数点
+	executor.SetCommand("mpstat -P ALL 1 1", "14:15:01     CPU    %usr   %nice    %sys %iowait    %irq   %soft  %steal  %guest  %gnice   %idle\n"+
+		"14:15:02     all    7,50    0,00    2,50    0,00    0,00    0,50    0,00    0,00    0,00   89,50\n"+
+		"14:15:02       0    7,50    0,00    2,50    0,00    0,00    0,50    0,00    0,00    0,00   89,50\n")
+	executor.SetCommand("iostat -dx 1 2", "iostat: unexpected output\n")
+
+	c := NewPerfSnapCollector()
+	c.SetExecutor(executor)
+	if err := c.SetBackend(PerfSnapBackendSysstat); err != nil {
+		t.Fatalf("SetBackend failed: %v", err)
+	}
+
+	data, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if data.Backend != PerfSnapBackendSysstat {
+		t.Errorf("Expected sysstat backend recorded, got '%s'", data.Backend)
+	}
+	if data.Hostname != "node-a" {
+		t.Errorf("Expected hostname from executor, got '%s'", data.Hostname)
+	}
+	if data.LoadAverage.OneMin != 12.5 {
+		t.Errorf("Expected load 12.5, got %.2f", data.LoadAverage.OneMin)
+	}
+	if data.MemoryStats.TotalMB != 16000 || data.MemoryStats.CacheMB != 500 {
+		t.Errorf("Expected 16000 MB total and 500 MB cache, got %+v", data.MemoryStats)
+	}
+	if data.VMStat.RunQueue != 9 || data.VMStat.ContextSwitches != 8890 || data.VMStat.Interrupts != 3120 {
+		t.Errorf("Expected last vmstat sample, got %+v", data.VMStat)
+	}
+	if len(data.CPUStats) != 1 || data.CPUStats[0].User != 7.5 || data.CPUStats[0].Idle != 89.5 {
+		t.Errorf("Unexpected CPU stats %+v", data.CPUStats)
+	}
+	if len(data.ParseWarnings) != 1 || !strings.HasPrefix(data.ParseWarnings[0], "iostat: ") {
+		t.Errorf("Expected iostat parse warning, got %v", data.ParseWarnings)
+	}
+
+	// 高负载和高内存使用应被识别为问题
+	categories := make(map[string]bool)
+	for _, issue := range data.Issues {
+		categories[issue.Category] = true
+	}
+	if !categories["load"] || !categories["memory"] {
+		t.Errorf("Expected load and memory issues, got %+v", data.Issues)
+	}
+
+	// 火焰图生成失败不影响采集，但原因需要保留在结果中
+	executor.SetCommand("mktemp /tmp/clusterreport_perf.XXXXXX", "/tmp/clusterreport_perf.a1B2c3\n")
+	executor.SetCommand("rm -f /tmp/clusterreport_perf.a1B2c3", "")
+	c.generateFlame = true
+	data, err = c.Collect()
+	if err != nil {
+		t.Fatalf("Collect with flame graph failed: %v", err)
+	}
+	if !strings.Contains(data.ProfileError, "perf record failed") || data.FlameGraphPath != "" {
+		t.Errorf("Expected perf record failure in ProfileError, got %q", data.ProfileError)
+	}
+
+	// perf 数据写入 mktemp 新建的文件，失败后同样删除
+	var record, removed bool
+	for _, call := range executor.Calls() {
+		if strings.HasPrefix(call, "perf record ") && strings.Contains(call, "-o /tmp/clusterreport_perf.a1B2c3 ") {
+			record = true
+		}
+		if call == "rm -f /tmp/clusterreport_perf.a1B2c3" {
+			removed = true
+		}
+	}
+	if !record || !removed {
+		t.Errorf("Expected perf record into the mktemp file and cleanup, got calls %v", executor.Calls())
+	}
+}
+
+func TestNodeProbeCollectorWithFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("hostname", "node-a\n")
+	executor.SetCommand("id -u", "1000\n")
+	executor.SetCommand("timedatectl show --property=Timezone --value", "UTC\n")
+	executor.SetCommand("lsmod", "Module                  Size  Used by\nnf_conntrack          172032  1\n")
+	executor.SetFile("/proc/loadavg", "0.10 0.20 0.30 1/100 42\n")
+	executor.SetFile("/proc/version", "Linux version 5.15.0-91-generic (buildd@lcy02) #101-Ubuntu\n")
+	executor.SetFile("/etc/os-release", "NAME=\"Ubuntu\"\nPRETTY_NAME=\"Ubuntu 22.04.3 LTS\"\n")
+	executor.SetFile("/proc/meminfo", "MemTotal:       16384000 kB\n")
+	executor.SetFile("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor", "powersave\n")
+
+	// 非 root 时即使开启自动优化也不应写入任何文件
+	c := NewNodeProbeCollector(true)
+	c.SetExecutor(executor)
+
+	data, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if data.Hostname != "node-a" {
+		t.Errorf("Expected hostname from executor, got '%s'", data.Hostname)
+	}
+	if data.OS != "Ubuntu 22.04.3 LTS" || data.Kernel != "5.15.0-91-generic" {
+		t.Errorf("Unexpected OS info: %s / %s", data.OS, data.Kernel)
+	}
+	if data.Timezone != "UTC" {
+		t.Errorf("Expected timezone UTC, got '%s'", data.Timezone)
+	}
+	if !data.KernelModules.NfConntrack || data.KernelModules.BrNetfilter {
+		t.Errorf("Unexpected kernel module status: %+v", data.KernelModules)
+	}
+	if _, ok := executor.Written("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"); ok {
+		t.Error("Expected no governor write without root")
+	}
+}
@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMountTimeout 单个挂载点 statfs 的默认超时时间
+const DefaultMountTimeout = 5 * time.Second
+
+// statfsWorkers 同时进行 statfs 的挂载点数量上限
+const statfsWorkers = 4
+
+// errStatfsPending 上一次对同一挂载点的 statfs 仍未返回
+var errStatfsPending = errors.New("previous statfs still pending")
+
+// statfsFunc 获取挂载点容量，调用方通过上下文限制等待时间
+type statfsFunc func(ctx context.Context, path string) (FilesystemUsage, error)
+
+// mountUsage 一个挂载点及其容量，Stale 表示 statfs 超时未返回
+type mountUsage struct {
+	Mount MountEntry
+	Usage FilesystemUsage
+	Stale bool
+}
+
+// readMountTable 读取挂载表，优先使用 mountinfo，不可读时退回 mounts
+func readMountTable(ctx context.Context, executor Executor, procRoot string) ([]MountEntry, error) {
+	data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "self", "mountinfo"))
+	if err == nil {
+		if mounts := parseMountinfo(string(data)); len(mounts) > 0 {
+			return mounts, nil
+		}
+	}
+
+	data, err = executor.ReadFile(ctx, filepath.Join(procRoot, "mounts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mounts: %w", err)
+	}
+	return parseMounts(string(data)), nil
+}
+
+// statMounts 枚举真实文件系统并逐个 statfs
+// 不使用 df：卡死的 NFS 或 FUSE 挂载会让 df 永久阻塞。每个挂载点单独设置超时，
+// 超时的挂载点标记为 stale 后继续处理其他挂载点；同一挂载点被覆盖挂载时以最后一次为准
+func statMounts(ctx context.Context, executor Executor, procRoot string, stat statfsFunc, timeout time.Duration) ([]mountUsage, error) {
+	mounts, err := readMountTable(ctx, executor, procRoot)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultMountTimeout
+	}
+
+	var candidates []MountEntry
+	seen := make(map[string]int)
+	for _, mount := range mounts {
+		if pseudoFilesystems[mount.FSType] {
+			continue
+		}
+		if i, ok := seen[mount.MountPoint]; ok {
+			candidates[i] = mount
+			continue
+		}
+		seen[mount.MountPoint] = len(candidates)
+		candidates = append(candidates, mount)
+	}
+
+	results := make([]mountUsage, len(candidates))
+	errs := make([]error, len(candidates))
+	sem := make(chan struct{}, statfsWorkers)
+	var wg sync.WaitGroup
+	for i, mount := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, mount MountEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			usage, err := stat(ctx, mount.MountPoint)
+			results[i] = mountUsage{Mount: mount, Usage: usage}
+			if errors.Is(err, ErrCommandTimeout) || errors.Is(err, errStatfsPending) {
+				results[i].Stale = true
+				return
+			}
+			errs[i] = err
+		}(i, mount)
+	}
+	wg.Wait()
+
+	var usages []mountUsage
+	for i, r := range results {
+		// 无权限访问或容量为 0 的挂载点（如空的 tmpfs 占位）不计入
+		if errs[i] != nil || (!r.Stale && r.Usage.Total == 0) {
+			continue
+		}
+		usages = append(usages, r)
+	}
+	return usages, nil
+}
+
+// pendingStatfs 本机上尚未返回的 statfs 调用
+// 处于 D 状态的 statfs 无法取消，记录下来避免每次采集都再阻塞一个 goroutine
+var pendingStatfs = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: make(map[string]bool)}
+
+// localStatfs 在独立 goroutine 中调用 statfs(2)，超时后调用方立即返回
+func localStatfs(stat func(path string) (FilesystemUsage, error)) statfsFunc {
+	return func(ctx context.Context, path string) (FilesystemUsage, error) {
+		pendingStatfs.Lock()
+		if pendingStatfs.paths[path] {
+			pendingStatfs.Unlock()
+			return FilesystemUsage{}, fmt.Errorf("statfs %s: %w", path, errStatfsPending)
+		}
+		pendingStatfs.paths[path] = true
+		pendingStatfs.Unlock()
+
+		type result struct {
+			usage FilesystemUsage
+			err   error
+		}
+		done := make(chan result, 1)
+		go func() {
+			usage, err := stat(path)
+			pendingStatfs.Lock()
+			delete(pendingStatfs.paths, path)
+			pendingStatfs.Unlock()
+			done <- result{usage: usage, err: err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.usage, r.err
+		case <-ctx.Done():
+			return FilesystemUsage{}, timeoutError(ctx, "statfs "+path)
+		}
+	}
+}
+
+// remoteStatfs 通过 stat -f 获取远程挂载点容量，超时后执行器会终止远程命令
+func remoteStatfs(executor Executor) statfsFunc {
+	return func(ctx context.Context, path string) (FilesystemUsage, error) {
+		out, err := executor.Run(ctx, "stat", "-f", "-c", "%S %b %f %a %c %d", "--", path)
+		if err != nil {
+			return FilesystemUsage{}, err
+		}
+		return parseStatFS(out)
+	}
+}
+
+// parseStatFS 解析 stat -f -c "%S %b %f %a %c %d" 的输出：
+// 基本块大小、总块数、空闲块数、非特权用户可用块数、inode 总数、空闲 inode 数
+func parseStatFS(output string) (FilesystemUsage, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 6 {
+		return FilesystemUsage{}, fmt.Errorf("unexpected stat -f output: %q", strings.TrimSpace(output))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return FilesystemUsage{}, fmt.Errorf("unexpected stat -f output: %q", strings.TrimSpace(output))
+		}
+		values[i] = v
+	}
+
+	bsize := values[0]
+	return FilesystemUsage{
+		Total:     values[1] * bsize,
+		Free:      values[2] * bsize,
+		Available: values[3] * bsize,
+		Files:     values[4],
+		FilesFree: values[5],
+	}, nil
+}
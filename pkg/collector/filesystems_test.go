@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseMountinfo(t *testing.T) {
+	data := "22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,errors=remount-ro\n" +
+		"40 22 0:45 / /mnt/nfs rw,relatime shared:80 master:2 - nfs4 10.0.0.9:/export rw,vers=4.1,hard,timeo=600\n" +
+		"41 22 0:46 / /mnt/no\\040opts rw - fuse.sshfs host:/ \n" +
+		"garbage line\n"
+
+	mounts := parseMountinfo(data)
+	if len(mounts) != 3 {
+		t.Fatalf("Expected 3 mounts, got %+v", mounts)
+	}
+	nfs := mounts[1]
+	if nfs.Device != "10.0.0.9:/export" || nfs.FSType != "nfs4" || nfs.MountPoint != "/mnt/nfs" {
+		t.Errorf("Unexpected mount with optional fields: %+v", nfs)
+	}
+	if nfs.Options != "rw,relatime,vers=4.1,hard,timeo=600" {
+		t.Errorf("Expected merged mount and super options, got %q", nfs.Options)
+	}
+	if mounts[2].MountPoint != "/mnt/no opts" || mounts[2].Options != "rw" {
+		t.Errorf("Unexpected mount without super options: %+v", mounts[2])
+	}
+}
+
+func TestParseStatFS(t *testing.T) {
+	usage, err := parseStatFS("4096 1000 250 200 64 16\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Total != 4096000 || usage.Free != 1024000 || usage.Available != 819200 ||
+		usage.Files != 64 || usage.FilesFree != 16 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	if _, err := parseStatFS("stat: cannot read file system information\n"); err == nil {
+		t.Error("Expected error for unexpected output")
+	}
+}
+
+func TestStatMountsMarksHungMountStale(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/proc/self/mountinfo", "22 1 8:1 / / rw - ext4 /dev/sda1 rw\n"+
+		"40 22 0:45 / /mnt/stale-test rw - nfs 10.0.0.9:/export rw,hard\n")
+
+	release := make(chan struct{})
+	defer close(release)
+	var calls int32
+	stat := localStatfs(func(path string) (FilesystemUsage, error) {
+		if path == "/mnt/stale-test" {
+			atomic.AddInt32(&calls, 1)
+			<-release
+		}
+		return FilesystemUsage{Total: 100, Free: 50, Available: 50}, nil
+	})
+
+	start := time.Now()
+	usages, err := statMounts(context.Background(), executor, "/proc", stat, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected hung mount to be abandoned after timeout, took %v", elapsed)
+	}
+	if len(usages) != 2 || usages[0].Stale || usages[0].Usage.Total != 100 {
+		t.Fatalf("Expected healthy root and stale nfs mount, got %+v", usages)
+	}
+	if !usages[1].Stale || usages[1].Mount.FSType != "nfs" {
+		t.Errorf("Expected nfs mount marked stale, got %+v", usages[1])
+	}
+
+	// statfs 仍卡住时再次采集直接判定为 stale，不再阻塞新的 goroutine
+	usages, err = statMounts(context.Background(), executor, "/proc", stat, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); len(usages) != 2 || !usages[1].Stale || n != 1 {
+		t.Errorf("Expected pending statfs to be reported stale immediately, got %+v (%d calls)", usages, n)
+	}
+}
+
+func TestRemoteStatMountsTimeout(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/proc/mounts", "/dev/sda1 / ext4 rw 0 0\n"+
+		"10.0.0.9:/export /mnt/nfs nfs rw,hard 0 0\n"+
+		"/dev/sdb1 /denied xfs rw 0 0\n")
+	executor.SetCommand("stat -f -c '%S %b %f %a %c %d' -- /", "4096 100 50 40 10 5\n")
+	executor.SetCommandResult("stat -f -c '%S %b %f %a %c %d' -- /mnt/nfs", FakeCommand{Delay: time.Minute})
+	executor.SetCommandResult("stat -f -c '%S %b %f %a %c %d' -- /denied", FakeCommand{Err: errors.New("permission denied")})
+
+	usages, err := statMounts(context.Background(), executor, "/proc", remoteStatfs(executor), 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("Expected root and stale nfs mount, got %+v", usages)
+	}
+	root := diskMetricsFromUsage(usages[0])
+	if root.Total != 409600 || root.UsedPercent != 50 || root.InodesUsedPercent != 50 {
+		t.Errorf("Unexpected root metrics: %+v", root)
+	}
+	if nfs := diskMetricsFromUsage(usages[1]); !nfs.Stale || nfs.Total != 0 || nfs.MountOptions != "rw,hard" {
+		t.Errorf("Expected stale nfs metrics, got %+v", nfs)
+	}
+}
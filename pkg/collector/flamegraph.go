@@ -0,0 +1,392 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 火焰图布局参数，与 FlameGraph 工具的默认值保持一致
+const (
+	flameDefaultWidth = 1200
+	flameFrameHeight  = 16
+	flameFontSize     = 12
+	flameCharWidth    = 0.59 * flameFontSize
+	flamePadX         = 10
+	flamePadTop       = 40
+	flamePadBottom    = 30
+	flameMinWidth     = 0.1 // 窄于该像素宽度的帧不绘制
+)
+
+// StackSample perf script 输出中的一次采样
+type StackSample struct {
+	Comm   string
+	PID    int
+	Frames []string // 从根到叶
+}
+
+// FoldedStacks 折叠后的调用栈，键为以 ";" 连接的栈（根在前），值为采样次数
+type FoldedStacks map[string]int
+
+// FlameGraphOptions 火焰图渲染选项
+type FlameGraphOptions struct {
+	Title string
+	Width int // 像素宽度，默认 1200
+}
+
+// perfScriptMaxLine perf script 单行的长度上限，超长的 C++ 模板符号可达数十 KiB
+const perfScriptMaxLine = 1 << 20
+
+// ParsePerfScript 解析 perf script 的默认输出
+func ParsePerfScript(output string) []StackSample {
+	samples, _ := ReadPerfScript(strings.NewReader(output))
+	return samples
+}
+
+// ReadPerfScript 逐行解析 perf script 的默认输出，不需要把完整输出读入内存
+// 每个采样以一行头部（进程名、PID 等）开始，随后每行一个栈帧（叶在前），采样之间以空行分隔
+func ReadPerfScript(r io.Reader) ([]StackSample, error) {
+	var samples []StackSample
+	var current *StackSample
+
+	flush := func() {
+		if current != nil {
+			// perf 输出的栈从叶到根，折叠格式要求从根到叶
+			frames := current.Frames
+			for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+				frames[i], frames[j] = frames[j], frames[i]
+			}
+			samples = append(samples, *current)
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), perfScriptMaxLine)
+	for scanner.Scan() {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if current != nil {
+			if frame, ok := parsePerfFrame(trimmed); ok {
+				current.Frames = append(current.Frames, frame)
+				continue
+			}
+			flush()
+		}
+
+		comm, pid, ok := parsePerfHeader(trimmed)
+		if !ok {
+			continue
+		}
+		current = &StackSample{Comm: comm, PID: pid}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read perf script output: %w", err)
+	}
+	flush()
+
+	return samples, nil
+}
+
+// parsePerfHeader 解析采样头部，如 "java 1234/1240 [002] 100.123: 10101010 cpu-clock:"
+// 进程名可能包含空格，以第一个形如 pid 或 pid/tid 的字段为界
+func parsePerfHeader(line string) (string, int, bool) {
+	fields := strings.Fields(line)
+	for i := 1; i < len(fields); i++ {
+		pidField := fields[i]
+		if idx := strings.IndexByte(pidField, '/'); idx > 0 {
+			pidField = pidField[:idx]
+		}
+		if pid, err := strconv.Atoi(pidField); err == nil {
+			return strings.Join(fields[:i], " "), pid, true
+		}
+	}
+	return "", 0, false
+}
+
+// parsePerfFrame 解析栈帧行，如 "ffffffff8103e3b6 native_safe_halt+0x6 ([kernel.kallsyms])"
+// 去掉地址和偏移；符号未知时与 stackcollapse-perf.pl 一样以模块名代替
+func parsePerfFrame(line string) (string, bool) {
+	idx := strings.IndexByte(line, ' ')
+	addr := line
+	if idx > 0 {
+		addr = line[:idx]
+	}
+	if _, err := strconv.ParseUint(addr, 16, 64); err != nil {
+		return "", false
+	}
+	if idx < 0 {
+		return "[unknown]", true
+	}
+
+	rest := strings.TrimSpace(line[idx+1:])
+	sym, dso := rest, ""
+	if strings.HasSuffix(rest, ")") {
+		if open := strings.LastIndex(rest, " ("); open >= 0 {
+			sym = strings.TrimSpace(rest[:open])
+			dso = rest[open+2 : len(rest)-1]
+		}
+	}
+
+	if off := strings.LastIndex(sym, "+0x"); off > 0 {
+		sym = sym[:off]
+	}
+	if sym == "" || sym == "[unknown]" {
+		if dso != "" && dso != "[unknown]" {
+			return "[" + path.Base(dso) + "]", true
+		}
+		return "[unknown]", true
+	}
+	return sym, true
+}
+
+// FoldStacks 折叠调用栈，进程名作为栈的根帧
+func FoldStacks(samples []StackSample) FoldedStacks {
+	folded := make(FoldedStacks)
+	for _, s := range samples {
+		frames := append([]string{s.Comm}, s.Frames...)
+		folded[strings.Join(frames, ";")]++
+	}
+	return folded
+}
+
+// Total 返回采样总数
+func (f FoldedStacks) Total() int {
+	total := 0
+	for _, count := range f {
+		total += count
+	}
+	return total
+}
+
+// WriteTo 按栈排序输出折叠格式（每行 "栈 次数"）
+func (f FoldedStacks) WriteTo(w io.Writer) (int64, error) {
+	stacks := make([]string, 0, len(f))
+	for stack := range f {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+
+	var written int64
+	for _, stack := range stacks {
+		n, err := fmt.Fprintf(w, "%s %d\n", stack, f[stack])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// flameNode 火焰图中的一个调用节点
+type flameNode struct {
+	name     string
+	value    int
+	children map[string]*flameNode
+}
+
+// flameFrame 布局后的一个矩形
+type flameFrame struct {
+	name  string
+	depth int
+	start int // 在父节点范围内的起始采样位置
+	value int
+}
+
+// buildFlameTree 由折叠栈构建调用树
+func buildFlameTree(stacks FoldedStacks) *flameNode {
+	root := &flameNode{name: "all", children: make(map[string]*flameNode)}
+	for stack, count := range stacks {
+		if count <= 0 {
+			continue
+		}
+		root.value += count
+		node := root
+		for _, name := range strings.Split(stack, ";") {
+			child, ok := node.children[name]
+			if !ok {
+				child = &flameNode{name: name, children: make(map[string]*flameNode)}
+				node.children[name] = child
+			}
+			child.value += count
+			node = child
+		}
+	}
+	return root
+}
+
+// layoutFlame 按名称排序子节点并计算每帧的位置
+func layoutFlame(node *flameNode, depth, start int, frames *[]flameFrame) int {
+	*frames = append(*frames, flameFrame{name: node.name, depth: depth, start: start, value: node.value})
+
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	maxDepth := depth
+	offset := start
+	for _, name := range names {
+		child := node.children[name]
+		if d := layoutFlame(child, depth+1, offset, frames); d > maxDepth {
+			maxDepth = d
+		}
+		offset += child.value
+	}
+	return maxDepth
+}
+
+// flameColor 按函数名生成稳定的暖色，同名函数颜色一致
+func flameColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	v := h.Sum32()
+	r := 205 + int(v%50)
+	g := int((v >> 8) % 230)
+	b := int((v >> 16) % 55)
+	return fmt.Sprintf("rgb(%d,%d,%d)", r, g, b)
+}
+
+// flameLabel 截断帧内显示的函数名
+func flameLabel(name string, width float64) string {
+	chars := int((width - 6) / flameCharWidth)
+	if chars < 3 {
+		return ""
+	}
+	if len(name) <= chars {
+		return name
+	}
+	return name[:chars-2] + ".."
+}
+
+// RenderFlameGraph 将折叠栈渲染为可交互的 SVG 火焰图
+// 点击帧放大、点击空白处复原，鼠标悬停显示采样详情；输出不含 XML 声明，可直接内嵌到 HTML
+func RenderFlameGraph(stacks FoldedStacks, opts FlameGraphOptions) ([]byte, error) {
+	total := stacks.Total()
+	if total == 0 {
+		return nil, fmt.Errorf("no stack samples to render")
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = flameDefaultWidth
+	}
+	title := opts.Title
+	if title == "" {
+		title = "CPU Flame Graph"
+	}
+
+	var frames []flameFrame
+	maxDepth := layoutFlame(buildFlameTree(stacks), 0, 0, &frames)
+
+	height := flamePadTop + (maxDepth+1)*flameFrameHeight + flamePadBottom
+	scale := float64(width-2*flamePadX) / float64(total)
+
+	// 以内容哈希作为元素 ID，同一页面内嵌多个火焰图时互不干扰
+	h := fnv.New32a()
+	stacks.WriteTo(h)
+	h.Write([]byte(title))
+	id := fmt.Sprintf("flamegraph-%08x", h.Sum32())
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg version="1.1" xmlns="http://www.w3.org/2000/svg" id="%s" class="flamegraph" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", id, width, height, width, height)
+	fmt.Fprintf(&buf, `<style>#%[1]s text{font-family:Verdana,sans-serif;font-size:%[2]dpx;fill:#000}#%[1]s .title{font-size:17px}#%[1]s g.frame{cursor:pointer}#%[1]s g.frame:hover rect{stroke:#000;stroke-width:0.5}#%[1]s g.frame text{pointer-events:none}#%[1]s .reset{cursor:pointer;fill:#0066cc}</style>`+"\n", id, flameFontSize)
+	fmt.Fprintf(&buf, `<rect x="0" y="0" width="%d" height="%d" fill="#f8f8f0"/>`+"\n", width, height)
+	fmt.Fprintf(&buf, `<text class="title" x="%d" y="24" text-anchor="middle">%s</text>`+"\n", width/2, html.EscapeString(title))
+	fmt.Fprintf(&buf, `<text class="reset" x="%d" y="24" style="display:none">Reset Zoom</text>`+"\n", flamePadX)
+	fmt.Fprintf(&buf, `<text class="details" x="%d" y="%d"> </text>`+"\n", flamePadX, height-10)
+
+	buf.WriteString(`<g class="frames">` + "\n")
+	for _, f := range frames {
+		w := float64(f.value) * scale
+		if w < flameMinWidth {
+			continue
+		}
+		x := float64(flamePadX) + float64(f.start)*scale
+		y := height - flamePadBottom - (f.depth+1)*flameFrameHeight
+		name := html.EscapeString(f.name)
+		info := fmt.Sprintf("%s (%d samples, %.2f%%)", f.name, f.value, float64(f.value)*100/float64(total))
+
+		fmt.Fprintf(&buf, `<g class="frame" data-s="%d" data-n="%d" data-d="%d" data-name="%s">`, f.start, f.value, f.depth, name)
+		fmt.Fprintf(&buf, `<title>%s</title>`, html.EscapeString(info))
+		fmt.Fprintf(&buf, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s" rx="2" ry="2"/>`, x, y, w, flameFrameHeight-1, flameColor(f.name))
+		fmt.Fprintf(&buf, `<text x="%.2f" y="%d">%s</text>`, x+3, y+flameFrameHeight-4, html.EscapeString(flameLabel(f.name, w)))
+		buf.WriteString("</g>\n")
+	}
+	buf.WriteString("</g>\n")
+
+	fmt.Fprintf(&buf, "<script><![CDATA[\n"+flameScript+"]]></script>\n", id, width, flamePadX, total, flameCharWidth)
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
+
+// flameScript 火焰图交互脚本，参数依次为元素 ID、宽度、边距、采样总数、字符宽度
+const flameScript = `(function () {
+  var svg = document.getElementById("%s");
+  if (!svg) return;
+  var W = %d, pad = %d, total = %d, charWidth = %.2f;
+  var frames = svg.querySelectorAll("g.frame");
+  var details = svg.querySelector(".details");
+  var reset = svg.querySelector(".reset");
+
+  function label(name, w) {
+    var n = Math.floor((w - 6) / charWidth);
+    if (n < 3) return "";
+    return name.length <= n ? name : name.substring(0, n - 2) + "..";
+  }
+
+  function zoom(s, n, d) {
+    var scale = (W - 2 * pad) / n;
+    for (var i = 0; i < frames.length; i++) {
+      var g = frames[i];
+      var gs = +g.getAttribute("data-s"), gn = +g.getAttribute("data-n"), gd = +g.getAttribute("data-d");
+      var x0 = Math.max(gs, s), x1 = Math.min(gs + gn, s + n);
+      if (x1 <= x0) {
+        g.style.display = "none";
+        continue;
+      }
+      var x = pad + (x0 - s) * scale, w = (x1 - x0) * scale;
+      var rect = g.querySelector("rect"), text = g.querySelector("text");
+      rect.setAttribute("x", x.toFixed(2));
+      rect.setAttribute("width", w.toFixed(2));
+      text.setAttribute("x", (x + 3).toFixed(2));
+      text.textContent = label(g.getAttribute("data-name"), w);
+      g.style.display = "";
+      g.style.opacity = gd < d ? "0.5" : "";
+    }
+    reset.style.display = n < total ? "" : "none";
+  }
+
+  svg.addEventListener("click", function (e) {
+    var g = e.target.closest ? e.target.closest("g.frame") : null;
+    if (g) {
+      zoom(+g.getAttribute("data-s"), +g.getAttribute("data-n"), +g.getAttribute("data-d"));
+    } else {
+      zoom(0, total, 0);
+    }
+  });
+  svg.addEventListener("mouseover", function (e) {
+    var g = e.target.closest ? e.target.closest("g.frame") : null;
+    if (g) details.textContent = g.querySelector("title").textContent;
+  });
+  svg.addEventListener("mouseout", function () {
+    details.textContent = " ";
+  });
+})();
+`
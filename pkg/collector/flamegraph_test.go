@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadPerfScriptFixture(t *testing.T) []StackSample {
+	t.Helper()
+	data, err := os.ReadFile("testdata/perf/script.txt")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return ParsePerfScript(string(data))
+}
+
+func TestParsePerfScript(t *testing.T) {
+	samples := loadPerfScriptFixture(t)
+	if len(samples) != 4 {
+		t.Fatalf("Expected 4 samples, got %d: %+v", len(samples), samples)
+	}
+
+	if samples[0].Comm != "swapper" || samples[0].PID != 0 {
+		t.Errorf("Unexpected first sample header: %+v", samples[0])
+	}
+	if got := strings.Join(samples[0].Frames, ";"); got != "do_idle;default_idle;native_safe_halt" {
+		t.Errorf("Expected root-first frames without offsets, got %s", got)
+	}
+
+	web := samples[2]
+	if web.Comm != "Web Content" || web.PID != 4242 {
+		t.Errorf("Expected comm with spaces and pid before tid, got %+v", web)
+	}
+	want := "main;[libxul.so];std::vector<int, std::allocator<int> >::push_back(int const&)"
+	if got := strings.Join(web.Frames, ";"); got != want {
+		t.Errorf("Unexpected frames:\n got %s\nwant %s", got, want)
+	}
+
+	if got := strings.Join(samples[3].Frames, ";"); got != "Interpreter;[unknown]" {
+		t.Errorf("Unexpected frames for unresolved stack: %s", got)
+	}
+}
+
+func TestReadPerfScript(t *testing.T) {
+	f, err := os.Open("testdata/perf/script.txt")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	samples, err := ReadPerfScript(f)
+	if err != nil {
+		t.Fatalf("ReadPerfScript failed: %v", err)
+	}
+	if want := loadPerfScriptFixture(t); len(samples) != len(want) {
+		t.Errorf("Expected %d samples from reader, got %d", len(want), len(samples))
+	}
+}
+
+func TestFoldStacks(t *testing.T) {
+	folded := FoldStacks(loadPerfScriptFixture(t))
+	if folded["swapper;do_idle;default_idle;native_safe_halt"] != 2 {
+		t.Errorf("Expected identical stacks to be merged, got %v", folded)
+	}
+	if folded.Total() != 4 {
+		t.Errorf("Expected 4 samples in total, got %d", folded.Total())
+	}
+
+	var buf bytes.Buffer
+	if _, err := folded.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "Web Content;main;") || lines[2] != "swapper;do_idle;default_idle;native_safe_halt 2" {
+		t.Errorf("Unexpected folded output:\n%s", buf.String())
+	}
+}
+
+func TestRenderFlameGraph(t *testing.T) {
+	folded := FoldStacks(loadPerfScriptFixture(t))
+	svg, err := RenderFlameGraph(folded, FlameGraphOptions{Title: "node-a <test>"})
+	if err != nil {
+		t.Fatalf("RenderFlameGraph failed: %v", err)
+	}
+
+	// 输出必须是合法 XML，符号中的 <>& 需要转义
+	decoder := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Invalid SVG: %v", err)
+		}
+	}
+
+	out := string(svg)
+	if strings.HasPrefix(out, "<?xml") {
+		t.Error("Expected SVG without XML declaration so it can be inlined in HTML")
+	}
+	for _, want := range []string{"node-a &lt;test&gt;", `data-name="native_safe_halt"`, "swapper (2 samples, 50.00%)", "<script>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected SVG to contain %q", want)
+		}
+	}
+
+	again, _ := RenderFlameGraph(folded, FlameGraphOptions{Title: "node-a <test>"})
+	if !bytes.Equal(svg, again) {
+		t.Error("Expected deterministic output for identical input")
+	}
+
+	if _, err := RenderFlameGraph(FoldedStacks{}, FlameGraphOptions{}); err == nil {
+		t.Error("Expected error for empty stacks")
+	}
+}
+
+func TestFilterSamples(t *testing.T) {
+	samples := loadPerfScriptFixture(t)
+
+	if got := FilterSamples(samples, nil); len(got) != len(samples) {
+		t.Errorf("Expected no filtering without pids, got %d samples", len(got))
+	}
+
+	got := FilterSamples(samples, []int{4242, 1234})
+	if len(got) != 2 || got[0].Comm != "Web Content" || got[1].Comm != "java" {
+		t.Errorf("Unexpected filtered samples: %+v", got)
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	var buf bytes.Buffer
+	samples := FilterSamples(loadPerfScriptFixture(t), []int{4242})
+	if err := WritePprof(&buf, samples, PprofOptions{Frequency: 99, Duration: 5 * time.Second}); err != nil {
+		t.Fatalf("WritePprof failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Expected gzip output: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("Failed to decompress profile: %v", err)
+	}
+
+	// 字符串表中应包含采样类型、标签和过滤后进程的函数名，不包含被过滤掉的进程
+	for _, want := range []string{"samples", "nanoseconds", "pid", "Web Content", "[libxul.so]", "main"} {
+		if !bytes.Contains(raw, []byte(want)) {
+			t.Errorf("Expected profile to contain %q", want)
+		}
+	}
+	if bytes.Contains(raw, []byte("native_safe_halt")) {
+		t.Error("Expected samples of other processes to be filtered out")
+	}
+}
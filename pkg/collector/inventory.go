@@ -0,0 +1,265 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HardwareInventory 从 DMI/SMBIOS 读取的硬件资产信息
+type HardwareInventory struct {
+	System  DMISystem  `json:"system" yaml:"system"`
+	BIOS    DMIBIOS    `json:"bios" yaml:"bios"`
+	Board   DMIBoard   `json:"board" yaml:"board"`
+	Chassis DMIChassis `json:"chassis" yaml:"chassis"`
+
+	MemorySlots     int      `json:"memory_slots" yaml:"memory_slots"`         // SMBIOS 中的内存插槽总数
+	MemoryInstalled uint64   `json:"memory_installed" yaml:"memory_installed"` // 已安装 DIMM 的总容量（字节）
+	DIMMs           []DIMM   `json:"dimms,omitempty" yaml:"dimms,omitempty"`   // 已安装的 DIMM
+	Sources         []string `json:"sources" yaml:"sources"`                   // 实际读取到的数据来源：sysfs、smbios
+	Warnings        []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// DMISystem 系统信息（SMBIOS 类型 1）
+type DMISystem struct {
+	Vendor  string `json:"vendor" yaml:"vendor"`
+	Product string `json:"product" yaml:"product"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Serial  string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	UUID    string `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+	SKU     string `json:"sku,omitempty" yaml:"sku,omitempty"`
+	Family  string `json:"family,omitempty" yaml:"family,omitempty"`
+}
+
+// DMIBIOS BIOS 信息（SMBIOS 类型 0）
+type DMIBIOS struct {
+	Vendor  string `json:"vendor" yaml:"vendor"`
+	Version string `json:"version" yaml:"version"`
+	Date    string `json:"date" yaml:"date"`
+	Release string `json:"release,omitempty" yaml:"release,omitempty"`
+}
+
+// DMIBoard 主板信息（SMBIOS 类型 2）
+type DMIBoard struct {
+	Vendor   string `json:"vendor" yaml:"vendor"`
+	Product  string `json:"product" yaml:"product"`
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Serial   string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	AssetTag string `json:"asset_tag,omitempty" yaml:"asset_tag,omitempty"`
+}
+
+// DMIChassis 机箱信息（SMBIOS 类型 3）
+type DMIChassis struct {
+	Vendor   string `json:"vendor" yaml:"vendor"`
+	Type     string `json:"type" yaml:"type"`
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Serial   string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	AssetTag string `json:"asset_tag,omitempty" yaml:"asset_tag,omitempty"`
+}
+
+// DIMM 一条内存（SMBIOS 类型 17）
+type DIMM struct {
+	Locator            string `json:"locator" yaml:"locator"`
+	BankLocator        string `json:"bank_locator,omitempty" yaml:"bank_locator,omitempty"`
+	Size               uint64 `json:"size_bytes" yaml:"size_bytes"`
+	Type               string `json:"type" yaml:"type"`
+	SpeedMTs           uint32 `json:"speed_mts,omitempty" yaml:"speed_mts,omitempty"`                       // 最大速率
+	ConfiguredSpeedMTs uint32 `json:"configured_speed_mts,omitempty" yaml:"configured_speed_mts,omitempty"` // 实际配置的速率
+	Rank               int    `json:"rank,omitempty" yaml:"rank,omitempty"`
+	Manufacturer       string `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+	Serial             string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	AssetTag           string `json:"asset_tag,omitempty" yaml:"asset_tag,omitempty"`
+	PartNumber         string `json:"part_number,omitempty" yaml:"part_number,omitempty"`
+}
+
+// InventoryCollector 读取 /sys/class/dmi/id 和原始 SMBIOS 表采集硬件资产信息
+// 不依赖 dmidecode；序列号和 SMBIOS 表通常只有 root 可读，读取失败时只输出可读的部分
+type InventoryCollector struct {
+	sysRoot string
+}
+
+// NewInventoryCollector 创建硬件资产采集器
+func NewInventoryCollector() *InventoryCollector {
+	return &InventoryCollector{sysRoot: DefaultSysRoot}
+}
+
+// Name 返回采集器名称
+func (c *InventoryCollector) Name() string {
+	return "inventory"
+}
+
+// Collect 在节点上采集硬件资产信息
+func (c *InventoryCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	inventory, err := CollectHardwareInventory(ctx, node.executor(), c.sysRoot)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(c.Name(), DataTypeConfig, node, inventory)
+}
+
+// Validate 校验配置
+func (c *InventoryCollector) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *InventoryCollector) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：sys_root
+func (c *InventoryCollector) Configure(options map[string]interface{}) error {
+	sysRoot, err := optionString(options, "sys_root", c.sysRoot)
+	if err != nil {
+		return err
+	}
+	c.sysRoot = sysRoot
+	return nil
+}
+
+// CollectHardwareInventory 从 sysRoot 读取硬件资产信息
+// sysfs 中的 DMI 属性优先，SMBIOS 表补充缺失的字段并提供 DIMM 列表；两者都不可读时返回错误
+func CollectHardwareInventory(ctx context.Context, executor Executor, sysRoot string) (*HardwareInventory, error) {
+	var warnings parseWarnings
+	inventory := &HardwareInventory{}
+
+	idDir := filepath.Join(sysRoot, "class", "dmi", "id")
+	found := 0
+	read := func(name string) string {
+		data, err := executor.ReadFile(ctx, filepath.Join(idDir, name))
+		if err != nil {
+			return ""
+		}
+		found++
+		return cleanDMIString(string(data))
+	}
+
+	inventory.System = DMISystem{
+		Vendor:  read("sys_vendor"),
+		Product: read("product_name"),
+		Version: read("product_version"),
+		Serial:  read("product_serial"),
+		UUID:    strings.ToLower(read("product_uuid")),
+		SKU:     read("product_sku"),
+		Family:  read("product_family"),
+	}
+	inventory.BIOS = DMIBIOS{
+		Vendor:  read("bios_vendor"),
+		Version: read("bios_version"),
+		Date:    read("bios_date"),
+		Release: read("bios_release"),
+	}
+	inventory.Board = DMIBoard{
+		Vendor:   read("board_vendor"),
+		Product:  read("board_name"),
+		Version:  read("board_version"),
+		Serial:   read("board_serial"),
+		AssetTag: read("board_asset_tag"),
+	}
+	inventory.Chassis = DMIChassis{
+		Vendor:   read("chassis_vendor"),
+		Version:  read("chassis_version"),
+		Serial:   read("chassis_serial"),
+		AssetTag: read("chassis_asset_tag"),
+	}
+	if t, err := strconv.Atoi(read("chassis_type")); err == nil {
+		inventory.Chassis.Type = chassisTypeName(byte(t))
+	}
+	if found > 0 {
+		inventory.Sources = append(inventory.Sources, "sysfs")
+	}
+
+	table, err := executor.ReadFile(ctx, filepath.Join(sysRoot, "firmware", "dmi", "tables", "DMI"))
+	if err != nil {
+		warnings.add("smbios", err.Error())
+	} else {
+		structures, err := parseSMBIOSTable(table)
+		if err != nil {
+			warnings.add("smbios", err.Error())
+		}
+		if len(structures) > 0 {
+			inventory.Sources = append(inventory.Sources, "smbios")
+			inventory.applySMBIOS(structures)
+		}
+	}
+
+	if len(inventory.Sources) == 0 {
+		return nil, fmt.Errorf("no DMI data available under %s", sysRoot)
+	}
+	inventory.Warnings = []string(warnings)
+	return inventory, nil
+}
+
+// applySMBIOS 用 SMBIOS 结构补充 sysfs 中缺失的字段并填充 DIMM 列表
+func (inv *HardwareInventory) applySMBIOS(structures []smbiosStructure) {
+	fill := func(dst *string, value string) {
+		if *dst == "" {
+			*dst = cleanDMIString(value)
+		}
+	}
+
+	for i := range structures {
+		s := &structures[i]
+		switch s.Type {
+		case smbiosTypeBIOS:
+			fill(&inv.BIOS.Vendor, s.stringAt(0x04))
+			fill(&inv.BIOS.Version, s.stringAt(0x05))
+			fill(&inv.BIOS.Date, s.stringAt(0x08))
+		case smbiosTypeSystem:
+			fill(&inv.System.Vendor, s.stringAt(0x04))
+			fill(&inv.System.Product, s.stringAt(0x05))
+			fill(&inv.System.Version, s.stringAt(0x06))
+			fill(&inv.System.Serial, s.stringAt(0x07))
+			if len(s.Formatted) >= 0x18 {
+				fill(&inv.System.UUID, smbiosUUID(s.Formatted[0x08:0x18]))
+			}
+			fill(&inv.System.SKU, s.stringAt(0x19))
+			fill(&inv.System.Family, s.stringAt(0x1A))
+		case smbiosTypeBoard:
+			fill(&inv.Board.Vendor, s.stringAt(0x04))
+			fill(&inv.Board.Product, s.stringAt(0x05))
+			fill(&inv.Board.Version, s.stringAt(0x06))
+			fill(&inv.Board.Serial, s.stringAt(0x07))
+			fill(&inv.Board.AssetTag, s.stringAt(0x08))
+		case smbiosTypeChassis:
+			fill(&inv.Chassis.Vendor, s.stringAt(0x04))
+			if t, ok := s.byteAt(0x05); ok {
+				fill(&inv.Chassis.Type, chassisTypeName(t&0x7F))
+			}
+			fill(&inv.Chassis.Version, s.stringAt(0x06))
+			fill(&inv.Chassis.Serial, s.stringAt(0x07))
+			fill(&inv.Chassis.AssetTag, s.stringAt(0x08))
+		case smbiosTypeMemoryDevice:
+			inv.MemorySlots++
+			dimm := smbiosMemoryDevice(s)
+			if dimm.Size == 0 {
+				continue
+			}
+			inv.MemoryInstalled += dimm.Size
+			inv.DIMMs = append(inv.DIMMs, dimm)
+		}
+	}
+}
+
+// chassisTypeName 返回机箱类型名称
+func chassisTypeName(t byte) string {
+	if name, ok := smbiosChassisTypes[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02X", t)
+}
+
+// cleanDMIString 去除厂商填写的占位值
+func cleanDMIString(s string) string {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "not specified", "to be filled by o.e.m.", "default string", "none", "n/a", "0123456789", "system serial number":
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+// smbiosEntry 构造一个 SMBIOS 结构：formatted 为头部之后的格式化区域
+func smbiosEntry(typ byte, handle uint16, formatted []byte, strs ...string) []byte {
+	b := []byte{typ, byte(4 + len(formatted)), 0, 0}
+	binary.LittleEndian.PutUint16(b[2:], handle)
+	b = append(b, formatted...)
+	for _, s := range strs {
+		b = append(b, s...)
+		b = append(b, 0)
+	}
+	if len(strs) == 0 {
+		b = append(b, 0)
+	}
+	return append(b, 0)
+}
+
+// memoryDevice 构造类型 17 的格式化区域（SMBIOS 3.2 长度 0x28）
+func memoryDevice(size uint16, extSize uint32, memType byte, speed uint16) []byte {
+	f := make([]byte, 0x28-4)
+	put16 := func(off int, v uint16) { binary.LittleEndian.PutUint16(f[off-4:], v) }
+	put16(0x0C, size)
+	f[0x10-4] = 1 // Device Locator
+	f[0x11-4] = 2 // Bank Locator
+	f[0x12-4] = memType
+	put16(0x15, speed)
+	f[0x17-4] = 3 // Manufacturer
+	f[0x18-4] = 4 // Serial
+	f[0x1A-4] = 5 // Part Number
+	f[0x1B-4] = 2 // Rank
+	binary.LittleEndian.PutUint32(f[0x1C-4:], extSize)
+	put16(0x20, speed-400)
+	return f
+}
+
+func testSMBIOSTable() []byte {
+	var table []byte
+	table = append(table, smbiosEntry(smbiosTypeBIOS, 0, []byte{1, 2, 0, 0, 3, 0}, "Dell Inc.", "2.14.1", "03/15/2023")...)
+
+	system := make([]byte, 0x1B-4)
+	system[0] = 1
+	system[1] = 2
+	system[3] = 3
+	copy(system[0x08-4:], []byte{0x44, 0x45, 0x4c, 0x4c, 0x35, 0x00, 0x10, 0x4e, 0x80, 0x56, 0xb6, 0xc0, 0x4f, 0x33, 0x42, 0x32})
+	table = append(table, smbiosEntry(smbiosTypeSystem, 1, system, "Dell Inc.", "PowerEdge R750", "ABC1234")...)
+
+	table = append(table, smbiosEntry(smbiosTypeChassis, 3, []byte{1, 0x17, 0, 2, 0}, "Dell Inc.", "ABC1234")...)
+	table = append(table, smbiosEntry(smbiosTypeMemoryDevice, 0x1100, memoryDevice(0x7FFF, 65536, 0x1A, 3200),
+		"A1", "Bank 0", "Samsung", "12345678", "M393A8G40AB2-CWE")...)
+	table = append(table, smbiosEntry(smbiosTypeMemoryDevice, 0x1101, memoryDevice(16384, 0, 0x1A, 3200),
+		"A2", "Bank 1", "Hynix", "87654321", "HMA82GR7DJR8N-XN")...)
+	table = append(table, smbiosEntry(smbiosTypeMemoryDevice, 0x1102, memoryDevice(0, 0, 0x02, 0),
+		"A3", "Bank 2", "NO DIMM", "NO DIMM", "NO DIMM")...)
+	table = append(table, smbiosEntry(smbiosTypeEndOfTable, 0xFFFF, nil)...)
+	return table
+}
+
+func TestParseSMBIOSTable(t *testing.T) {
+	structures, err := parseSMBIOSTable(testSMBIOSTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structures) != 6 {
+		t.Fatalf("Expected 6 structures before end-of-table, got %d", len(structures))
+	}
+
+	dimm := smbiosMemoryDevice(&structures[3])
+	if dimm.Locator != "A1" || dimm.Size != 64<<30 || dimm.Type != "DDR4" || dimm.SpeedMTs != 3200 ||
+		dimm.ConfiguredSpeedMTs != 2800 || dimm.Rank != 2 || dimm.PartNumber != "M393A8G40AB2-CWE" {
+		t.Errorf("Unexpected extended-size DIMM: %+v", dimm)
+	}
+	if dimm := smbiosMemoryDevice(&structures[4]); dimm.Size != 16<<30 || dimm.Manufacturer != "Hynix" {
+		t.Errorf("Unexpected DIMM: %+v", dimm)
+	}
+	if dimm := smbiosMemoryDevice(&structures[5]); dimm.Size != 0 || dimm.PartNumber != "" {
+		t.Errorf("Expected empty slot, got %+v", dimm)
+	}
+
+	if _, err := parseSMBIOSTable(testSMBIOSTable()[:40]); err == nil {
+		t.Error("Expected error for truncated table")
+	}
+}
+
+func TestCollectHardwareInventory(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/sys/class/dmi/id/sys_vendor", "Dell Inc.\n")
+	executor.SetFile("/sys/class/dmi/id/product_name", "PowerEdge R750\n")
+	executor.SetFile("/sys/class/dmi/id/bios_version", "2.15.0\n")
+	executor.SetFile("/sys/class/dmi/id/bios_date", "06/01/2023\n")
+	executor.SetFile("/sys/class/dmi/id/board_serial", "To Be Filled By O.E.M.\n")
+	executor.SetFile("/sys/class/dmi/id/chassis_type", "23\n")
+
+	// 普通用户只能读取 sysfs 中的非敏感属性
+	inventory, err := CollectHardwareInventory(context.Background(), executor, "/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inventory.BIOS.Version != "2.15.0" || inventory.Chassis.Type != "Rack Mount Chassis" || inventory.Board.Serial != "" {
+		t.Errorf("Unexpected sysfs inventory: %+v", inventory)
+	}
+	if len(inventory.Sources) != 1 || len(inventory.Warnings) != 1 {
+		t.Errorf("Expected sysfs source and a warning for the unreadable SMBIOS table, got %v %v", inventory.Sources, inventory.Warnings)
+	}
+
+	executor.SetFile("/sys/firmware/dmi/tables/DMI", string(testSMBIOSTable()))
+	inventory, err = CollectHardwareInventory(context.Background(), executor, "/sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inventory.BIOS.Version != "2.15.0" || inventory.BIOS.Vendor != "Dell Inc." {
+		t.Errorf("Expected sysfs values to win and SMBIOS to fill gaps, got %+v", inventory.BIOS)
+	}
+	if inventory.System.Serial != "ABC1234" || inventory.System.UUID != "4c4c4544-0035-4e10-8056-b6c04f334232" {
+		t.Errorf("Unexpected system: %+v", inventory.System)
+	}
+	if inventory.MemorySlots != 3 || len(inventory.DIMMs) != 2 || inventory.MemoryInstalled != 80<<30 {
+		t.Errorf("Expected 2 of 3 slots populated with 80GiB, got %d slots %d DIMMs %d bytes",
+			inventory.MemorySlots, len(inventory.DIMMs), inventory.MemoryInstalled)
+	}
+
+	if _, err := CollectHardwareInventory(context.Background(), NewFakeExecutor("vm"), "/sys"); err == nil {
+		t.Error("Expected error without any DMI data")
+	}
+}
@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LegacyFactory 根据节点和配置项创建旧版采集器
+// 旧版采集器通常在创建时建立连接（如数据库），因此每次采集都重新创建
+type LegacyFactory func(node Node, options map[string]interface{}) (LegacyCollector, error)
+
+// LegacyAdapter 将 LegacyCollector 适配为 Collector
+// 旧版采集器不使用节点的执行器，而是按配置直接连接服务（如 MySQL、Redis），
+// 未配置服务地址时由 factory 使用节点地址
+type LegacyAdapter struct {
+	name     string
+	dataType DataType
+	factory  LegacyFactory
+	options  map[string]interface{}
+}
+
+// NewLegacyAdapter 创建旧版采集器适配器
+func NewLegacyAdapter(name string, factory LegacyFactory) *LegacyAdapter {
+	return &LegacyAdapter{
+		name:     name,
+		dataType: DataTypeCustom,
+		factory:  factory,
+	}
+}
+
+// Name 返回采集器名称
+func (a *LegacyAdapter) Name() string {
+	return a.name
+}
+
+// Collect 创建旧版采集器并采集一次
+// 旧版 Collect 不支持取消，ctx 结束时立即返回，后台的采集结束后再释放连接
+func (a *LegacyAdapter) Collect(ctx context.Context, node Node) (*Data, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type outcome struct {
+		raw interface{}
+		err error
+	}
+	run := func() outcome {
+		lc, err := a.factory(node, a.options)
+		if err != nil {
+			return outcome{err: fmt.Errorf("collector %s: %w", a.name, err)}
+		}
+		if closer, ok := lc.(io.Closer); ok {
+			defer closer.Close()
+		}
+		raw, err := lc.Collect()
+		if err != nil {
+			return outcome{err: fmt.Errorf("collector %s: %w", a.name, err)}
+		}
+		return outcome{raw: raw}
+	}
+	done := make(chan outcome, 1)
+	go func() { done <- run() }()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case out := <-done:
+		if out.err != nil {
+			return nil, out.err
+		}
+		return newData(a.name, a.dataType, node, out.raw)
+	}
+}
+
+// Validate 校验配置
+func (a *LegacyAdapter) Validate(config Config) error {
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (a *LegacyAdapter) SupportedTypes() []DataType {
+	return []DataType{a.dataType}
+}
+
+// Configure 保存配置项，在每次采集创建旧版采集器时传给 factory
+func (a *LegacyAdapter) Configure(options map[string]interface{}) error {
+	a.options = options
+	return nil
+}
+
+// DecodeOptions 将采集器配置项解码到带 yaml 标签的结构体
+func DecodeOptions(options map[string]interface{}, out interface{}) error {
+	if len(options) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
+
+// NodeHost 返回节点的主机地址（去掉端口），本地节点返回 localhost
+func NodeHost(node Node) string {
+	addr := node.Address
+	if addr == "" {
+		return "localhost"
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testLegacyCollector 记录是否被关闭的旧版采集器
+type testLegacyCollector struct {
+	host   string
+	block  chan struct{}
+	closed bool
+}
+
+func (c *testLegacyCollector) Name() string { return "legacy" }
+
+func (c *testLegacyCollector) Collect() (interface{}, error) {
+	if c.block != nil {
+		<-c.block
+	}
+	if c.host == "" {
+		return nil, errors.New("no host")
+	}
+	return map[string]interface{}{"host": c.host}, nil
+}
+
+func (c *testLegacyCollector) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLegacyAdapter(t *testing.T) {
+	var created *testLegacyCollector
+	adapter := NewLegacyAdapter("legacy", func(node Node, options map[string]interface{}) (LegacyCollector, error) {
+		var config struct {
+			Host string `yaml:"host"`
+			Port int    `yaml:"port"`
+		}
+		if err := DecodeOptions(options, &config); err != nil {
+			return nil, err
+		}
+		if config.Host == "" {
+			config.Host = NodeHost(node)
+		}
+		created = &testLegacyCollector{host: config.Host}
+		return created, nil
+	})
+
+	var c Collector = adapter
+	node := Node{Name: "db-1", Address: "10.0.0.8:22"}
+	data, err := c.Collect(context.Background(), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Node != "db-1" || data.Collector != "legacy" || data.Type != DataTypeCustom || data.Metrics["host"] != "10.0.0.8" {
+		t.Errorf("Unexpected data: %+v", data)
+	}
+	if !created.closed {
+		t.Error("Expected legacy collector to be closed after collection")
+	}
+
+	if err := adapter.Configure(map[string]interface{}{"host": "db.example.com", "port": 3307}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := c.Collect(context.Background(), node); err != nil || data.Metrics["host"] != "db.example.com" {
+		t.Errorf("Expected configured host, got %+v, %v", data, err)
+	}
+	if err := adapter.Configure(map[string]interface{}{"port": "not-a-port"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Collect(context.Background(), node); err == nil {
+		t.Error("Expected error for invalid options")
+	}
+}
+
+func TestLegacyAdapterCancel(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	adapter := NewLegacyAdapter("slow", func(node Node, options map[string]interface{}) (LegacyCollector, error) {
+		return &testLegacyCollector{host: "x", block: block}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := adapter.Collect(ctx, LocalNode()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected blocked legacy collector to be abandoned on timeout")
+	}
+}
@@ -0,0 +1,385 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultTopSlabs 默认输出的最大 slab 缓存数量
+const DefaultTopSlabs = 10
+
+// memorySysctls 影响内存行为的内核参数，键为 /proc/sys 下的相对路径
+var memorySysctls = []string{
+	"vm/swappiness",
+	"vm/dirty_ratio",
+	"vm/dirty_background_ratio",
+	"vm/dirty_bytes",
+	"vm/dirty_background_bytes",
+	"vm/overcommit_memory",
+	"vm/overcommit_ratio",
+	"vm/min_free_kbytes",
+	"vm/zone_reclaim_mode",
+	"vm/nr_hugepages",
+	"kernel/numa_balancing",
+}
+
+// MemoryDetail 内存明细、大页、NUMA 和 slab 信息
+type MemoryDetail struct {
+	Meminfo      MeminfoBreakdown  `json:"meminfo" yaml:"meminfo"`
+	HugePages    []HugePagePool    `json:"hugepages,omitempty" yaml:"hugepages,omitempty"`
+	THP          TransparentHuge   `json:"transparent_hugepage" yaml:"transparent_hugepage"`
+	NUMANodes    []NUMANodeMemory  `json:"numa_nodes,omitempty" yaml:"numa_nodes,omitempty"`
+	Sysctls      map[string]int64  `json:"sysctls,omitempty" yaml:"sysctls,omitempty"`             // 如 vm.swappiness、kernel.numa_balancing
+	Slabs        []SlabCache       `json:"slabs,omitempty" yaml:"slabs,omitempty"`                 // 按占用内存从大到小
+	SlabsSkipped string            `json:"slabs_skipped,omitempty" yaml:"slabs_skipped,omitempty"` // 未读取 slabinfo 的原因
+	Raw          map[string]uint64 `json:"raw,omitempty" yaml:"raw,omitempty"`                     // /proc/meminfo 的全部字段，单位字节
+	Warnings     []string          `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// MeminfoBreakdown /proc/meminfo 中常用字段（字节）
+type MeminfoBreakdown struct {
+	Total        uint64 `json:"total" yaml:"total"`
+	Free         uint64 `json:"free" yaml:"free"`
+	Available    uint64 `json:"available" yaml:"available"`
+	Buffers      uint64 `json:"buffers" yaml:"buffers"`
+	Cached       uint64 `json:"cached" yaml:"cached"`
+	SwapCached   uint64 `json:"swap_cached" yaml:"swap_cached"`
+	Active       uint64 `json:"active" yaml:"active"`
+	Inactive     uint64 `json:"inactive" yaml:"inactive"`
+	AnonPages    uint64 `json:"anon_pages" yaml:"anon_pages"`
+	Mapped       uint64 `json:"mapped" yaml:"mapped"`
+	Shmem        uint64 `json:"shmem" yaml:"shmem"`
+	Dirty        uint64 `json:"dirty" yaml:"dirty"`
+	Writeback    uint64 `json:"writeback" yaml:"writeback"`
+	Slab         uint64 `json:"slab" yaml:"slab"`
+	SReclaimable uint64 `json:"slab_reclaimable" yaml:"slab_reclaimable"`
+	SUnreclaim   uint64 `json:"slab_unreclaimable" yaml:"slab_unreclaimable"`
+	KernelStack  uint64 `json:"kernel_stack" yaml:"kernel_stack"`
+	PageTables   uint64 `json:"page_tables" yaml:"page_tables"`
+	CommitLimit  uint64 `json:"commit_limit" yaml:"commit_limit"`
+	CommittedAS  uint64 `json:"committed_as" yaml:"committed_as"`
+	SwapTotal    uint64 `json:"swap_total" yaml:"swap_total"`
+	SwapFree     uint64 `json:"swap_free" yaml:"swap_free"`
+	AnonHuge     uint64 `json:"anon_huge_pages" yaml:"anon_huge_pages"`
+}
+
+// HugePagePool 一种大小的大页池
+type HugePagePool struct {
+	PageSize uint64 `json:"page_size_bytes" yaml:"page_size_bytes"`
+	Total    uint64 `json:"total" yaml:"total"`
+	Free     uint64 `json:"free" yaml:"free"`
+	Reserved uint64 `json:"reserved" yaml:"reserved"`
+	Surplus  uint64 `json:"surplus" yaml:"surplus"`
+}
+
+// TransparentHuge 透明大页设置，值为方括号中选中的模式
+type TransparentHuge struct {
+	Enabled string `json:"enabled,omitempty" yaml:"enabled,omitempty"` // always、madvise 或 never
+	Defrag  string `json:"defrag,omitempty" yaml:"defrag,omitempty"`
+}
+
+// NUMANodeMemory 单个 NUMA 节点的内存（字节）
+type NUMANodeMemory struct {
+	ID    int    `json:"id" yaml:"id"`
+	Total uint64 `json:"total" yaml:"total"`
+	Free  uint64 `json:"free" yaml:"free"`
+	Used  uint64 `json:"used" yaml:"used"`
+}
+
+// SlabCache /proc/slabinfo 中的一个缓存
+type SlabCache struct {
+	Name       string `json:"name" yaml:"name"`
+	ActiveObjs uint64 `json:"active_objs" yaml:"active_objs"`
+	NumObjs    uint64 `json:"num_objs" yaml:"num_objs"`
+	ObjSize    uint64 `json:"obj_size" yaml:"obj_size"`
+	Size       uint64 `json:"size_bytes" yaml:"size_bytes"` // num_objs * objsize
+}
+
+// MemoryDetailCollector 读取 procfs 和 sysfs 采集内存明细
+type MemoryDetailCollector struct {
+	procRoot string
+	sysRoot  string
+	topSlabs int
+}
+
+// NewMemoryDetailCollector 创建内存明细采集器
+func NewMemoryDetailCollector() *MemoryDetailCollector {
+	return &MemoryDetailCollector{
+		procRoot: DefaultProcRoot,
+		sysRoot:  DefaultSysRoot,
+		topSlabs: DefaultTopSlabs,
+	}
+}
+
+// Name 返回采集器名称
+func (c *MemoryDetailCollector) Name() string {
+	return "memory"
+}
+
+// Collect 在节点上采集内存明细
+func (c *MemoryDetailCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	detail, err := CollectMemoryDetail(ctx, node.executor(), c.procRoot, c.sysRoot, c.topSlabs)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newData(c.Name(), DataTypeConfig, node, detail)
+}
+
+// Validate 校验配置
+func (c *MemoryDetailCollector) Validate(config Config) error {
+	if c.topSlabs < 0 {
+		return fmt.Errorf("top_slabs must not be negative: %d", c.topSlabs)
+	}
+	return validateConfig(config)
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *MemoryDetailCollector) SupportedTypes() []DataType {
+	return []DataType{DataTypeConfig}
+}
+
+// Configure 应用配置项：proc_root、sys_root、top_slabs（0 表示不读取 slabinfo）
+func (c *MemoryDetailCollector) Configure(options map[string]interface{}) error {
+	procRoot, err := optionString(options, "proc_root", c.procRoot)
+	if err != nil {
+		return err
+	}
+	sysRoot, err := optionString(options, "sys_root", c.sysRoot)
+	if err != nil {
+		return err
+	}
+	topSlabs, err := optionInt(options, "top_slabs", c.topSlabs)
+	if err != nil {
+		return err
+	}
+	c.procRoot = procRoot
+	c.sysRoot = sysRoot
+	c.topSlabs = topSlabs
+	return nil
+}
+
+// CollectMemoryDetail 从 procRoot 和 sysRoot 读取内存明细，topSlabs 为输出的 slab 缓存数量
+// 只有 /proc/meminfo 不可读时返回错误；slabinfo 通常只有 root 可读，读取失败时记录原因
+func CollectMemoryDetail(ctx context.Context, executor Executor, procRoot, sysRoot string, topSlabs int) (*MemoryDetail, error) {
+	data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	values := parseMeminfo(string(data))
+	if values["MemTotal"] == 0 {
+		return nil, fmt.Errorf("MemTotal not found in meminfo")
+	}
+
+	var warnings parseWarnings
+	detail := &MemoryDetail{
+		Meminfo: meminfoBreakdown(values),
+		Raw:     values,
+	}
+
+	detail.HugePages = readHugePagePools(ctx, executor, sysRoot, &warnings)
+
+	thpDir := filepath.Join(sysRoot, "kernel", "mm", "transparent_hugepage")
+	if data, err := executor.ReadFile(ctx, filepath.Join(thpDir, "enabled")); err == nil {
+		detail.THP.Enabled, _ = parseSelected(string(data))
+	}
+	if data, err := executor.ReadFile(ctx, filepath.Join(thpDir, "defrag")); err == nil {
+		detail.THP.Defrag, _ = parseSelected(string(data))
+	}
+
+	detail.NUMANodes = readNUMANodeMemory(ctx, executor, sysRoot, &warnings)
+
+	detail.Sysctls = make(map[string]int64)
+	for _, name := range memorySysctls {
+		data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "sys", name))
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			warnings.add("sysctl", fmt.Sprintf("%s: invalid value %q", name, strings.TrimSpace(string(data))))
+			continue
+		}
+		detail.Sysctls[strings.ReplaceAll(name, "/", ".")] = value
+	}
+
+	if topSlabs > 0 {
+		data, err := executor.ReadFile(ctx, filepath.Join(procRoot, "slabinfo"))
+		if err != nil {
+			detail.SlabsSkipped = err.Error()
+		} else {
+			slabs, err := parseSlabinfo(string(data))
+			if err != nil {
+				warnings.add("slabinfo", err.Error())
+			}
+			sort.Slice(slabs, func(i, j int) bool { return slabs[i].Size > slabs[j].Size })
+			if len(slabs) > topSlabs {
+				slabs = slabs[:topSlabs]
+			}
+			detail.Slabs = slabs
+		}
+	}
+
+	detail.Warnings = []string(warnings)
+	return detail, nil
+}
+
+// meminfoBreakdown 从 parseMeminfo 的结果中取出常用字段
+func meminfoBreakdown(values map[string]uint64) MeminfoBreakdown {
+	return MeminfoBreakdown{
+		Total:        values["MemTotal"],
+		Free:         values["MemFree"],
+		Available:    values["MemAvailable"],
+		Buffers:      values["Buffers"],
+		Cached:       values["Cached"],
+		SwapCached:   values["SwapCached"],
+		Active:       values["Active"],
+		Inactive:     values["Inactive"],
+		AnonPages:    values["AnonPages"],
+		Mapped:       values["Mapped"],
+		Shmem:        values["Shmem"],
+		Dirty:        values["Dirty"],
+		Writeback:    values["Writeback"],
+		Slab:         values["Slab"],
+		SReclaimable: values["SReclaimable"],
+		SUnreclaim:   values["SUnreclaim"],
+		KernelStack:  values["KernelStack"],
+		PageTables:   values["PageTables"],
+		CommitLimit:  values["CommitLimit"],
+		CommittedAS:  values["Committed_AS"],
+		SwapTotal:    values["SwapTotal"],
+		SwapFree:     values["SwapFree"],
+		AnonHuge:     values["AnonHugePages"],
+	}
+}
+
+// readHugePagePools 读取 /sys/kernel/mm/hugepages 下每种大小的大页池
+func readHugePagePools(ctx context.Context, executor Executor, sysRoot string, warnings *parseWarnings) []HugePagePool {
+	dirs, err := executor.Glob(ctx, filepath.Join(sysRoot, "kernel", "mm", "hugepages", "hugepages-*"))
+	if err != nil {
+		warnings.add("hugepages", err.Error())
+		return nil
+	}
+
+	var pools []HugePagePool
+	for _, dir := range dirs {
+		sizeKB, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB"), 10, 64)
+		if err != nil {
+			warnings.add("hugepages", fmt.Sprintf("unexpected directory %s", filepath.Base(dir)))
+			continue
+		}
+		read := func(name string) uint64 {
+			data, err := executor.ReadFile(ctx, filepath.Join(dir, name))
+			if err != nil {
+				return 0
+			}
+			n, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+			return n
+		}
+		pools = append(pools, HugePagePool{
+			PageSize: sizeKB * 1024,
+			Total:    read("nr_hugepages"),
+			Free:     read("free_hugepages"),
+			Reserved: read("resv_hugepages"),
+			Surplus:  read("surplus_hugepages"),
+		})
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].PageSize < pools[j].PageSize })
+	return pools
+}
+
+// readNUMANodeMemory 读取每个 NUMA 节点的 meminfo
+func readNUMANodeMemory(ctx context.Context, executor Executor, sysRoot string, warnings *parseWarnings) []NUMANodeMemory {
+	dirs, err := executor.Glob(ctx, filepath.Join(sysRoot, "devices", "system", "node", "node[0-9]*"))
+	if err != nil {
+		warnings.add("node", err.Error())
+		return nil
+	}
+
+	var nodes []NUMANodeMemory
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		data, err := executor.ReadFile(ctx, filepath.Join(dir, "meminfo"))
+		if err != nil {
+			warnings.add("node", err.Error())
+			continue
+		}
+		values := parseNodeMeminfo(string(data))
+		node := NUMANodeMemory{ID: id, Total: values["MemTotal"], Free: values["MemFree"]}
+		if node.Free <= node.Total {
+			node.Used = node.Total - node.Free
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// parseNodeMeminfo 解析 /sys/devices/system/node/nodeN/meminfo，行首为 "Node N "
+func parseNodeMeminfo(data string) map[string]uint64 {
+	var b strings.Builder
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) == 3 && fields[0] == "Node" {
+			line = fields[2]
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return parseMeminfo(b.String())
+}
+
+// parseSlabinfo 解析 /proc/slabinfo 2.x 版本
+func parseSlabinfo(data string) ([]SlabCache, error) {
+	lines := strings.Split(data, "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "slabinfo - version: 2.") {
+		return nil, fmt.Errorf("unsupported slabinfo format")
+	}
+
+	var slabs []SlabCache
+	var bad int
+	for _, line := range lines[1:] {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			bad++
+			continue
+		}
+		var values [3]uint64
+		ok := true
+		for i := range values {
+			n, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				ok = false
+				break
+			}
+			values[i] = n
+		}
+		if !ok {
+			bad++
+			continue
+		}
+		slabs = append(slabs, SlabCache{
+			Name:       fields[0],
+			ActiveObjs: values[0],
+			NumObjs:    values[1],
+			ObjSize:    values[2],
+			Size:       values[1] * values[2],
+		})
+	}
+	if bad > 0 {
+		return slabs, fmt.Errorf("skipped %d malformed lines", bad)
+	}
+	return slabs, nil
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMemoryDetailFromFixture(t *testing.T) {
+	detail, err := CollectMemoryDetail(context.Background(), NewLocalExecutor(DefaultExecOptions()), "testdata/proc", "testdata/sys", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Warnings) > 0 || detail.SlabsSkipped != "" {
+		t.Errorf("Unexpected warnings: %v %s", detail.Warnings, detail.SlabsSkipped)
+	}
+
+	m := detail.Meminfo
+	if m.Total != 16384000*1024 || m.Dirty != 20480*1024 || m.PageTables != 65536*1024 || m.CommittedAS != 12288000*1024 {
+		t.Errorf("Unexpected meminfo breakdown: %+v", m)
+	}
+	if m.Slab != m.SReclaimable+m.SUnreclaim {
+		t.Errorf("Expected slab to equal reclaimable + unreclaimable, got %+v", m)
+	}
+
+	if len(detail.HugePages) != 2 {
+		t.Fatalf("Expected 2 hugepage pools, got %+v", detail.HugePages)
+	}
+	if pool := detail.HugePages[0]; pool.PageSize != 2<<20 || pool.Total != 512 || pool.Free != 128 || pool.Reserved != 16 {
+		t.Errorf("Unexpected 2MiB pool: %+v", pool)
+	}
+	if detail.THP.Enabled != "madvise" || detail.THP.Defrag != "madvise" {
+		t.Errorf("Unexpected THP modes: %+v", detail.THP)
+	}
+
+	if len(detail.NUMANodes) != 2 || detail.NUMANodes[1].Free != 1024000*1024 || detail.NUMANodes[1].Used != 7168000*1024 {
+		t.Errorf("Unexpected NUMA nodes: %+v", detail.NUMANodes)
+	}
+	if detail.Sysctls["vm.swappiness"] != 60 || detail.Sysctls["kernel.numa_balancing"] != 1 {
+		t.Errorf("Unexpected sysctls: %v", detail.Sysctls)
+	}
+	if _, ok := detail.Sysctls["vm.zone_reclaim_mode"]; ok {
+		t.Error("Expected missing sysctls to be omitted")
+	}
+
+	var names []string
+	for _, s := range detail.Slabs {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "ext4_inode_cache,dentry" {
+		t.Errorf("Expected top 2 slabs by size, got %v", names)
+	}
+}
+
+func TestMemoryDetailSlabinfoUnreadable(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/proc/meminfo", "MemTotal:       1024 kB\nMemFree:         512 kB\n")
+
+	detail, err := CollectMemoryDetail(context.Background(), executor, "/proc", "/sys", DefaultTopSlabs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.SlabsSkipped == "" || len(detail.Slabs) != 0 {
+		t.Errorf("Expected slabinfo to be reported as skipped, got %+v", detail)
+	}
+
+	executor.SetFile("/proc/meminfo", "MemFree: 512 kB\n")
+	if _, err := CollectMemoryDetail(context.Background(), executor, "/proc", "/sys", 0); err == nil {
+		t.Error("Expected error without MemTotal")
+	}
+}
@@ -1,10 +1,10 @@
 package collector
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -16,17 +16,91 @@ type SystemMetrics struct {
 	Memory    MemoryMetrics     `json:"memory"`
 	Disk      []DiskMetrics     `json:"disk"`
 	Network   []NetworkMetrics  `json:"network"`
+	DiskIO    []DiskIOMetrics   `json:"disk_io,omitempty"`
 	Process   ProcessMetrics    `json:"process"`
 	Custom    map[string]string `json:"custom,omitempty"`
+
+	// DiskQueues 块设备队列参数，由 diskqueue 采集器提供，analyze 时合并进来
+	DiskQueues []DiskQueue `json:"disk_queues,omitempty"`
+
+	// SectionErrors 采集失败的部分，对应部分的数据不可用
+	SectionErrors []SectionError `json:"section_errors,omitempty"`
+}
+
+// 指标分区名称
+const (
+	SectionCPU     = "cpu"
+	SectionMemory  = "memory"
+	SectionDisk    = "disk"
+	SectionNetwork = "network"
+	SectionDiskIO  = "diskio"
+	SectionProcess = "process"
+)
+
+// metricSections 所有指标分区
+var metricSections = []string{SectionCPU, SectionMemory, SectionDisk, SectionNetwork, SectionDiskIO, SectionProcess}
+
+// SectionError 单个指标分区的采集错误
+type SectionError struct {
+	Section string `json:"section"`
+	Error   string `json:"error"`
+}
+
+// HasSection 判断指定分区是否采集成功
+func (m *SystemMetrics) HasSection(section string) bool {
+	for _, se := range m.SectionErrors {
+		if se.Section == section {
+			return false
+		}
+	}
+	return true
+}
+
+// Partial 判断是否有分区采集失败
+func (m *SystemMetrics) Partial() bool {
+	return len(m.SectionErrors) > 0
+}
+
+// sectionError 汇总所有分区错误
+func (m *SystemMetrics) sectionError() string {
+	parts := make([]string, 0, len(m.SectionErrors))
+	for _, se := range m.SectionErrors {
+		parts = append(parts, se.Section+": "+se.Error)
+	}
+	return strings.Join(parts, "; ")
 }
 
 // CPUMetrics CPU指标
+// 使用率类字段为采样窗口内的百分比
 type CPUMetrics struct {
-	Cores     int     `json:"cores"`
-	Usage     float64 `json:"usage"`
-	LoadAvg1  float64 `json:"load_avg_1"`
-	LoadAvg5  float64 `json:"load_avg_5"`
-	LoadAvg15 float64 `json:"load_avg_15"`
+	Cores     int              `json:"cores"`
+	Usage     float64          `json:"usage"`
+	User      float64          `json:"user"`
+	Nice      float64          `json:"nice"`
+	System    float64          `json:"system"`
+	IOWait    float64          `json:"iowait"`
+	IRQ       float64          `json:"irq"`
+	SoftIRQ   float64          `json:"softirq"`
+	Steal     float64          `json:"steal"`
+	Idle      float64          `json:"idle"`
+	LoadAvg1  float64          `json:"load_avg_1"`
+	LoadAvg5  float64          `json:"load_avg_5"`
+	LoadAvg15 float64          `json:"load_avg_15"`
+	PerCore   []CoreCPUMetrics `json:"per_core,omitempty"`
+}
+
+// CoreCPUMetrics 单个 CPU 核心的使用率（%）
+type CoreCPUMetrics struct {
+	CPU     string  `json:"cpu"`
+	Usage   float64 `json:"usage"`
+	User    float64 `json:"user"`
+	Nice    float64 `json:"nice"`
+	System  float64 `json:"system"`
+	IOWait  float64 `json:"iowait"`
+	IRQ     float64 `json:"irq"`
+	SoftIRQ float64 `json:"softirq"`
+	Steal   float64 `json:"steal"`
+	Idle    float64 `json:"idle"`
 }
 
 // MemoryMetrics 内存指标
@@ -40,14 +114,21 @@ type MemoryMetrics struct {
 }
 
 // DiskMetrics 磁盘指标
+// Stale 表示 statfs 在超时时间内未返回（如失联的 NFS 服务器），此时容量和 inode 字段均为 0
 type DiskMetrics struct {
-	Device      string  `json:"device"`
-	MountPoint  string  `json:"mount_point"`
-	FSType      string  `json:"fs_type"`
-	Total       uint64  `json:"total"`
-	Used        uint64  `json:"used"`
-	Available   uint64  `json:"available"`
-	UsedPercent float64 `json:"used_percent"`
+	Device            string  `json:"device"`
+	MountPoint        string  `json:"mount_point"`
+	FSType            string  `json:"fs_type"`
+	MountOptions      string  `json:"mount_options,omitempty"`
+	Total             uint64  `json:"total"`
+	Used              uint64  `json:"used"`
+	Available         uint64  `json:"available"`
+	UsedPercent       float64 `json:"used_percent"`
+	InodesTotal       uint64  `json:"inodes_total"`
+	InodesUsed        uint64  `json:"inodes_used"`
+	InodesFree        uint64  `json:"inodes_free"`
+	InodesUsedPercent float64 `json:"inodes_used_percent"`
+	Stale             bool    `json:"stale,omitempty"`
 }
 
 // NetworkMetrics 网络指标
@@ -58,6 +139,57 @@ type NetworkMetrics struct {
 	PacketsSent uint64 `json:"packets_sent"`
 	PacketsRecv uint64 `json:"packets_recv"`
 	Errors      uint64 `json:"errors"`
+	RxErrors    uint64 `json:"rx_errors"`
+	TxErrors    uint64 `json:"tx_errors"`
+	RxDropped   uint64 `json:"rx_dropped"`
+	TxDropped   uint64 `json:"tx_dropped"`
+	RxFifo      uint64 `json:"rx_fifo"`
+	TxFifo      uint64 `json:"tx_fifo"`
+
+	// Rates 速率模式下两次采样间的速率，累计值无法跨节点比较
+	Rates *NetworkRates `json:"rates,omitempty"`
+}
+
+// NetworkRates 网络接口速率（每秒）
+type NetworkRates struct {
+	RxBytesPerSec   float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec   float64 `json:"tx_bytes_per_sec"`
+	RxPacketsPerSec float64 `json:"rx_packets_per_sec"`
+	TxPacketsPerSec float64 `json:"tx_packets_per_sec"`
+	RxErrorsPerSec  float64 `json:"rx_errors_per_sec"`
+	TxErrorsPerSec  float64 `json:"tx_errors_per_sec"`
+	RxDroppedPerSec float64 `json:"rx_dropped_per_sec"`
+	TxDroppedPerSec float64 `json:"tx_dropped_per_sec"`
+	RxFifoPerSec    float64 `json:"rx_fifo_per_sec"`
+	TxFifoPerSec    float64 `json:"tx_fifo_per_sec"`
+}
+
+// DiskIOMetrics 块设备 I/O 累计计数（来自 /proc/diskstats）
+type DiskIOMetrics struct {
+	Device          string `json:"device"`
+	ReadsCompleted  uint64 `json:"reads_completed"`
+	WritesCompleted uint64 `json:"writes_completed"`
+	SectorsRead     uint64 `json:"sectors_read"`
+	SectorsWritten  uint64 `json:"sectors_written"`
+	ReadTimeMs      uint64 `json:"read_time_ms"`
+	WriteTimeMs     uint64 `json:"write_time_ms"`
+	InProgress      uint64 `json:"in_progress"`
+	IOTimeMs        uint64 `json:"io_time_ms"`
+
+	// Rates 速率模式下两次采样间的速率
+	Rates *DiskIORates `json:"rates,omitempty"`
+}
+
+// DiskIORates 块设备 I/O 速率
+type DiskIORates struct {
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadAwaitMs      float64 `json:"read_await_ms"`
+	WriteAwaitMs     float64 `json:"write_await_ms"`
+	AwaitMs          float64 `json:"await_ms"`
+	UtilPercent      float64 `json:"util_percent"`
 }
 
 // ProcessMetrics 进程指标
@@ -68,59 +200,174 @@ type ProcessMetrics struct {
 	Zombie   int `json:"zombie"`
 }
 
+// FilesystemUsage 文件系统容量（字节）和 inode 数量
+type FilesystemUsage struct {
+	Total     uint64
+	Free      uint64
+	Available uint64
+	Files     uint64
+	FilesFree uint64
+}
+
+// DefaultCPUSampleInterval 默认 CPU 使用率采样窗口
+const DefaultCPUSampleInterval = 500 * time.Millisecond
+
+// MetricsOptions 指标采集器选项
+type MetricsOptions struct {
+	ProcRoot          string        // procfs 挂载点，默认 /proc
+	SysRoot           string        // sysfs 挂载点，默认 /sys
+	CPUSampleInterval time.Duration // 两次读取 /proc/stat 的间隔，默认 500ms
+	RateInterval      time.Duration // 网络和磁盘 I/O 速率的采样窗口，为 0 时只输出累计值
+	MountTimeout      time.Duration // 单个挂载点 statfs 的超时时间，默认 5s
+}
+
 // MetricsCollector 指标采集器
+// 直接读取 procfs 和 statfs(2)，不依赖 procps 等外部命令
 type MetricsCollector struct {
-	config Config
+	config     Config
+	executor   Executor
+	procRoot   string
+	sysRoot    string
+	interval   time.Duration
+	rateWindow time.Duration
+	mountWait  time.Duration
+	statfs     func(path string) (FilesystemUsage, error)
 }
 
 // NewMetricsCollector 创建指标采集器
 func NewMetricsCollector(config Config) *MetricsCollector {
+	return NewMetricsCollectorWithOptions(config, MetricsOptions{})
+}
+
+// NewMetricsCollectorWithOptions 使用指定选项创建指标采集器
+func NewMetricsCollectorWithOptions(config Config, opts MetricsOptions) *MetricsCollector {
+	if opts.ProcRoot == "" {
+		opts.ProcRoot = DefaultProcRoot
+	}
+	if opts.SysRoot == "" {
+		opts.SysRoot = DefaultSysRoot
+	}
+	if opts.CPUSampleInterval <= 0 {
+		opts.CPUSampleInterval = DefaultCPUSampleInterval
+	}
+	if opts.MountTimeout <= 0 {
+		opts.MountTimeout = DefaultMountTimeout
+	}
+
 	return &MetricsCollector{
-		config: config,
+		config:     config,
+		executor:   NewLocalExecutor(DefaultExecOptions()),
+		procRoot:   opts.ProcRoot,
+		sysRoot:    opts.SysRoot,
+		interval:   opts.CPUSampleInterval,
+		rateWindow: opts.RateInterval,
+		mountWait:  opts.MountTimeout,
+		statfs:     statfs,
+	}
+}
+
+// SetExecutor 设置命令执行器
+func (mc *MetricsCollector) SetExecutor(executor Executor) {
+	mc.executor = executor
+}
+
+// SetCPUSampleInterval 设置 CPU 使用率采样窗口
+func (mc *MetricsCollector) SetCPUSampleInterval(interval time.Duration) {
+	if interval > 0 {
+		mc.interval = interval
+	}
+}
+
+// SetRateInterval 设置速率采样窗口，为 0 时关闭速率模式
+func (mc *MetricsCollector) SetRateInterval(interval time.Duration) {
+	mc.rateWindow = interval
+}
+
+// procPath 返回 procfs 下的路径
+func (mc *MetricsCollector) procPath(elem ...string) string {
+	return filepath.Join(append([]string{mc.procRoot}, elem...)...)
+}
+
+// readProc 读取 procfs 下的文件
+func (mc *MetricsCollector) readProc(elem ...string) (string, error) {
+	data, err := mc.executor.ReadFile(context.Background(), mc.procPath(elem...))
+	if err != nil {
+		return "", err
 	}
+	return string(data), nil
 }
 
 // CollectMetrics 采集所有指标
+// 各分区独立采集，单个分区失败时记录到 SectionErrors 并继续采集其他分区；
+// 仅当所有分区都失败时返回错误
 func (mc *MetricsCollector) CollectMetrics() (*SystemMetrics, error) {
 	metrics := &SystemMetrics{
 		Timestamp: time.Now(),
 		Custom:    make(map[string]string),
 	}
 
+	record := func(section string, err error) {
+		metrics.SectionErrors = append(metrics.SectionErrors, SectionError{
+			Section: section,
+			Error:   err.Error(),
+		})
+	}
+
+	// 速率模式下先记录计数器基线，CPU 采样的等待时间同时计入速率窗口
+	var baseline *counterSample
+	if mc.rateWindow > 0 && runtime.GOOS == "linux" {
+		baseline = mc.sampleCounters()
+	}
+
 	// 采集 CPU 指标
-	cpuMetrics, err := mc.collectCPUMetrics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect CPU metrics: %w", err)
+	if cpuMetrics, err := mc.collectCPUMetrics(); err != nil {
+		record(SectionCPU, err)
+	} else {
+		metrics.CPU = cpuMetrics
 	}
-	metrics.CPU = cpuMetrics
 
 	// 采集内存指标
-	memMetrics, err := mc.collectMemoryMetrics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect memory metrics: %w", err)
+	if memMetrics, err := mc.collectMemoryMetrics(); err != nil {
+		record(SectionMemory, err)
+	} else {
+		metrics.Memory = memMetrics
 	}
-	metrics.Memory = memMetrics
 
 	// 采集磁盘指标
-	diskMetrics, err := mc.collectDiskMetrics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect disk metrics: %w", err)
+	if diskMetrics, err := mc.collectDiskMetrics(); err != nil {
+		record(SectionDisk, err)
+	} else {
+		metrics.Disk = diskMetrics
 	}
-	metrics.Disk = diskMetrics
 
 	// 采集网络指标
-	netMetrics, err := mc.collectNetworkMetrics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect network metrics: %w", err)
+	if netMetrics, err := mc.collectNetworkMetrics(); err != nil {
+		record(SectionNetwork, err)
+	} else {
+		metrics.Network = netMetrics
+	}
+
+	// 采集磁盘 I/O 指标
+	if ioMetrics, err := mc.collectDiskIOMetrics(); err != nil {
+		record(SectionDiskIO, err)
+	} else {
+		metrics.DiskIO = ioMetrics
 	}
-	metrics.Network = netMetrics
 
 	// 采集进程指标
-	procMetrics, err := mc.collectProcessMetrics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect process metrics: %w", err)
+	if procMetrics, err := mc.collectProcessMetrics(); err != nil {
+		record(SectionProcess, err)
+	} else {
+		metrics.Process = procMetrics
+	}
+
+	if baseline != nil {
+		mc.applyRates(metrics, baseline)
+	}
+
+	if len(metrics.SectionErrors) == len(metricSections) {
+		return nil, fmt.Errorf("failed to collect metrics: %s", metrics.sectionError())
 	}
-	metrics.Process = procMetrics
 
 	return metrics, nil
 }
@@ -131,174 +378,223 @@ func (mc *MetricsCollector) collectCPUMetrics() (CPUMetrics, error) {
 		Cores: runtime.NumCPU(),
 	}
 
-	// 获取负载平均值（仅Linux）
-	if runtime.GOOS == "linux" {
-		out, err := exec.Command("cat", "/proc/loadavg").Output()
-		if err == nil {
-			fields := strings.Fields(string(out))
-			if len(fields) >= 3 {
-				metrics.LoadAvg1, _ = strconv.ParseFloat(fields[0], 64)
-				metrics.LoadAvg5, _ = strconv.ParseFloat(fields[1], 64)
-				metrics.LoadAvg15, _ = strconv.ParseFloat(fields[2], 64)
-			}
-		}
+	// 仅 Linux 支持
+	if runtime.GOOS != "linux" {
+		return metrics, nil
 	}
 
-	return metrics, nil
-}
+	// 间隔采样 /proc/stat 两次计算使用率
+	prev, err := mc.readProc("stat")
+	if err != nil {
+		return metrics, fmt.Errorf("failed to read stat: %w", err)
+	}
+	prevAll, prevCores, err := parseProcStat(prev)
+	if err != nil {
+		return metrics, err
+	}
 
-// collectMemoryMetrics 采集内存指标
-func (mc *MetricsCollector) collectMemoryMetrics() (MemoryMetrics, error) {
-	metrics := MemoryMetrics{}
+	time.Sleep(mc.interval)
 
-	if runtime.GOOS == "linux" {
-		out, err := exec.Command("free", "-b").Output()
-		if err != nil {
-			return metrics, err
-		}
+	cur, err := mc.readProc("stat")
+	if err != nil {
+		return metrics, fmt.Errorf("failed to read stat: %w", err)
+	}
+	curAll, curCores, err := parseProcStat(cur)
+	if err != nil {
+		return metrics, err
+	}
 
-		lines := strings.Split(string(out), "\n")
-		if len(lines) >= 2 {
-			// 解析内存行
-			memFields := strings.Fields(lines[1])
-			if len(memFields) >= 7 {
-				metrics.Total, _ = strconv.ParseUint(memFields[1], 10, 64)
-				metrics.Used, _ = strconv.ParseUint(memFields[2], 10, 64)
-				metrics.Available, _ = strconv.ParseUint(memFields[6], 10, 64)
-				if metrics.Total > 0 {
-					metrics.UsedPercent = float64(metrics.Used) / float64(metrics.Total) * 100
-				}
-			}
+	all := cpuUsageBetween(prevAll, curAll)
+	metrics.Usage = all.Usage
+	metrics.User = all.User
+	metrics.Nice = all.Nice
+	metrics.System = all.System
+	metrics.IOWait = all.IOWait
+	metrics.IRQ = all.IRQ
+	metrics.SoftIRQ = all.SoftIRQ
+	metrics.Steal = all.Steal
+	metrics.Idle = all.Idle
+
+	// 核心可能在两次采样之间上下线，按名称匹配
+	prevByName := make(map[string]cpuTimes, len(prevCores))
+	for _, t := range prevCores {
+		prevByName[t.CPU] = t
+	}
+	for _, t := range curCores {
+		if p, ok := prevByName[t.CPU]; ok {
+			metrics.PerCore = append(metrics.PerCore, cpuUsageBetween(p, t))
 		}
+	}
+	if len(curCores) > 0 {
+		metrics.Cores = len(curCores)
+	}
 
-		// 解析交换分区
-		if len(lines) >= 3 {
-			swapFields := strings.Fields(lines[2])
-			if len(swapFields) >= 3 {
-				metrics.SwapTotal, _ = strconv.ParseUint(swapFields[1], 10, 64)
-				metrics.SwapUsed, _ = strconv.ParseUint(swapFields[2], 10, 64)
-			}
-		}
+	// 获取负载平均值
+	data, err := mc.readProc("loadavg")
+	if err != nil {
+		return metrics, fmt.Errorf("failed to read load average: %w", err)
+	}
+	metrics.LoadAvg1, metrics.LoadAvg5, metrics.LoadAvg15, err = parseLoadavg(data)
+	if err != nil {
+		return metrics, err
 	}
 
 	return metrics, nil
 }
 
+// collectMemoryMetrics 采集内存指标
+func (mc *MetricsCollector) collectMemoryMetrics() (MemoryMetrics, error) {
+	if runtime.GOOS != "linux" {
+		return MemoryMetrics{}, nil
+	}
+
+	data, err := mc.readProc("meminfo")
+	if err != nil {
+		return MemoryMetrics{}, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	return memoryFromMeminfo(data)
+}
+
 // collectDiskMetrics 采集磁盘指标
+// 挂载点从 mountinfo 枚举后逐个 statfs，不调用会被卡死挂载阻塞的 df
 func (mc *MetricsCollector) collectDiskMetrics() ([]DiskMetrics, error) {
-	var metrics []DiskMetrics
-
-	if runtime.GOOS == "linux" {
-		out, err := exec.Command("df", "-B1", "-T").Output()
-		if err != nil {
-			return nil, err
-		}
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
 
-		lines := strings.Split(string(out), "\n")
-		for i, line := range lines {
-			if i == 0 || line == "" {
-				continue
-			}
+	// statfs(2) 只能作用于本机，远程节点使用 stat -f
+	stat := remoteStatfs(mc.executor)
+	if isLocal(mc.executor) {
+		stat = localStatfs(mc.statfs)
+	}
 
-			fields := strings.Fields(line)
-			if len(fields) >= 7 {
-				total, _ := strconv.ParseUint(fields[2], 10, 64)
-				used, _ := strconv.ParseUint(fields[3], 10, 64)
-				avail, _ := strconv.ParseUint(fields[4], 10, 64)
-
-				var usedPercent float64
-				if total > 0 {
-					usedPercent = float64(used) / float64(total) * 100
-				}
-
-				metrics = append(metrics, DiskMetrics{
-					Device:      fields[0],
-					FSType:      fields[1],
-					Total:       total,
-					Used:        used,
-					Available:   avail,
-					UsedPercent: usedPercent,
-					MountPoint:  fields[6],
-				})
-			}
-		}
+	usages, err := statMounts(context.Background(), mc.executor, mc.procRoot, stat, mc.mountWait)
+	if err != nil {
+		return nil, err
 	}
 
+	metrics := make([]DiskMetrics, 0, len(usages))
+	for _, u := range usages {
+		metrics = append(metrics, diskMetricsFromUsage(u))
+	}
 	return metrics, nil
 }
 
-// collectNetworkMetrics 采集网络指标
-func (mc *MetricsCollector) collectNetworkMetrics() ([]NetworkMetrics, error) {
-	var metrics []NetworkMetrics
+// diskMetricsFromUsage 根据 statfs 结果计算容量和 inode 使用率
+func diskMetricsFromUsage(u mountUsage) DiskMetrics {
+	disk := DiskMetrics{
+		Device:       u.Mount.Device,
+		MountPoint:   u.Mount.MountPoint,
+		FSType:       u.Mount.FSType,
+		MountOptions: u.Mount.Options,
+		Stale:        u.Stale,
+	}
+	if u.Stale {
+		return disk
+	}
 
-	if runtime.GOOS == "linux" {
-		out, err := exec.Command("cat", "/proc/net/dev").Output()
-		if err != nil {
-			return nil, err
-		}
+	usage := u.Usage
+	disk.Total = usage.Total
+	disk.Used = usage.Total - usage.Free
+	disk.Available = usage.Available
+	if usage.Total > 0 {
+		disk.UsedPercent = float64(disk.Used) / float64(usage.Total) * 100
+	}
 
-		lines := strings.Split(string(out), "\n")
-		for i, line := range lines {
-			if i < 2 || line == "" {
-				continue
-			}
+	// btrfs 等动态分配 inode 的文件系统报告的总数为 0
+	disk.InodesTotal = usage.Files
+	disk.InodesFree = usage.FilesFree
+	if usage.Files > 0 {
+		disk.InodesUsed = usage.Files - usage.FilesFree
+		disk.InodesUsedPercent = float64(disk.InodesUsed) / float64(usage.Files) * 100
+	}
+	return disk
+}
 
-			parts := strings.Split(line, ":")
-			if len(parts) != 2 {
-				continue
-			}
+// collectNetworkMetrics 采集网络指标
+func (mc *MetricsCollector) collectNetworkMetrics() ([]NetworkMetrics, error) {
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
 
-			iface := strings.TrimSpace(parts[0])
-			fields := strings.Fields(parts[1])
-			if len(fields) >= 16 {
-				bytesRecv, _ := strconv.ParseUint(fields[0], 10, 64)
-				packetsRecv, _ := strconv.ParseUint(fields[1], 10, 64)
-				bytesSent, _ := strconv.ParseUint(fields[8], 10, 64)
-				packetsSent, _ := strconv.ParseUint(fields[9], 10, 64)
-
-				metrics = append(metrics, NetworkMetrics{
-					Interface:   iface,
-					BytesRecv:   bytesRecv,
-					PacketsRecv: packetsRecv,
-					BytesSent:   bytesSent,
-					PacketsSent: packetsSent,
-				})
-			}
-		}
+	data, err := mc.readProc("net", "dev")
+	if err != nil {
+		return nil, err
 	}
+	return parseNetDev(data), nil
+}
 
-	return metrics, nil
+// collectDiskIOMetrics 采集块设备 I/O 累计计数
+func (mc *MetricsCollector) collectDiskIOMetrics() ([]DiskIOMetrics, error) {
+	if runtime.GOOS != "linux" {
+		return nil, nil
+	}
+
+	data, err := mc.readProc("diskstats")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiskstats(data), nil
 }
 
 // collectProcessMetrics 采集进程指标
 func (mc *MetricsCollector) collectProcessMetrics() (ProcessMetrics, error) {
 	metrics := ProcessMetrics{}
 
-	if runtime.GOOS == "linux" {
-		out, err := exec.Command("ps", "-eo", "state").Output()
+	if runtime.GOOS != "linux" {
+		return metrics, nil
+	}
+
+	// 远程节点逐个读取 /proc/[pid]/stat 代价过高，使用 ps 一次获取
+	if !isLocal(mc.executor) {
+		out, err := mc.executor.Run(context.Background(), "ps", "-eo", "state")
 		if err != nil {
 			return metrics, err
 		}
-
-		lines := strings.Split(string(out), "\n")
-		for i, line := range lines {
-			if i == 0 || line == "" {
+		for i, line := range strings.Split(out, "\n") {
+			state := strings.TrimSpace(line)
+			if i == 0 || state == "" {
 				continue
 			}
+			metrics.count(state[0])
+		}
+		return metrics, nil
+	}
 
-			state := strings.TrimSpace(line)
-			metrics.Total++
-
-			switch state[0] {
-			case 'R':
-				metrics.Running++
-			case 'S', 'D':
-				metrics.Sleeping++
-			case 'Z':
-				metrics.Zombie++
-			}
+	files, err := mc.executor.Glob(context.Background(), mc.procPath("[0-9]*", "stat"))
+	if err != nil {
+		return metrics, err
+	}
+	if len(files) == 0 {
+		return metrics, fmt.Errorf("no processes found under %s", mc.procRoot)
+	}
+
+	for _, file := range files {
+		// 进程可能在列出后退出，忽略读取失败
+		data, err := mc.executor.ReadFile(context.Background(), file)
+		if err != nil {
+			continue
 		}
+		state, err := parseProcessState(string(data))
+		if err != nil {
+			continue
+		}
+		metrics.count(state)
 	}
 
 	return metrics, nil
 }
+
+// count 按进程状态计数
+func (m *ProcessMetrics) count(state byte) {
+	m.Total++
+
+	switch state {
+	case 'R':
+		m.Running++
+	case 'S', 'D':
+		m.Sleeping++
+	case 'Z':
+		m.Zombie++
+	}
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrTruncatedRecord NDJSON 流的最后一行不完整，通常是采集进程在写入时被中断
+var ErrTruncatedRecord = errors.New("truncated ndjson record")
+
+// NDJSONWriter 以每行一个 JSON 对象的形式写出记录
+// 每条记录通过一次 Write 调用写出，进程中断时已写入的记录保持完整
+type NDJSONWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewNDJSONWriter 创建 NDJSON 写入器
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w}
+}
+
+// Write 写出一条记录，可并发调用
+func (w *NDJSONWriter) Write(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode ndjson record: %w", err)
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write ndjson record: %w", err)
+	}
+	return nil
+}
+
+// NDJSONReader 逐行读取 NDJSON 流，内存占用只与单条记录大小有关
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewNDJSONReader 创建 NDJSON 读取器
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r)}
+}
+
+// Next 将下一条记录解码到 v，没有更多记录时返回 io.EOF
+// 空行被忽略；没有换行结尾且无法解码的最后一行返回 ErrTruncatedRecord
+func (r *NDJSONReader) Next(v interface{}) error {
+	for {
+		data, err := r.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		complete := err == nil
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			if !complete {
+				return io.EOF
+			}
+			continue
+		}
+
+		if decodeErr := json.Unmarshal(data, v); decodeErr != nil {
+			if !complete {
+				return fmt.Errorf("line %d: %w", r.line, ErrTruncatedRecord)
+			}
+			return fmt.Errorf("line %d: %w", r.line, decodeErr)
+		}
+		return nil
+	}
+}
+
+// IsNDJSON 判断数据是否为多条 JSON 记录组成的 NDJSON 流
+// 只包含一个 JSON 文档（即使跨多行）时返回 false
+func IsNDJSON(data []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(first), []byte("{")) && dec.More()
+}
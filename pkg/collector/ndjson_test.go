@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	for _, node := range []string{"node-a", "node-b"} {
+		if err := w.Write(&SystemInfo{Node: node, Hostname: node, Status: NodeStatusOK}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("Expected one line per record, got %d:\n%s", lines, buf.String())
+	}
+
+	r := NewNDJSONReader(&buf)
+	var nodes []string
+	for {
+		var info SystemInfo
+		err := r.Next(&info)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, info.Node)
+	}
+	if strings.Join(nodes, ",") != "node-a,node-b" {
+		t.Errorf("Unexpected records: %v", nodes)
+	}
+}
+
+func TestNDJSONReaderTruncated(t *testing.T) {
+	// 采集中断时最后一行只写了一半
+	input := `{"node":"node-a","hostname":"a"}` + "\n\n" + `{"node":"node-b","host`
+	r := NewNDJSONReader(strings.NewReader(input))
+
+	var info SystemInfo
+	if err := r.Next(&info); err != nil || info.Node != "node-a" {
+		t.Fatalf("Expected first record, got %+v, %v", info, err)
+	}
+	if err := r.Next(&info); !errors.Is(err, ErrTruncatedRecord) {
+		t.Errorf("Expected truncated record error, got %v", err)
+	}
+
+	r = NewNDJSONReader(strings.NewReader("{\"node\":1}\n"))
+	if err := r.Next(&info); err == nil || errors.Is(err, ErrTruncatedRecord) {
+		t.Errorf("Expected decode error for complete invalid line, got %v", err)
+	}
+}
+
+func TestIsNDJSON(t *testing.T) {
+	tests := map[string]bool{
+		"{\"a\":1}\n{\"a\":2}\n":        true,
+		"{\"a\":1}\n{\"a\":":            true,
+		"{\n  \"a\": 1,\n  \"b\": 2\n}": false,
+		"{\"a\":1}\n":                   false,
+		"[1]\n[2]\n":                    false,
+		"not json":                      false,
+	}
+	for input, want := range tests {
+		if got := IsNDJSON([]byte(input)); got != want {
+			t.Errorf("IsNDJSON(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestCollectStreamSinkError(t *testing.T) {
+	sc := NewSystemCollector(Config{}, false)
+	nodes := []string{"10.255.255.1", "10.255.255.2", "10.255.255.3"}
+
+	calls := 0
+	errFull := errors.New("disk full")
+	err := sc.CollectStream(context.Background(), nodes, 1, func(info *SystemInfo) error {
+		calls++
+		if info.Node == "" || info.Status != NodeStatusFailed {
+			t.Errorf("Expected failed record with node name, got %+v", info)
+		}
+		return errFull
+	})
+	if !errors.Is(err, errFull) {
+		t.Errorf("Expected sink error to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected collection to stop after sink error, got %d records", calls)
+	}
+}
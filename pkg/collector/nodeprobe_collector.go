@@ -3,19 +3,26 @@ package collector
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/devops-toolkit/clusterreport/pkg/parser"
 )
 
 // NodeProbeCollector 包装 NodeProbe 的功能
 type NodeProbeCollector struct {
-	autoOptimize bool // 是否启用自动优化功能
+	autoOptimize bool     // 是否启用自动优化功能
+	journalDir   string   // 自动优化的变更日志目录，为空时不保存
+	executor     Executor // 命令执行器，默认在本地执行
+	root         *bool    // 缓存的目标节点 root 权限检查结果
+	warnings     parseWarnings
 }
 
 // NodeProbeData 存储 NodeProbe 收集的数据
@@ -32,8 +39,11 @@ type NodeProbeData struct {
 	Python        NodeProbePythonInfo    `json:"python" yaml:"python"`
 	Java          NodeProbeJavaInfo      `json:"java" yaml:"java"`
 	KernelModules NodeProbeKernelModules `json:"kernel_modules" yaml:"kernel_modules"`
+	Remediation   *Journal               `json:"remediation,omitempty" yaml:"remediation,omitempty"`
 	Timestamp     string                 `json:"timestamp" yaml:"timestamp"`
 	Version       string                 `json:"nodeprobe_version" yaml:"nodeprobe_version"`
+	ParserVersion int                    `json:"parser_version" yaml:"parser_version"`
+	ParseWarnings []string               `json:"parse_warnings,omitempty" yaml:"parse_warnings,omitempty"`
 }
 
 type NodeProbeCPUInfo struct {
@@ -58,6 +68,10 @@ type NodeProbeDiskInfo struct {
 	DataDisks   []string `json:"data_disks" yaml:"data_disks"`
 	TotalDisks  int      `json:"total_disks" yaml:"total_disks"`
 	DataDiskNum int      `json:"data_disk_num" yaml:"data_disk_num"`
+
+	// 由 /sys/block 和 /proc/mdstat 构建的块设备拓扑，读取失败时为空
+	Devices     []BlockDevice     `json:"devices,omitempty" yaml:"devices,omitempty"`
+	Filesystems []BlockFilesystem `json:"filesystems,omitempty" yaml:"filesystems,omitempty"`
 }
 
 type NodeProbeNetworkIF struct {
@@ -87,14 +101,40 @@ type NodeProbeKernelModules struct {
 func NewNodeProbeCollector(autoOptimize bool) *NodeProbeCollector {
 	return &NodeProbeCollector{
 		autoOptimize: autoOptimize,
+		executor:     NewLocalExecutor(DefaultExecOptions()),
 	}
 }
 
+// SetExecutor 设置命令执行器，用于在远程节点上采集
+func (c *NodeProbeCollector) SetExecutor(executor Executor) {
+	c.executor = executor
+	c.root = nil
+}
+
+// SetJournalDir 设置自动优化的变更日志目录，日志在修改节点前写入并随每项修改更新
+func (c *NodeProbeCollector) SetJournalDir(dir string) {
+	c.journalDir = dir
+}
+
 // Collect 执行 NodeProbe 数据收集
 func (c *NodeProbeCollector) Collect() (*NodeProbeData, error) {
 	data := &NodeProbeData{
-		Version:   "1.1.1",
-		Timestamp: getCurrentTimestamp(),
+		Version:       "1.1.1",
+		Timestamp:     getCurrentTimestamp(),
+		ParserVersion: parser.Version,
+	}
+	c.warnings = nil
+
+	// 自动优化在采集前执行，采集结果反映优化后的状态；所有修改及原值记入变更日志
+	// 单项修改失败只记录在日志中，日志无法保存时停止采集
+	if c.autoOptimize && c.isRoot() {
+		remediator := NewRemediator(c.executor)
+		remediator.SetJournalDir(c.journalDir)
+		journal, err := remediator.Apply(context.Background(), c.Plan())
+		if errors.Is(err, ErrJournalNotSaved) {
+			return nil, err
+		}
+		data.Remediation = journal
 	}
 
 	// 收集各项信息
@@ -108,23 +148,24 @@ func (c *NodeProbeCollector) Collect() (*NodeProbeData, error) {
 	data.Network = c.getNetworkInfo()
 	data.Python = c.getPythonInfo()
 	data.Java = c.getJavaInfo()
-	data.KernelModules = c.checkAndLoadKernelModules()
+	data.KernelModules = c.checkKernelModules()
+	data.ParseWarnings = []string(c.warnings)
 
 	return data, nil
 }
 
 // 获取主机名
 func (c *NodeProbeCollector) getHostname() string {
-	hostname, err := os.Hostname()
-	if err != nil {
+	output, err := c.executor.Run(context.Background(), "hostname")
+	if err != nil || strings.TrimSpace(output) == "" {
 		return "unknown"
 	}
-	return hostname
+	return strings.TrimSpace(output)
 }
 
 // 获取系统负载
 func (c *NodeProbeCollector) getLoadAverage() string {
-	data, err := os.ReadFile("/proc/loadavg")
+	data, err := c.readFile("/proc/loadavg")
 	if err != nil {
 		return "N/A"
 	}
@@ -135,54 +176,13 @@ func (c *NodeProbeCollector) getLoadAverage() string {
 	return "N/A"
 }
 
-// 获取时区信息并校准
+// 获取时区信息
 func (c *NodeProbeCollector) getTimezone() string {
-	var currentTZ string
-
-	// 尝试使用timedatectl命令
-	if output, err := execCommand("timedatectl", "show", "--property=Timezone", "--value"); err == nil {
-		currentTZ = strings.TrimSpace(output)
-		if currentTZ != "" {
-			// 如果启用自动优化且当前时区不是Asia/Shanghai，尝试设置
-			if c.autoOptimize && currentTZ != "Asia/Shanghai" && os.Geteuid() == 0 {
-				if _, err := execCommand("timedatectl", "set-timezone", "Asia/Shanghai"); err == nil {
-					return fmt.Sprintf("已校准至 Asia/Shanghai (原: %s)", currentTZ)
-				}
-			}
-			return currentTZ
-		}
-	}
-
-	// 尝试读取/etc/timezone文件
-	if data, err := os.ReadFile("/etc/timezone"); err == nil {
-		currentTZ = strings.TrimSpace(string(data))
-		if currentTZ != "" {
-			if c.autoOptimize && currentTZ != "Asia/Shanghai" && os.Geteuid() == 0 {
-				if err := os.WriteFile("/etc/timezone", []byte("Asia/Shanghai\n"), 0644); err == nil {
-					if _, err := execCommand("ln", "-sf", "/usr/share/zoneinfo/Asia/Shanghai", "/etc/localtime"); err == nil {
-						return fmt.Sprintf("已校准至 Asia/Shanghai (原: %s)", currentTZ)
-					}
-				}
-			}
-			return currentTZ
-		}
-	}
-
-	// 尝试读取/etc/localtime符号链接
-	if link, err := os.Readlink("/etc/localtime"); err == nil {
-		parts := strings.Split(link, "zoneinfo/")
-		if len(parts) == 2 {
-			currentTZ = parts[1]
-			if c.autoOptimize && currentTZ != "Asia/Shanghai" && os.Geteuid() == 0 {
-				if _, err := execCommand("ln", "-sf", "/usr/share/zoneinfo/Asia/Shanghai", "/etc/localtime"); err == nil {
-					return fmt.Sprintf("已校准至 Asia/Shanghai (原: %s)", currentTZ)
-				}
-			}
-			return currentTZ
-		}
+	tz, err := currentTimezone(context.Background(), c.executor)
+	if err != nil {
+		return "Unknown"
 	}
-
-	return "Unknown"
+	return tz
 }
 
 // 获取操作系统信息
@@ -191,7 +191,7 @@ func (c *NodeProbeCollector) getOSInfo() (string, string) {
 	kernel := "Unknown"
 
 	// 获取发行版信息
-	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+	if data, err := c.readFile("/etc/os-release"); err == nil {
 		scanner := bufio.NewScanner(bytes.NewReader(data))
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -203,7 +203,7 @@ func (c *NodeProbeCollector) getOSInfo() (string, string) {
 	}
 
 	// 获取内核版本
-	if data, err := os.ReadFile("/proc/version"); err == nil {
+	if data, err := c.readFile("/proc/version"); err == nil {
 		fields := strings.Fields(string(data))
 		if len(fields) >= 3 {
 			kernel = fields[2]
@@ -217,30 +217,16 @@ func (c *NodeProbeCollector) getOSInfo() (string, string) {
 func (c *NodeProbeCollector) getCPUInfo() NodeProbeCPUInfo {
 	info := NodeProbeCPUInfo{}
 
-	data, err := os.ReadFile("/proc/cpuinfo")
+	data, err := c.readFile("/proc/cpuinfo")
 	if err != nil {
 		return info
 	}
 
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	cores := 0
-	modelFound := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "model name") && !modelFound {
-			parts := strings.Split(line, ":")
-			if len(parts) == 2 {
-				info.Model = strings.TrimSpace(parts[1])
-				modelFound = true
-			}
-		}
-		if strings.Contains(line, "processor") {
-			cores++
-		}
+	processors := parseCPUInfoBlocks(string(data))
+	if len(processors) > 0 {
+		info.Model = cpuModel(processors[0])
 	}
-
-	info.Cores = cores
+	info.Cores = len(processors)
 	info.RunMode = c.getCPURunMode()
 	info.PerformanceMode = c.getCPUPerformanceMode()
 
@@ -249,19 +235,18 @@ func (c *NodeProbeCollector) getCPUInfo() NodeProbeCPUInfo {
 
 // 获取CPU运行模式
 func (c *NodeProbeCollector) getCPURunMode() string {
-	if output, err := execCommand("lscpu"); err == nil {
-		lines := strings.Split(output, "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "CPU op-mode(s)") {
-				parts := strings.Split(line, ":")
-				if len(parts) == 2 {
-					return strings.TrimSpace(parts[1])
-				}
+	if output, err := c.execCommand("lscpu"); err == nil {
+		if result, err := parser.ParseLSCPU(output); err != nil {
+			c.warnings.add("lscpu", err.Error())
+		} else {
+			c.warnings.add("lscpu", result.Warnings...)
+			if result.OpModes != "" {
+				return result.OpModes
 			}
 		}
 	}
 
-	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+	if data, err := c.readFile("/proc/cpuinfo"); err == nil {
 		if strings.Contains(string(data), "lm") {
 			return "32-bit, 64-bit"
 		}
@@ -280,35 +265,18 @@ func (c *NodeProbeCollector) getCPUPerformanceMode() string {
 
 	var currentGovernor string
 	for _, file := range governorFiles {
-		if data, err := os.ReadFile(file); err == nil {
+		if data, err := c.readFile(file); err == nil {
 			currentGovernor = strings.TrimSpace(string(data))
 			break
 		}
 	}
 
-	// 如果启用自动优化且当前是省电模式且以root权限运行，自动切换到性能模式
-	if c.autoOptimize && currentGovernor == "powersave" && os.Geteuid() == 0 {
-		cpuGovernorPattern := "/sys/devices/system/cpu/cpu*/cpufreq/scaling_governor"
-		files, err := filepath.Glob(cpuGovernorPattern)
-		if err == nil && len(files) > 0 {
-			successCount := 0
-			for _, file := range files {
-				if err := os.WriteFile(file, []byte("performance\n"), 0644); err == nil {
-					successCount++
-				}
-			}
-			if successCount > 0 {
-				return fmt.Sprintf("已自动调整至最大性能模式 (原: powersave, 成功调整 %d 个CPU)", successCount)
-			}
-		}
-	}
-
 	if currentGovernor != "" {
 		switch currentGovernor {
 		case "performance":
 			return "最大性能模式 (performance)"
 		case "powersave":
-			if os.Geteuid() != 0 {
+			if !c.isRoot() {
 				return "省电模式 (powersave) - 需要root权限才能自动调整"
 			}
 			return "省电模式 (powersave)"
@@ -331,7 +299,7 @@ func (c *NodeProbeCollector) getMemoryInfo() NodeProbeMemoryInfo {
 	info := NodeProbeMemoryInfo{}
 
 	// 获取总内存
-	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+	if data, err := c.readFile("/proc/meminfo"); err == nil {
 		scanner := bufio.NewScanner(bytes.NewReader(data))
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -347,8 +315,10 @@ func (c *NodeProbeCollector) getMemoryInfo() NodeProbeMemoryInfo {
 		}
 	}
 
-	// 获取内存插槽信息
-	if output, err := execCommand("dmidecode", "-t", "17"); err == nil {
+	// 获取内存插槽信息，优先解析原始 SMBIOS 表，不可读时使用 dmidecode
+	if slots, ok := c.getMemorySlotsFromSMBIOS(); ok {
+		info.Slots = slots
+	} else if output, err := c.execCommand("dmidecode", "-t", "17"); err == nil {
 		lines := strings.Split(output, "\n")
 		var currentSlot NodeProbeMemorySlot
 		var hasSize bool
@@ -395,46 +365,89 @@ func (c *NodeProbeCollector) getMemoryInfo() NodeProbeMemoryInfo {
 	return info
 }
 
+// getMemorySlotsFromSMBIOS 从 /sys/firmware/dmi/tables/DMI 读取已安装的内存，大小格式与 dmidecode 一致
+func (c *NodeProbeCollector) getMemorySlotsFromSMBIOS() ([]NodeProbeMemorySlot, bool) {
+	data, err := c.readFile("/sys/firmware/dmi/tables/DMI")
+	if err != nil {
+		return nil, false
+	}
+	structures, err := parseSMBIOSTable(data)
+	if err != nil {
+		c.warnings.add("smbios", err.Error())
+	}
+
+	var inventory HardwareInventory
+	inventory.applySMBIOS(structures)
+	if inventory.MemorySlots == 0 {
+		return nil, false
+	}
+
+	slots := []NodeProbeMemorySlot{}
+	for _, dimm := range inventory.DIMMs {
+		size := fmt.Sprintf("%d MB", dimm.Size>>20)
+		if dimm.Size%(1<<30) == 0 {
+			size = fmt.Sprintf("%d GB", dimm.Size>>30)
+		}
+		slots = append(slots, NodeProbeMemorySlot{Location: dimm.Locator, Size: size})
+	}
+	return slots, true
+}
+
 // 获取磁盘信息
 func (c *NodeProbeCollector) getDiskInfo() NodeProbeDiskInfo {
 	info := NodeProbeDiskInfo{}
 
 	// 获取系统盘挂载信息
-	if output, err := execCommand("df", "-h", "/"); err == nil {
-		lines := strings.Split(output, "\n")
-		if len(lines) > 1 {
-			for i := 1; i < len(lines); i++ {
-				line := lines[i]
-				if line == "" {
-					continue
-				}
-
-				fields := strings.Fields(line)
-				if len(fields) >= 6 && fields[5] == "/" {
-					info.SystemDisk = fmt.Sprintf("%s %s/%s (%s)",
-						fields[0], fields[3], fields[2], fields[4])
+	if output, err := c.execCommand("df", "-h", "/"); err == nil {
+		if result, err := parser.ParseDF(output); err != nil {
+			c.warnings.add("df", err.Error())
+		} else {
+			c.warnings.add("df", result.Warnings...)
+			for _, fs := range result.Filesystems {
+				if fs.MountPoint == "/" {
+					info.SystemDisk = fmt.Sprintf("%s %s/%s (%.0f%%)",
+						fs.Filesystem, parser.FormatSize(fs.Available), parser.FormatSize(fs.Used), fs.UsePercent)
 					break
 				}
 			}
 		}
 	}
 
-	// 获取所有磁盘信息
-	if output, err := execCommand("lsblk", "-d", "-o", "NAME,SIZE,TYPE"); err == nil {
-		lines := strings.Split(output, "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "disk") {
-				fields := strings.Fields(line)
-				if len(fields) >= 2 {
-					diskInfo := fmt.Sprintf("/dev/%s %s", fields[0], fields[1])
-					// 判断是否为数据盘（默认大于1T的认为是数据盘）
-					if strings.Contains(fields[1], "T") ||
-						(strings.Contains(fields[1], "G") && c.parseSize(fields[1]) > 1000) {
-						info.DataDisks = append(info.DataDisks, diskInfo)
-						info.DataDiskNum++
-					}
-					info.TotalDisks++
+	// 优先按块设备拓扑区分系统盘和数据盘
+	topology, err := CollectBlockTopology(context.Background(), c.executor, DefaultProcRoot, DefaultSysRoot)
+	if err == nil && len(topology.Devices) > 0 {
+		c.warnings.add("blockdevices", topology.Warnings...)
+		info.Devices = topology.Devices
+		info.Filesystems = topology.Filesystems
+		info.DataDisks, info.TotalDisks = classifyNodeDisks(topology)
+		info.DataDiskNum = len(info.DataDisks)
+		for _, fs := range topology.Filesystems {
+			if fs.MountPoint == "/" && info.SystemDisk != "" && len(fs.Disks) > 0 {
+				info.SystemDisk += " on " + strings.Join(fs.Disks, ",")
+			}
+		}
+		return info
+	}
+	if err != nil {
+		c.warnings.add("blockdevices", err.Error())
+	}
+
+	// 拓扑不可用时退回 lsblk：大于 1T 的磁盘认为是数据盘
+	if output, err := c.execCommand("lsblk", "-d", "-o", "NAME,SIZE,TYPE"); err == nil {
+		if result, err := parser.ParseLSBlk(output); err != nil {
+			c.warnings.add("lsblk", err.Error())
+		} else {
+			c.warnings.add("lsblk", result.Warnings...)
+			for _, dev := range result.Devices {
+				if dev.Type != "disk" {
+					continue
 				}
+				diskInfo := fmt.Sprintf("/dev/%s %s", dev.Name, dev.Fields["SIZE"])
+				if dev.Size > 1000<<30 {
+					info.DataDisks = append(info.DataDisks, diskInfo)
+					info.DataDiskNum++
+				}
+				info.TotalDisks++
 			}
 		}
 	}
@@ -442,81 +455,76 @@ func (c *NodeProbeCollector) getDiskInfo() NodeProbeDiskInfo {
 	return info
 }
 
-// 解析磁盘大小
-func (c *NodeProbeCollector) parseSize(sizeStr string) float64 {
-	re := regexp.MustCompile(`(\d+\.?\d*)([KMGT])`)
-	matches := re.FindStringSubmatch(sizeStr)
-	if len(matches) == 3 {
-		size, _ := strconv.ParseFloat(matches[1], 64)
-		unit := matches[2]
-		switch unit {
-		case "T":
-			return size * 1024
-		case "G":
-			return size
-		case "M":
-			return size / 1024
-		case "K":
-			return size / 1024 / 1024
+// classifyNodeDisks 返回数据盘列表和磁盘总数
+// 承载系统挂载点的磁盘为系统盘；多路径设备按聚合后的设备计数，不重复统计其各条路径
+func classifyNodeDisks(topology *BlockTopology) ([]string, int) {
+	system := topology.SystemDisks()
+
+	var dataDisks []string
+	total := 0
+	for _, dev := range topology.Devices {
+		switch {
+		case dev.Type == BlockTypeMultipath:
+		case dev.Type == BlockTypeDisk && dev.Multipath == nil:
+		default:
+			continue
+		}
+		total++
+		if system[dev.Name] {
+			continue
+		}
+		desc := fmt.Sprintf("%s %s", dev.Path, parser.FormatSize(dev.Size))
+		if media := firstNonEmptyString(dev.Media, dev.Type); media != BlockTypeDisk {
+			desc += " " + media
 		}
+		dataDisks = append(dataDisks, desc)
 	}
-	return 0
+	return dataDisks, total
 }
 
 // 获取网络接口信息
 func (c *NodeProbeCollector) getNetworkInfo() []NodeProbeNetworkIF {
 	var interfaces []NodeProbeNetworkIF
 
-	if output, err := execCommand("ip", "addr", "show"); err == nil {
-		lines := strings.Split(output, "\n")
-		var currentIface NodeProbeNetworkIF
-
-		for _, line := range lines {
-			if matches := regexp.MustCompile(`^\d+:\s+(\S+):\s+<(.+?)>`).FindStringSubmatch(line); len(matches) > 2 {
-				if currentIface.Name != "" {
-					interfaces = append(interfaces, currentIface)
-				}
-				currentIface = NodeProbeNetworkIF{Name: matches[1]}
-				if strings.Contains(matches[2], "UP") {
-					currentIface.Status = "UP"
-				} else {
-					currentIface.Status = "DOWN"
-				}
-			}
-
-			if strings.Contains(line, "inet ") && !strings.Contains(line, "inet6") {
-				fields := strings.Fields(line)
-				for i, field := range fields {
-					if field == "inet" && i+1 < len(fields) {
-						currentIface.IP = fields[i+1]
-						break
-					}
-				}
-			}
+	output, err := c.execCommand("ip", "addr", "show")
+	if err != nil {
+		return interfaces
+	}
+	result, err := parser.ParseIPAddr(output)
+	if err != nil {
+		c.warnings.add("ip", err.Error())
+		return interfaces
+	}
+	c.warnings.add("ip", result.Warnings...)
 
-			if currentIface.Name != "" && currentIface.Name != "lo" {
-				if speedOutput, err := execCommand("ethtool", currentIface.Name); err == nil {
-					if matches := regexp.MustCompile(`Speed:\s+(\S+)`).FindStringSubmatch(speedOutput); len(matches) > 1 {
-						currentIface.Speed = matches[1]
-					}
-				}
-			}
+	// 过滤掉lo接口
+	for _, iface := range result.Interfaces {
+		if iface.Name == "lo" {
+			continue
 		}
-
-		if currentIface.Name != "" {
-			interfaces = append(interfaces, currentIface)
+		netIF := NodeProbeNetworkIF{Name: iface.Name, Status: "DOWN", IP: ipv4CIDR(iface)}
+		if iface.HasFlag("UP") {
+			netIF.Status = "UP"
 		}
+		if speedOutput, err := c.execCommand("ethtool", iface.Name); err == nil {
+			if matches := regexp.MustCompile(`Speed:\s+(\S+)`).FindStringSubmatch(speedOutput); len(matches) > 1 {
+				netIF.Speed = matches[1]
+			}
+		}
+		interfaces = append(interfaces, netIF)
 	}
 
-	// 过滤掉lo接口
-	var filtered []NodeProbeNetworkIF
-	for _, iface := range interfaces {
-		if iface.Name != "lo" {
-			filtered = append(filtered, iface)
+	return interfaces
+}
+
+// ipv4CIDR 返回网卡第一个 IPv4 地址，带前缀长度
+func ipv4CIDR(iface parser.IPInterface) string {
+	for _, addr := range iface.Addresses {
+		if addr.Family == "inet" {
+			return fmt.Sprintf("%s/%d", addr.Address, addr.PrefixLen)
 		}
 	}
-
-	return filtered
+	return ""
 }
 
 // 获取Python信息
@@ -526,12 +534,12 @@ func (c *NodeProbeCollector) getPythonInfo() NodeProbePythonInfo {
 	pythonCommands := []string{"python3", "python", "python2"}
 
 	for _, cmd := range pythonCommands {
-		if output, err := execCommand(cmd, "--version"); err == nil {
+		if output, err := c.execCommand(cmd, "--version"); err == nil {
 			versionStr := strings.TrimSpace(output)
 			if versionStr != "" {
 				info.Version = versionStr
 
-				if pathOutput, err := execCommand("which", cmd); err == nil {
+				if pathOutput, err := c.execCommand("which", cmd); err == nil {
 					info.Path = strings.TrimSpace(pathOutput)
 				}
 				break
@@ -551,7 +559,7 @@ func (c *NodeProbeCollector) getPythonInfo() NodeProbePythonInfo {
 func (c *NodeProbeCollector) getJavaInfo() NodeProbeJavaInfo {
 	info := NodeProbeJavaInfo{}
 
-	if output, err := execCommand("java", "-version"); err == nil {
+	if output, err := c.execCommand("java", "-version"); err == nil {
 		lines := strings.Split(output, "\n")
 		for _, line := range lines {
 			if strings.Contains(line, "version") {
@@ -564,10 +572,10 @@ func (c *NodeProbeCollector) getJavaInfo() NodeProbeJavaInfo {
 			}
 		}
 
-		if pathOutput, err := execCommand("which", "java"); err == nil {
+		if pathOutput, err := c.execCommand("which", "java"); err == nil {
 			info.Path = strings.TrimSpace(pathOutput)
 
-			if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+			if javaHome := c.getEnv("JAVA_HOME"); javaHome != "" {
 				info.Path = fmt.Sprintf("%s (JAVA_HOME: %s)", info.Path, javaHome)
 			}
 		}
@@ -581,58 +589,131 @@ func (c *NodeProbeCollector) getJavaInfo() NodeProbeJavaInfo {
 	return info
 }
 
-// 检查和加载内核模块
-func (c *NodeProbeCollector) checkAndLoadKernelModules() NodeProbeKernelModules {
+// 检查内核模块
+func (c *NodeProbeCollector) checkKernelModules() NodeProbeKernelModules {
 	status := NodeProbeKernelModules{}
 
-	modules := []struct {
-		name  string
-		field *bool
-	}{
-		{"nf_conntrack", &status.NfConntrack},
-		{"br_netfilter", &status.BrNetfilter},
+	lsmod, _ := c.execCommand("lsmod")
+	loaded := map[string]*bool{
+		"nf_conntrack": &status.NfConntrack,
+		"br_netfilter": &status.BrNetfilter,
 	}
 
 	var messages []string
+	for _, name := range nodeProbeModules {
+		if moduleListed(lsmod, name) {
+			*loaded[name] = true
+			messages = append(messages, fmt.Sprintf("%s: 已加载", name))
+		} else if !c.isRoot() {
+			messages = append(messages, fmt.Sprintf("%s: 未加载(需要root权限)", name))
+		} else {
+			messages = append(messages, fmt.Sprintf("%s: 未加载", name))
+		}
+	}
 
-	for _, mod := range modules {
-		// 检查模块是否已加载
-		if output, err := execCommand("lsmod"); err == nil {
-			if strings.Contains(output, mod.name) {
-				*mod.field = true
-				messages = append(messages, fmt.Sprintf("%s: 已加载", mod.name))
-				continue
-			}
+	status.Message = strings.Join(messages, ", ")
+	return status
+}
+
+// nodeProbeModules NodeProbe 检查的内核模块
+var nodeProbeModules = []string{"nf_conntrack", "br_netfilter"}
+
+// nodeProbeTimezone 自动优化校准的目标时区
+const nodeProbeTimezone = "Asia/Shanghai"
+
+// Plan 生成自动优化的修复计划：省电模式的 CPU 切换为性能模式、加载缺失的内核模块、校准时区
+// 只读取节点状态，不做任何修改
+func (c *NodeProbeCollector) Plan() *RemediationPlan {
+	ctx := context.Background()
+	plan := &RemediationPlan{
+		Node:      c.getHostname(),
+		CreatedAt: time.Now(),
+	}
+
+	files, _ := c.executor.Glob(ctx, "/sys/devices/system/cpu/cpu*/cpufreq/scaling_governor")
+	for _, file := range files {
+		data, err := c.readFile(file)
+		if err != nil {
+			continue
+		}
+		if governor := strings.TrimSpace(string(data)); governor == "powersave" {
+			cpu := path.Base(path.Dir(path.Dir(file)))
+			plan.Actions = append(plan.Actions, RemediationAction{
+				ID:          "cpu_governor:" + cpu,
+				Kind:        ActionWriteFile,
+				Target:      file,
+				Description: fmt.Sprintf("%s 切换至最大性能模式", cpu),
+				From:        governor,
+				To:          "performance",
+			})
 		}
+	}
 
-		// 如果启用自动优化且未加载且是root用户，尝试加载
-		if c.autoOptimize && os.Geteuid() == 0 {
-			if _, err := execCommand("modprobe", mod.name); err == nil {
-				*mod.field = true
-				messages = append(messages, fmt.Sprintf("%s: 已自动加载", mod.name))
-			} else {
-				*mod.field = false
-				messages = append(messages, fmt.Sprintf("%s: 加载失败", mod.name))
-			}
-		} else {
-			*mod.field = false
-			if os.Geteuid() != 0 {
-				messages = append(messages, fmt.Sprintf("%s: 未加载(需要root权限)", mod.name))
-			} else {
-				messages = append(messages, fmt.Sprintf("%s: 未加载", mod.name))
+	if lsmod, err := c.execCommand("lsmod"); err == nil {
+		for _, name := range nodeProbeModules {
+			if !moduleListed(lsmod, name) {
+				plan.Actions = append(plan.Actions, RemediationAction{
+					ID:          "kernel_module:" + name,
+					Kind:        ActionLoadModule,
+					Target:      name,
+					Description: fmt.Sprintf("加载内核模块 %s", name),
+					From:        moduleUnloaded,
+					To:          moduleLoaded,
+				})
 			}
 		}
 	}
 
-	status.Message = strings.Join(messages, ", ")
-	return status
+	if tz, err := currentTimezone(ctx, c.executor); err == nil && tz != nodeProbeTimezone {
+		plan.Actions = append(plan.Actions, RemediationAction{
+			ID:          "timezone",
+			Kind:        ActionSetTimezone,
+			Target:      "timezone",
+			Description: fmt.Sprintf("校准时区至 %s", nodeProbeTimezone),
+			From:        tz,
+			To:          nodeProbeTimezone,
+		})
+	}
+
+	return plan
+}
+
+// execCommand 通过执行器执行命令，返回标准输出和标准错误的合并内容
+func (c *NodeProbeCollector) execCommand(command string, args ...string) (string, error) {
+	return c.executor.RunCombined(context.Background(), command, args...)
+}
+
+// readFile 通过执行器读取文件
+func (c *NodeProbeCollector) readFile(path string) ([]byte, error) {
+	return c.executor.ReadFile(context.Background(), path)
 }
 
-// execCommand 执行命令并返回输出
-func execCommand(command string, args ...string) (string, error) {
-	cmd := exec.Command(command, args...)
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+// getEnv 获取目标节点上的环境变量
+func (c *NodeProbeCollector) getEnv(name string) string {
+	if isLocal(c.executor) {
+		return os.Getenv(name)
+	}
+	output, err := c.executor.Run(context.Background(), "printenv", name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// isRoot 判断目标节点上是否以 root 权限运行
+func (c *NodeProbeCollector) isRoot() bool {
+	if c.root != nil {
+		return *c.root
+	}
+
+	root := false
+	if isLocal(c.executor) {
+		root = os.Geteuid() == 0
+	} else if output, err := c.executor.Run(context.Background(), "id", "-u"); err == nil {
+		root = strings.TrimSpace(output) == "0"
+	}
+	c.root = &root
+	return root
 }
 
 // getCurrentTimestamp 获取当前时间戳
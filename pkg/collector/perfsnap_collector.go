@@ -1,16 +1,44 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/devops-toolkit/clusterreport/pkg/parser"
 )
 
+// PerfScriptMaxOutputBytes perf script 输出的上限
+// 全系统调用栈在多核节点上可达数百 MiB，远超普通命令的 DefaultMaxOutputBytes；
+// 输出流式写入本地临时文件再逐行解析，上限约束的是磁盘占用而不是内存
+const PerfScriptMaxOutputBytes = 1 << 30
+
+// perfScriptTimeout 返回 perf script 的超时；符号解析耗时随采样量增长，与采集时长相当
+func perfScriptTimeout(duration int) time.Duration {
+	return time.Duration(duration)*time.Second + DefaultCommandTimeout
+}
+
 // PerfSnapCollector 包装 PerfSnap 的功能
 type PerfSnapCollector struct {
-	duration      int  // 采集持续时间（秒）
-	generateFlame bool // 是否生成火焰图
+	duration      int           // 采集持续时间（秒）
+	generateFlame bool          // 是否生成火焰图
+	executor      Executor      // 命令执行器，默认在本地执行
+	backend       string        // 采集后端：native 或 sysstat
+	interval      time.Duration // 时间序列采样间隔，0 表示只采集单个快照
+	profile       ProfileOptions
+	parseWarnings parseWarnings // 本次采集的解析问题
+}
+
+// ProfileOptions perf 采样结果的输出选项
+type ProfileOptions struct {
+	OutputDir    string // 火焰图、pprof 和折叠栈文件的输出目录，默认为系统临时目录
+	PIDs         []int  // 只保留这些进程的采样
+	TopProcesses bool   // 只保留 TopProcessesCPU 中进程的采样
 }
 
 // PerfSnapData 存储 PerfSnap 收集的数据
@@ -32,7 +60,15 @@ type PerfSnapData struct {
 	Issues          []PerfSnapIssue       `json:"issues" yaml:"issues"`
 	Recommendations []string              `json:"recommendations" yaml:"recommendations"`
 	FlameGraphPath  string                `json:"flame_graph_path,omitempty" yaml:"flame_graph_path,omitempty"`
+	PprofPath       string                `json:"pprof_path,omitempty" yaml:"pprof_path,omitempty"`
+	FoldedPath      string                `json:"folded_path,omitempty" yaml:"folded_path,omitempty"`
+	ProfilePIDs     []int                 `json:"profile_pids,omitempty" yaml:"profile_pids,omitempty"`
+	ProfileError    string                `json:"profile_error,omitempty" yaml:"profile_error,omitempty"` // 火焰图生成失败的原因
+	Backend         string                `json:"backend" yaml:"backend"`
+	Series          *PerfSnapSeries       `json:"series,omitempty" yaml:"series,omitempty"`
 	Version         string                `json:"perfsnap_version" yaml:"perfsnap_version"`
+	ParserVersion   int                   `json:"parser_version" yaml:"parser_version"`
+	ParseWarnings   []string              `json:"parse_warnings,omitempty" yaml:"parse_warnings,omitempty"`
 }
 
 type PerfSnapLoadAvg struct {
@@ -115,6 +151,8 @@ func NewPerfSnapCollector() *PerfSnapCollector {
 	return &PerfSnapCollector{
 		duration:      5, // 默认采集5秒
 		generateFlame: false,
+		executor:      NewLocalExecutor(DefaultExecOptions()),
+		backend:       PerfSnapBackendNative,
 	}
 }
 
@@ -123,40 +161,69 @@ func NewPerfSnapCollectorWithOptions(duration int, generateFlame bool) *PerfSnap
 	return &PerfSnapCollector{
 		duration:      duration,
 		generateFlame: generateFlame,
+		executor:      NewLocalExecutor(DefaultExecOptions()),
+		backend:       PerfSnapBackendNative,
 	}
 }
 
+// SetExecutor 设置命令执行器，用于在远程节点上采集
+func (c *PerfSnapCollector) SetExecutor(executor Executor) {
+	c.executor = executor
+}
+
+// run 通过执行器执行命令，返回标准输出
+func (c *PerfSnapCollector) run(command string, args ...string) (string, error) {
+	return c.executor.Run(context.Background(), command, args...)
+}
+
 // Collect 执行 PerfSnap 数据收集
 func (c *PerfSnapCollector) Collect() (*PerfSnapData, error) {
 	data := &PerfSnapData{
-		Version:   "1.1.1",
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Version:       "1.1.1",
+		Timestamp:     time.Now().Format("2006-01-02 15:04:05"),
+		Backend:       c.backend,
+		ParserVersion: parser.Version,
 	}
+	c.parseWarnings = nil
 
 	// 收集各项性能数据
 	data.Hostname = c.getHostname()
 	data.Uptime = c.getUptime()
 	data.LoadAverage = c.getLoadAverage()
-	data.VMStat = c.getVMStat()
-	data.CPUStats = c.getCPUStats()
-	data.ProcessStats = c.getProcessStats()
-	data.DiskIOStats = c.getDiskIOStats()
-	data.MemoryStats = c.getMemoryStats()
-	data.NetworkStats = c.getNetworkStats()
+
+	if c.backend == PerfSnapBackendSysstat {
+		// sysstat 工具只能给出单个时间窗口的统计，时间序列始终采样 /proc
+		if c.interval > 0 {
+			series, _, _, err := c.collectSeries()
+			if err != nil {
+				return nil, fmt.Errorf("perfsnap series sampling failed: %w", err)
+			}
+			data.Series = series
+		}
+		data.VMStat = c.getVMStat()
+		data.CPUStats = c.getCPUStats()
+		data.ProcessStats = c.getProcessStats()
+		data.DiskIOStats = c.getDiskIOStats()
+		data.MemoryStats = c.getMemoryStats()
+		data.NetworkStats = c.getNetworkStats()
+	} else if err := c.collectNative(data); err != nil {
+		return nil, fmt.Errorf("native perfsnap sampling failed: %w", err)
+	}
+
 	data.TCPStats = c.getTCPStats()
 	data.TopProcessesCPU = c.getTopProcessesByCPU()
 	data.TopProcessesMem = c.getTopProcessesByMem()
 	data.DmesgErrors = c.getDmesgErrors()
+	data.ParseWarnings = []string(c.parseWarnings)
 
 	// 分析性能问题
 	data.Issues = c.analyzeIssues(data)
 	data.Recommendations = c.generateRecommendations(data.Issues)
 
-	// 生成火焰图（如果启用）
+	// 生成火焰图（如果启用）；失败不影响其他数据，原因记录在 ProfileError 中
 	if c.generateFlame {
-		flamePath, err := c.generateFlameGraph()
-		if err == nil {
-			data.FlameGraphPath = flamePath
+		if err := c.generateProfiles(data); err != nil {
+			data.ProfileError = err.Error()
 		}
 	}
 
@@ -165,26 +232,26 @@ func (c *PerfSnapCollector) Collect() (*PerfSnapData, error) {
 
 // getHostname 获取主机名
 func (c *PerfSnapCollector) getHostname() string {
-	output, err := exec.Command("hostname").Output()
+	output, err := c.run("hostname")
 	if err != nil {
 		return "unknown"
 	}
-	return strings.TrimSpace(string(output))
+	return strings.TrimSpace(output)
 }
 
 // getUptime 获取系统运行时间
 func (c *PerfSnapCollector) getUptime() string {
-	output, err := exec.Command("uptime", "-p").Output()
+	output, err := c.run("uptime", "-p")
 	if err != nil {
 		return "N/A"
 	}
-	return strings.TrimSpace(string(output))
+	return strings.TrimSpace(output)
 }
 
 // getLoadAverage 获取负载平均值
 func (c *PerfSnapCollector) getLoadAverage() PerfSnapLoadAvg {
 	loadAvg := PerfSnapLoadAvg{}
-	output, err := exec.Command("sh", "-c", "cat /proc/loadavg | awk '{print $1,$2,$3}'").Output()
+	output, err := c.executor.ReadFile(context.Background(), "/proc/loadavg")
 	if err != nil {
 		return loadAvg
 	}
@@ -203,24 +270,23 @@ func (c *PerfSnapCollector) getLoadAverage() PerfSnapLoadAvg {
 func (c *PerfSnapCollector) getVMStat() PerfSnapVMStat {
 	vmstat := PerfSnapVMStat{}
 
-	// 使用 vmstat 命令获取数据
-	output, err := exec.Command("vmstat", "1", "2").Output()
+	// 使用 vmstat 命令获取数据，第一次采样是开机以来的平均值，取最后一次
+	output, err := c.run("vmstat", "1", "2")
 	if err != nil {
 		return vmstat
 	}
-
-	lines := strings.Split(string(output), "\n")
-	if len(lines) >= 4 {
-		// 取最后一行的数据
-		fields := strings.Fields(lines[3])
-		if len(fields) >= 11 {
-			fmt.Sscanf(fields[0], "%d", &vmstat.RunQueue)
-			fmt.Sscanf(fields[1], "%d", &vmstat.BlockedProcesses)
-			fmt.Sscanf(fields[10], "%d", &vmstat.ContextSwitches)
-			fmt.Sscanf(fields[11], "%d", &vmstat.Interrupts)
-		}
+	result, err := parser.ParseVMStat(output)
+	if err != nil {
+		c.parseWarnings.add("vmstat", err.Error())
+		return vmstat
 	}
+	c.parseWarnings.add("vmstat", result.Warnings...)
 
+	last := result.Last()
+	vmstat.RunQueue = last.RunQueue
+	vmstat.BlockedProcesses = last.Blocked
+	vmstat.ContextSwitches = last.ContextSwitches
+	vmstat.Interrupts = last.Interrupts
 	return vmstat
 }
 
@@ -229,27 +295,28 @@ func (c *PerfSnapCollector) getCPUStats() []PerfSnapCPUStat {
 	var stats []PerfSnapCPUStat
 
 	// 使用 mpstat 命令
-	output, err := exec.Command("mpstat", "-P", "ALL", "1", "1").Output()
+	output, err := c.run("mpstat", "-P", "ALL", "1", "1")
+	if err != nil {
+		return stats
+	}
+	result, err := parser.ParseMPStat(output)
 	if err != nil {
+		c.parseWarnings.add("mpstat", err.Error())
 		return stats
 	}
+	c.parseWarnings.add("mpstat", result.Warnings...)
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "all") || strings.Contains(line, "CPU") {
+	for _, cpu := range result.CPUs {
+		if cpu.CPU == "all" {
 			continue
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 12 {
-			stat := PerfSnapCPUStat{}
-			stat.CPU = fields[2]
-			fmt.Sscanf(fields[3], "%f", &stat.User)
-			fmt.Sscanf(fields[5], "%f", &stat.System)
-			fmt.Sscanf(fields[6], "%f", &stat.IOWait)
-			fmt.Sscanf(fields[12], "%f", &stat.Idle)
-			stats = append(stats, stat)
-		}
+		stats = append(stats, PerfSnapCPUStat{
+			CPU:    cpu.CPU,
+			User:   cpu.User,
+			System: cpu.System,
+			IOWait: cpu.IOWait,
+			Idle:   cpu.Idle,
+		})
 	}
 
 	return stats
@@ -260,29 +327,31 @@ func (c *PerfSnapCollector) getProcessStats() []PerfSnapProcessStat {
 	var stats []PerfSnapProcessStat
 
 	// 使用 pidstat 命令
-	output, err := exec.Command("pidstat", "1", "1").Output()
+	output, err := c.run("pidstat", "1", "1")
 	if err != nil {
 		return stats
 	}
+	result, err := parser.ParsePidstat(output)
+	if err != nil {
+		c.parseWarnings.add("pidstat", err.Error())
+		return stats
+	}
+	c.parseWarnings.add("pidstat", result.Warnings...)
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "PID") || strings.Contains(line, "Average") || line == "" {
+	for _, p := range result.Processes {
+		if p.CPUPct <= 1.0 { // 只记录CPU使用率>1%的进程
 			continue
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 8 {
-			stat := PerfSnapProcessStat{}
-			fmt.Sscanf(fields[2], "%d", &stat.PID)
-			stat.User = fields[4]
-			fmt.Sscanf(fields[6], "%f", &stat.CPUPct)
-			stat.Command = fields[7]
-
-			if stat.CPUPct > 1.0 { // 只记录CPU使用率>1%的进程
-				stats = append(stats, stat)
-			}
+		user := p.User
+		if user == "" {
+			user = p.UID
 		}
+		stats = append(stats, PerfSnapProcessStat{
+			PID:     p.PID,
+			User:    user,
+			Command: p.Command,
+			CPUPct:  p.CPUPct,
+		})
 	}
 
 	return stats
@@ -292,34 +361,27 @@ func (c *PerfSnapCollector) getProcessStats() []PerfSnapProcessStat {
 func (c *PerfSnapCollector) getDiskIOStats() []PerfSnapDiskIOStat {
 	var stats []PerfSnapDiskIOStat
 
-	// 使用 iostat 命令
-	output, err := exec.Command("iostat", "-dx", "1", "2").Output()
+	// 使用 iostat 命令，第一次报告是开机以来的平均值，只取最后一次
+	output, err := c.run("iostat", "-dx", "1", "2")
 	if err != nil {
 		return stats
 	}
+	result, err := parser.ParseIOStat(output)
+	if err != nil {
+		c.parseWarnings.add("iostat", err.Error())
+		return stats
+	}
+	c.parseWarnings.add("iostat", result.Warnings...)
 
-	lines := strings.Split(string(output), "\n")
-	deviceSection := false
-
-	for _, line := range lines {
-		if strings.Contains(line, "Device") {
-			deviceSection = true
-			continue
-		}
-
-		if deviceSection && line != "" {
-			fields := strings.Fields(line)
-			if len(fields) >= 14 {
-				stat := PerfSnapDiskIOStat{}
-				stat.Device = fields[0]
-				fmt.Sscanf(fields[3], "%f", &stat.TPS)
-				fmt.Sscanf(fields[4], "%f", &stat.ReadKBps)
-				fmt.Sscanf(fields[5], "%f", &stat.WriteKBps)
-				fmt.Sscanf(fields[9], "%f", &stat.AvgWait)
-				fmt.Sscanf(fields[13], "%f", &stat.Util)
-				stats = append(stats, stat)
-			}
-		}
+	for _, d := range result.Devices {
+		stats = append(stats, PerfSnapDiskIOStat{
+			Device:    d.Device,
+			TPS:       d.TPS,
+			ReadKBps:  d.ReadKBps,
+			WriteKBps: d.WriteKBps,
+			AvgWait:   d.Await,
+			Util:      d.Util,
+		})
 	}
 
 	return stats
@@ -330,25 +392,26 @@ func (c *PerfSnapCollector) getMemoryStats() PerfSnapMemoryStat {
 	memStat := PerfSnapMemoryStat{}
 
 	// 使用 free 命令
-	output, err := exec.Command("free", "-m").Output()
+	output, err := c.run("free", "-m")
 	if err != nil {
 		return memStat
 	}
+	result, err := parser.ParseFree(output, 1<<20)
+	if err != nil {
+		c.parseWarnings.add("free", err.Error())
+		return memStat
+	}
+	c.parseWarnings.add("free", result.Warnings...)
 
-	lines := strings.Split(string(output), "\n")
-	if len(lines) >= 2 {
-		fields := strings.Fields(lines[1])
-		if len(fields) >= 7 {
-			fmt.Sscanf(fields[1], "%d", &memStat.TotalMB)
-			fmt.Sscanf(fields[2], "%d", &memStat.UsedMB)
-			fmt.Sscanf(fields[3], "%d", &memStat.FreeMB)
-			fmt.Sscanf(fields[5], "%d", &memStat.BufferMB)
-			fmt.Sscanf(fields[6], "%d", &memStat.CacheMB)
-
-			if memStat.TotalMB > 0 {
-				memStat.UsedPercent = float64(memStat.UsedMB) / float64(memStat.TotalMB) * 100
-			}
-		}
+	mem := result.Mem
+	memStat.TotalMB = int(mem.Total >> 20)
+	memStat.UsedMB = int(mem.Used >> 20)
+	memStat.FreeMB = int(mem.Free >> 20)
+	memStat.BufferMB = int(mem.Buffers >> 20)
+	// 新版本 free 只输出 buff/cache 合计列
+	memStat.CacheMB = int((mem.BuffCache - mem.Buffers) >> 20)
+	if memStat.TotalMB > 0 {
+		memStat.UsedPercent = float64(memStat.UsedMB) / float64(memStat.TotalMB) * 100
 	}
 
 	return memStat
@@ -359,30 +422,28 @@ func (c *PerfSnapCollector) getNetworkStats() []PerfSnapNetworkStat {
 	var stats []PerfSnapNetworkStat
 
 	// 使用 sar 命令
-	output, err := exec.Command("sar", "-n", "DEV", "1", "1").Output()
+	output, err := c.run("sar", "-n", "DEV", "1", "1")
+	if err != nil {
+		return stats
+	}
+	result, err := parser.ParseSarNetDev(output)
 	if err != nil {
+		c.parseWarnings.add("sar", err.Error())
 		return stats
 	}
+	c.parseWarnings.add("sar", result.Warnings...)
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "IFACE") || strings.Contains(line, "Average") || line == "" {
+	for _, iface := range result.Interfaces {
+		if iface.Interface == "lo" { // 排除本地回环
 			continue
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 9 {
-			iface := fields[1]
-			if iface != "lo" { // 排除本地回环
-				stat := PerfSnapNetworkStat{}
-				stat.Interface = iface
-				fmt.Sscanf(fields[4], "%f", &stat.RxKBps)
-				fmt.Sscanf(fields[5], "%f", &stat.TxKBps)
-				fmt.Sscanf(fields[2], "%f", &stat.RxPckps)
-				fmt.Sscanf(fields[3], "%f", &stat.TxPckps)
-				stats = append(stats, stat)
-			}
-		}
+		stats = append(stats, PerfSnapNetworkStat{
+			Interface: iface.Interface,
+			RxKBps:    iface.RxKB,
+			TxKBps:    iface.TxKB,
+			RxPckps:   iface.RxPackets,
+			TxPckps:   iface.TxPackets,
+		})
 	}
 
 	return stats
@@ -393,29 +454,28 @@ func (c *PerfSnapCollector) getTCPStats() PerfSnapTCPStat {
 	tcpStat := PerfSnapTCPStat{}
 
 	// 使用 ss 命令统计连接状态
-	output, err := exec.Command("ss", "-s").Output()
+	output, err := c.run("ss", "-s")
 	if err != nil {
 		return tcpStat
 	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "estab") {
-			fmt.Sscanf(line, "TCP: %d (estab %d", &tcpStat.Established, &tcpStat.Established)
-		}
+	summary, err := parser.ParseSSSummary(output)
+	if err != nil {
+		c.parseWarnings.add("ss", err.Error())
+		return tcpStat
 	}
+	c.parseWarnings.add("ss", summary.Warnings...)
+	tcpStat.Established = summary.Established
 
 	// 统计各种状态的连接
-	output2, err := exec.Command("ss", "-tan").Output()
-	if err == nil {
-		lines := strings.Split(string(output2), "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "TIME-WAIT") {
-				tcpStat.TimeWait++
-			} else if strings.Contains(line, "CLOSE-WAIT") {
-				tcpStat.CloseWait++
-			}
+	if output, err := c.run("ss", "-tan"); err == nil {
+		states, err := parser.ParseSSStates(output)
+		if err != nil {
+			c.parseWarnings.add("ss", err.Error())
+			return tcpStat
 		}
+		c.parseWarnings.add("ss", states.Warnings...)
+		tcpStat.TimeWait = states.Counts["TIME-WAIT"]
+		tcpStat.CloseWait = states.Counts["CLOSE-WAIT"]
 	}
 
 	return tcpStat
@@ -425,12 +485,12 @@ func (c *PerfSnapCollector) getTCPStats() PerfSnapTCPStat {
 func (c *PerfSnapCollector) getTopProcessesByCPU() []PerfSnapTopProcess {
 	var processes []PerfSnapTopProcess
 
-	output, err := exec.Command("ps", "aux", "--sort=-%cpu").Output()
+	output, err := c.run("ps", "aux", "--sort=-%cpu")
 	if err != nil {
 		return processes
 	}
 
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(output, "\n")
 	count := 0
 	for _, line := range lines[1:] { // 跳过标题行
 		if count >= 10 { // 只取前10个
@@ -461,12 +521,12 @@ func (c *PerfSnapCollector) getTopProcessesByCPU() []PerfSnapTopProcess {
 func (c *PerfSnapCollector) getTopProcessesByMem() []PerfSnapTopProcess {
 	var processes []PerfSnapTopProcess
 
-	output, err := exec.Command("ps", "aux", "--sort=-%mem").Output()
+	output, err := c.run("ps", "aux", "--sort=-%mem")
 	if err != nil {
 		return processes
 	}
 
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(output, "\n")
 	count := 0
 	for _, line := range lines[1:] { // 跳过标题行
 		if count >= 10 { // 只取前10个
@@ -497,12 +557,12 @@ func (c *PerfSnapCollector) getTopProcessesByMem() []PerfSnapTopProcess {
 func (c *PerfSnapCollector) getDmesgErrors() []string {
 	var errors []string
 
-	output, err := exec.Command("dmesg", "-T", "-l", "err,crit,alert,emerg").Output()
+	output, err := c.run("dmesg", "-T", "-l", "err,crit,alert,emerg")
 	if err != nil {
 		return errors
 	}
 
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		if line != "" && len(errors) < 20 { // 最多保留20条错误
 			errors = append(errors, line)
@@ -587,18 +647,142 @@ func (c *PerfSnapCollector) generateRecommendations(issues []PerfSnapIssue) []st
 	return recommendations
 }
 
-// generateFlameGraph 生成火焰图
-func (c *PerfSnapCollector) generateFlameGraph() (string, error) {
+// SetProfileOptions 设置 perf 采样结果的输出选项
+func (c *PerfSnapCollector) SetProfileOptions(opts ProfileOptions) {
+	c.profile = opts
+}
+
+// profilePIDs 返回需要保留采样的进程，为空表示不过滤
+func (c *PerfSnapCollector) profilePIDs(data *PerfSnapData) []int {
+	pids := append([]int(nil), c.profile.PIDs...)
+	if c.profile.TopProcesses {
+		for _, p := range data.TopProcessesCPU {
+			pids = append(pids, p.PID)
+		}
+	}
+	return pids
+}
+
+// generateProfiles 采集调用栈并生成火焰图、pprof 和折叠栈文件
+// perf record/script 在目标节点上执行，调用栈的折叠、过滤和渲染在本地完成，不依赖 FlameGraph 脚本
+func (c *PerfSnapCollector) generateProfiles(data *PerfSnapData) error {
+	const frequency = 99
+
 	timestamp := time.Now().Format("20060102_150405")
-	outputPath := fmt.Sprintf("/tmp/flamegraph_%s.svg", timestamp)
+	outputDir := c.profile.OutputDir
+	if outputDir == "" {
+		outputDir = os.TempDir()
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
 
-	// 采集性能数据
-	cmd := exec.Command("perf", "record", "-F", "99", "-a", "-g", "--", "sleep", fmt.Sprintf("%d", c.duration))
-	if err := cmd.Run(); err != nil {
-		return "", err
+	// perf 以 root 运行，数据文件由 mktemp 在节点上新建，避免 /tmp 下可预测的路径被符号链接劫持，
+	// 也避免同一节点上的并发运行互相覆盖或删除数据
+	output, err := c.executor.Run(context.Background(), "mktemp", "/tmp/clusterreport_perf.XXXXXX")
+	if err != nil {
+		return fmt.Errorf("failed to create perf data file: %w", err)
+	}
+	perfDataPath := strings.TrimSpace(output)
+	if perfDataPath == "" {
+		return fmt.Errorf("failed to create perf data file: mktemp returned no path")
+	}
+	defer c.executor.Run(context.Background(), "rm", "-f", perfDataPath)
+
+	// 采集性能数据，perf record 的耗时取决于采集时长，需要放宽单条命令超时
+	start := clockOf(c.executor).Now()
+	ctx := WithCommandTimeout(context.Background(), time.Duration(c.duration)*time.Second+DefaultCommandTimeout)
+	if _, err := c.executor.Run(ctx, "perf", "record", "-F", fmt.Sprintf("%d", frequency), "-a", "-g", "-o", perfDataPath, "--", "sleep", fmt.Sprintf("%d", c.duration)); err != nil {
+		return fmt.Errorf("perf record failed: %w", err)
+	}
+
+	// 调用栈输出远大于普通命令，不在内存中缓冲，直接写入输出目录的临时文件后逐行解析
+	samples, err := c.perfScript(perfDataPath, outputDir)
+	if err != nil {
+		return err
 	}
 
-	// 生成火焰图（需要FlameGraph工具）
-	// 这里简化实现，实际需要安装FlameGraph工具
-	return outputPath, nil
+	// 与旧版一样可以只分析 CPU 占用最高的进程；采集仍是全系统的，过滤在本地完成
+	pids := c.profilePIDs(data)
+	samples = FilterSamples(samples, pids)
+	if len(samples) == 0 {
+		return fmt.Errorf("no stack samples recorded for pids %v", pids)
+	}
+	data.ProfilePIDs = pids
+
+	folded := FoldStacks(samples)
+	foldedPath := filepath.Join(outputDir, fmt.Sprintf("stacks_%s.folded", timestamp))
+	if err := writeProfileFile(foldedPath, func(w io.Writer) error {
+		_, err := folded.WriteTo(w)
+		return err
+	}); err != nil {
+		return err
+	}
+	data.FoldedPath = foldedPath
+
+	pprofPath := filepath.Join(outputDir, fmt.Sprintf("profile_%s.pb.gz", timestamp))
+	if err := writeProfileFile(pprofPath, func(w io.Writer) error {
+		return WritePprof(w, samples, PprofOptions{
+			Frequency: frequency,
+			Start:     start,
+			Duration:  time.Duration(c.duration) * time.Second,
+		})
+	}); err != nil {
+		return err
+	}
+	data.PprofPath = pprofPath
+
+	title := fmt.Sprintf("CPU Flame Graph: %s (%ds)", data.Hostname, c.duration)
+	if len(pids) > 0 {
+		title += fmt.Sprintf(" PID %v", pids)
+	}
+	svg, err := RenderFlameGraph(folded, FlameGraphOptions{Title: title})
+	if err != nil {
+		return err
+	}
+	flamePath := filepath.Join(outputDir, fmt.Sprintf("flamegraph_%s.svg", timestamp))
+	if err := os.WriteFile(flamePath, svg, 0644); err != nil {
+		return fmt.Errorf("failed to write flame graph: %w", err)
+	}
+	data.FlameGraphPath = flamePath
+
+	return nil
+}
+
+// perfScript 运行 perf script，输出写入 dir 中的临时文件，解析完成后删除
+// 符号解析可能较慢，使用 perfScriptTimeout；截断的调用栈会让火焰图失真，超出上限时直接失败
+func (c *PerfSnapCollector) perfScript(perfDataPath, dir string) ([]StackSample, error) {
+	f, err := os.CreateTemp(dir, "perf_script_*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create perf script output file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	ctx := WithMaxOutputBytes(WithCommandTimeout(context.Background(), perfScriptTimeout(c.duration)), PerfScriptMaxOutputBytes)
+	err = runStream(ctx, c.executor, f, "perf", "script", "-i", perfDataPath)
+	if errors.Is(err, ErrOutputTruncated) {
+		return nil, fmt.Errorf("perf script output exceeds %d bytes, reduce the profiling duration or profile fewer processes: %w", PerfScriptMaxOutputBytes, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("perf script failed: %w", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read perf script output: %w", err)
+	}
+	return ReadPerfScript(f)
+}
+
+// writeProfileFile 创建文件并写入内容
+func writeProfileFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
 }
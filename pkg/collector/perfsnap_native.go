@@ -0,0 +1,347 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PerfSnap 采集后端
+const (
+	PerfSnapBackendNative  = "native"  // 直接采样 /proc，不依赖外部工具
+	PerfSnapBackendSysstat = "sysstat" // 使用 vmstat、mpstat、pidstat、iostat、sar
+)
+
+// userHZ /proc 中 CPU 时间的单位，Linux 上固定为 100
+const userHZ = 100
+
+// perfSnapSample 原生后端的一次 /proc 采样
+type perfSnapSample struct {
+	at       time.Time
+	cpuAll   cpuTimes
+	cpuCores []cpuTimes
+	counters procStatCounters
+	disks    map[string]DiskIOMetrics
+	networks map[string]NetworkMetrics
+	procs    map[int]procCPUTimes
+}
+
+// validatePerfSnapBackend 校验采集后端名称
+func validatePerfSnapBackend(backend string) error {
+	switch backend {
+	case PerfSnapBackendNative, PerfSnapBackendSysstat:
+		return nil
+	default:
+		return fmt.Errorf("unknown perfsnap backend: %s (available: %s, %s)", backend, PerfSnapBackendNative, PerfSnapBackendSysstat)
+	}
+}
+
+// SetBackend 设置采集后端
+func (c *PerfSnapCollector) SetBackend(backend string) error {
+	if err := validatePerfSnapBackend(backend); err != nil {
+		return err
+	}
+	c.backend = backend
+	return nil
+}
+
+// collectNative 在 duration 时间窗口内采样 /proc，计算各项性能统计
+func (c *PerfSnapCollector) collectNative(data *PerfSnapData) error {
+	var first, second *perfSnapSample
+	var err error
+
+	if c.interval > 0 {
+		// 时间序列模式下，快照统计覆盖整个序列的时间窗口
+		data.Series, first, second, err = c.collectSeries()
+		if err != nil {
+			return err
+		}
+	} else {
+		if first, err = c.sampleProc(); err != nil {
+			return err
+		}
+
+		clockOf(c.executor).Sleep(time.Duration(c.duration) * time.Second)
+
+		if second, err = c.sampleProc(); err != nil {
+			return err
+		}
+	}
+
+	seconds := second.at.Sub(first.at).Seconds()
+	if seconds <= 0 {
+		return fmt.Errorf("invalid sampling window")
+	}
+
+	data.VMStat = nativeVMStat(first, second, seconds)
+	data.CPUStats = nativeCPUStats(first, second)
+	data.DiskIOStats = c.nativeDiskIOStats(first, second, seconds)
+	data.NetworkStats = nativeNetworkStats(first, second, seconds)
+	data.ProcessStats = c.nativeProcessStats(first, second, seconds)
+	data.MemoryStats = c.nativeMemoryStats()
+
+	return nil
+}
+
+// sampleProc 读取一次 /proc 中的累计计数器
+func (c *PerfSnapCollector) sampleProc() (*perfSnapSample, error) {
+	ctx := context.Background()
+
+	stat, err := c.executor.ReadFile(ctx, "/proc/stat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc/stat: %w", err)
+	}
+
+	sample := &perfSnapSample{
+		at:       clockOf(c.executor).Now(),
+		counters: parseProcStatCounters(string(stat)),
+		disks:    make(map[string]DiskIOMetrics),
+		networks: make(map[string]NetworkMetrics),
+		procs:    make(map[int]procCPUTimes),
+	}
+	sample.cpuAll, sample.cpuCores, err = parseProcStat(string(stat))
+	if err != nil {
+		return nil, err
+	}
+
+	if diskstats, err := c.executor.ReadFile(ctx, "/proc/diskstats"); err == nil {
+		for _, d := range parseDiskstats(string(diskstats)) {
+			sample.disks[d.Device] = d
+		}
+	}
+	if netdev, err := c.executor.ReadFile(ctx, "/proc/net/dev"); err == nil {
+		for _, n := range parseNetDev(string(netdev)) {
+			sample.networks[n.Interface] = n
+		}
+	}
+	for _, p := range c.readProcessTimes() {
+		sample.procs[p.PID] = p
+	}
+
+	return sample, nil
+}
+
+// readProcessTimes 读取所有进程的 CPU 时间
+// 本地直接读文件；远程逐个读取代价过高，用一条 cat 命令批量获取
+func (c *PerfSnapCollector) readProcessTimes() []procCPUTimes {
+	ctx := context.Background()
+	var contents []string
+
+	if isLocal(c.executor) {
+		files, err := c.executor.Glob(ctx, "/proc/[0-9]*/stat")
+		if err != nil {
+			return nil
+		}
+		for _, file := range files {
+			if data, err := c.executor.ReadFile(ctx, file); err == nil {
+				contents = append(contents, string(data))
+			}
+		}
+	} else {
+		output, err := c.executor.Run(ctx, "sh", "-c", "cat /proc/[0-9]*/stat 2>/dev/null")
+		if err != nil && output == "" {
+			return nil
+		}
+		contents = strings.Split(output, "\n")
+	}
+
+	var procs []procCPUTimes
+	for _, content := range contents {
+		if p, err := parseProcPIDStat(content); err == nil {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
+
+// nativeVMStat 对应 vmstat 的 r、b、cs、in 列
+func nativeVMStat(first, second *perfSnapSample, seconds float64) PerfSnapVMStat {
+	return PerfSnapVMStat{
+		RunQueue:         second.counters.ProcsRunning,
+		BlockedProcesses: second.counters.ProcsBlocked,
+		ContextSwitches:  int(counterDelta(first.counters.ContextSwitches, second.counters.ContextSwitches) / seconds),
+		Interrupts:       int(counterDelta(first.counters.Interrupts, second.counters.Interrupts) / seconds),
+	}
+}
+
+// nativeCPUStats 对应 mpstat -P ALL 的各核心数据
+func nativeCPUStats(first, second *perfSnapSample) []PerfSnapCPUStat {
+	prev := make(map[string]cpuTimes, len(first.cpuCores))
+	for _, t := range first.cpuCores {
+		prev[t.CPU] = t
+	}
+
+	var stats []PerfSnapCPUStat
+	for _, t := range second.cpuCores {
+		p, ok := prev[t.CPU]
+		if !ok {
+			continue
+		}
+		usage := cpuUsageBetween(p, t)
+		stats = append(stats, PerfSnapCPUStat{
+			CPU:    strings.TrimPrefix(t.CPU, "cpu"),
+			User:   usage.User + usage.Nice,
+			System: usage.System,
+			IOWait: usage.IOWait,
+			Idle:   usage.Idle,
+		})
+	}
+	return stats
+}
+
+// nativeDiskIOStats 对应 iostat -dx，仅统计整块磁盘
+func (c *PerfSnapCollector) nativeDiskIOStats(first, second *perfSnapSample, seconds float64) []PerfSnapDiskIOStat {
+	// /sys/block 下只有整块磁盘，用于排除分区；无法读取时保留全部设备
+	wholeDisks := make(map[string]bool)
+	if entries, err := c.executor.Glob(context.Background(), "/sys/block/*/stat"); err == nil {
+		for _, entry := range entries {
+			wholeDisks[path.Base(path.Dir(entry))] = true
+		}
+	}
+
+	names := make([]string, 0, len(second.disks))
+	for name := range second.disks {
+		if len(wholeDisks) == 0 || wholeDisks[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var stats []PerfSnapDiskIOStat
+	for _, name := range names {
+		prev, ok := first.disks[name]
+		if !ok {
+			continue
+		}
+		rates := diskIORates(prev, second.disks[name], seconds)
+		stats = append(stats, PerfSnapDiskIOStat{
+			Device:    name,
+			TPS:       rates.ReadIOPS + rates.WriteIOPS,
+			ReadKBps:  rates.ReadBytesPerSec / 1024,
+			WriteKBps: rates.WriteBytesPerSec / 1024,
+			AvgWait:   rates.AwaitMs,
+			Util:      rates.UtilPercent,
+		})
+	}
+	return stats
+}
+
+// nativeNetworkStats 对应 sar -n DEV，排除本地回环
+func nativeNetworkStats(first, second *perfSnapSample, seconds float64) []PerfSnapNetworkStat {
+	names := make([]string, 0, len(second.networks))
+	for name := range second.networks {
+		if name != "lo" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var stats []PerfSnapNetworkStat
+	for _, name := range names {
+		prev, ok := first.networks[name]
+		if !ok {
+			continue
+		}
+		rates := networkRates(prev, second.networks[name], seconds)
+		stats = append(stats, PerfSnapNetworkStat{
+			Interface: name,
+			RxKBps:    rates.RxBytesPerSec / 1024,
+			TxKBps:    rates.TxBytesPerSec / 1024,
+			RxPckps:   rates.RxPacketsPerSec,
+			TxPckps:   rates.TxPacketsPerSec,
+		})
+	}
+	return stats
+}
+
+// nativeProcessStats 对应 pidstat，只保留 CPU 使用率 >1% 的进程
+func (c *PerfSnapCollector) nativeProcessStats(first, second *perfSnapSample, seconds float64) []PerfSnapProcessStat {
+	var stats []PerfSnapProcessStat
+	for pid, cur := range second.procs {
+		prev, ok := first.procs[pid]
+		if !ok {
+			continue
+		}
+
+		cpuPct := counterDelta(prev.Ticks, cur.Ticks) / userHZ / seconds * 100
+		if cpuPct > 1.0 {
+			stats = append(stats, PerfSnapProcessStat{
+				PID:     pid,
+				Command: cur.Comm,
+				CPUPct:  cpuPct,
+			})
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].CPUPct > stats[j].CPUPct
+	})
+
+	// 只为入选的进程解析用户名
+	if len(stats) > 0 {
+		users := c.readUsers()
+		for i := range stats {
+			stats[i].User = c.processUser(stats[i].PID, users)
+		}
+	}
+
+	return stats
+}
+
+// readUsers 读取目标节点的 UID 到用户名映射
+func (c *PerfSnapCollector) readUsers() map[string]string {
+	data, err := c.executor.ReadFile(context.Background(), "/etc/passwd")
+	if err != nil {
+		return nil
+	}
+	return parsePasswd(string(data))
+}
+
+// processUser 返回进程的用户名，无法解析时返回 UID
+func (c *PerfSnapCollector) processUser(pid int, users map[string]string) string {
+	status, err := c.executor.ReadFile(context.Background(), "/proc/"+strconv.Itoa(pid)+"/status")
+	if err != nil {
+		return ""
+	}
+	uid := parseStatusUID(string(status))
+	if name, ok := users[uid]; ok {
+		return name
+	}
+	return uid
+}
+
+// nativeMemoryStats 对应 free -m
+func (c *PerfSnapCollector) nativeMemoryStats() PerfSnapMemoryStat {
+	memStat := PerfSnapMemoryStat{}
+
+	data, err := c.executor.ReadFile(context.Background(), "/proc/meminfo")
+	if err != nil {
+		return memStat
+	}
+
+	const mib = 1024 * 1024
+	values := parseMeminfo(string(data))
+	total := values["MemTotal"]
+	free := values["MemFree"]
+	buffers := values["Buffers"]
+	cache := values["Cached"] + values["SReclaimable"]
+
+	memStat.TotalMB = int(total / mib)
+	memStat.FreeMB = int(free / mib)
+	memStat.BufferMB = int(buffers / mib)
+	memStat.CacheMB = int(cache / mib)
+
+	// 与 free 一致：used = total - free - buffers - cache
+	if used := total - free - buffers - cache; total > free+buffers+cache {
+		memStat.UsedMB = int(used / mib)
+	}
+	if memStat.TotalMB > 0 {
+		memStat.UsedPercent = float64(memStat.UsedMB) / float64(memStat.TotalMB) * 100
+	}
+
+	return memStat
+}
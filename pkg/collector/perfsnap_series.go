@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// PerfSnapSeries 时间序列模式的采样结果
+type PerfSnapSeries struct {
+	Interval float64                          `json:"interval_seconds" yaml:"interval_seconds"`
+	Duration float64                          `json:"duration_seconds" yaml:"duration_seconds"`
+	Points   []PerfSnapSeriesPoint            `json:"points" yaml:"points"`
+	Summary  map[string]PerfSnapSeriesSummary `json:"summary" yaml:"summary"`
+}
+
+// PerfSnapSeriesPoint 一个采样点，指标值为上一采样点到本采样点之间的平均值
+type PerfSnapSeriesPoint struct {
+	Timestamp time.Time          `json:"timestamp" yaml:"timestamp"`
+	Metrics   map[string]float64 `json:"metrics" yaml:"metrics"`
+}
+
+// PerfSnapSeriesSummary 单个指标在整个序列中的统计
+type PerfSnapSeriesSummary struct {
+	Samples int     `json:"samples" yaml:"samples"`
+	Min     float64 `json:"min" yaml:"min"`
+	Avg     float64 `json:"avg" yaml:"avg"`
+	Max     float64 `json:"max" yaml:"max"`
+	P95     float64 `json:"p95" yaml:"p95"`
+}
+
+// validatePerfSnapInterval 校验采样间隔，间隔不能超过采集时长
+func validatePerfSnapInterval(interval time.Duration, duration int) error {
+	if interval < 0 {
+		return fmt.Errorf("perfsnap interval must not be negative: %s", interval)
+	}
+	if interval > time.Duration(duration)*time.Second {
+		return fmt.Errorf("perfsnap interval %s exceeds duration %ds", interval, duration)
+	}
+	return nil
+}
+
+// SetInterval 设置时间序列模式的采样间隔，0 表示只采集单个快照
+func (c *PerfSnapCollector) SetInterval(interval time.Duration) error {
+	if err := validatePerfSnapInterval(interval, c.duration); err != nil {
+		return err
+	}
+	c.interval = interval
+	return nil
+}
+
+// collectSeries 在 duration 内每隔 interval 采样一次 /proc
+// 返回序列以及首尾两次采样，供快照统计复用
+func (c *PerfSnapCollector) collectSeries() (*PerfSnapSeries, *perfSnapSample, *perfSnapSample, error) {
+	first, err := c.sampleProc()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	series := &PerfSnapSeries{Interval: c.interval.Seconds()}
+	end := first.at.Add(time.Duration(c.duration) * time.Second)
+	prev := first
+	clock := clockOf(c.executor)
+
+	for {
+		wait := c.interval
+		if remaining := end.Sub(clock.Now()); remaining < wait {
+			wait = remaining
+		}
+		if wait <= 0 {
+			break
+		}
+		clock.Sleep(wait)
+
+		cur, err := c.sampleProc()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		series.Points = append(series.Points, c.seriesPoint(prev, cur))
+		prev = cur
+	}
+
+	series.Duration = prev.at.Sub(first.at).Seconds()
+	series.Summary = summarizeSeries(series.Points)
+	return series, first, prev, nil
+}
+
+// seriesPoint 由相邻两次采样计算一个采样点
+func (c *PerfSnapCollector) seriesPoint(prev, cur *perfSnapSample) PerfSnapSeriesPoint {
+	point := PerfSnapSeriesPoint{
+		Timestamp: cur.at,
+		Metrics:   make(map[string]float64),
+	}
+	seconds := cur.at.Sub(prev.at).Seconds()
+	if seconds <= 0 {
+		return point
+	}
+
+	load := c.getLoadAverage()
+	point.Metrics["load.1m"] = load.OneMin
+
+	cpu := cpuUsageBetween(prev.cpuAll, cur.cpuAll)
+	point.Metrics["cpu.usage"] = cpu.Usage
+	point.Metrics["cpu.user"] = cpu.User + cpu.Nice
+	point.Metrics["cpu.system"] = cpu.System
+	point.Metrics["cpu.iowait"] = cpu.IOWait
+
+	if mem := c.nativeMemoryStats(); mem.TotalMB > 0 {
+		point.Metrics["memory.used_percent"] = mem.UsedPercent
+	}
+
+	vm := nativeVMStat(prev, cur, seconds)
+	point.Metrics["vmstat.run_queue"] = float64(vm.RunQueue)
+	point.Metrics["vmstat.blocked"] = float64(vm.BlockedProcesses)
+	point.Metrics["vmstat.context_switches"] = float64(vm.ContextSwitches)
+	point.Metrics["vmstat.interrupts"] = float64(vm.Interrupts)
+
+	for _, d := range c.nativeDiskIOStats(prev, cur, seconds) {
+		point.Metrics["disk."+d.Device+".tps"] = d.TPS
+		point.Metrics["disk."+d.Device+".read_kbps"] = d.ReadKBps
+		point.Metrics["disk."+d.Device+".write_kbps"] = d.WriteKBps
+		point.Metrics["disk."+d.Device+".await_ms"] = d.AvgWait
+		point.Metrics["disk."+d.Device+".util_pct"] = d.Util
+	}
+
+	for _, n := range nativeNetworkStats(prev, cur, seconds) {
+		point.Metrics["net."+n.Interface+".rx_kbps"] = n.RxKBps
+		point.Metrics["net."+n.Interface+".tx_kbps"] = n.TxKBps
+	}
+
+	return point
+}
+
+// summarizeSeries 计算每个指标的 min/avg/max/p95
+// 设备或网卡在序列中途出现时，只统计其出现后的采样点
+func summarizeSeries(points []PerfSnapSeriesPoint) map[string]PerfSnapSeriesSummary {
+	values := make(map[string][]float64)
+	for _, p := range points {
+		for name, v := range p.Metrics {
+			values[name] = append(values[name], v)
+		}
+	}
+
+	summary := make(map[string]PerfSnapSeriesSummary, len(values))
+	for name, vs := range values {
+		sort.Float64s(vs)
+
+		var sum float64
+		for _, v := range vs {
+			sum += v
+		}
+
+		summary[name] = PerfSnapSeriesSummary{
+			Samples: len(vs),
+			Min:     vs[0],
+			Avg:     sum / float64(len(vs)),
+			Max:     vs[len(vs)-1],
+			P95:     percentile(vs, 95),
+		}
+	}
+	return summary
+}
+
+// percentile 使用最近秩法计算已排序数据的百分位数
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// PprofOptions pprof 导出选项
+type PprofOptions struct {
+	Frequency int           // 采样频率（Hz），用于换算每个采样代表的 CPU 时间
+	Start     time.Time     // 采样开始时间
+	Duration  time.Duration // 采样时长
+}
+
+// FilterSamples 只保留指定进程的采样，pids 为空时原样返回
+func FilterSamples(samples []StackSample, pids []int) []StackSample {
+	if len(pids) == 0 {
+		return samples
+	}
+
+	keep := make(map[int]bool, len(pids))
+	for _, pid := range pids {
+		keep[pid] = true
+	}
+
+	var filtered []StackSample
+	for _, s := range samples {
+		if keep[s.PID] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// WritePprof 将采样写为 gzip 压缩的 pprof profile.proto，可用 go tool pprof 打开
+// 每个采样带 pid（数值）和 comm（字符串）标签，便于在 pprof 中按进程过滤
+func WritePprof(w io.Writer, samples []StackSample, opts PprofOptions) error {
+	period := int64(0)
+	if opts.Frequency > 0 {
+		period = int64(time.Second) / int64(opts.Frequency)
+	}
+
+	p := newPprofBuilder()
+	samplesType := p.valueType("samples", "count")
+	cpuType := p.valueType("cpu", "nanoseconds")
+	pidKey := p.str("pid")
+	commKey := p.str("comm")
+
+	var body []byte
+	body = appendBytesField(body, 1, samplesType)
+	body = appendBytesField(body, 1, cpuType)
+
+	for _, s := range samples {
+		// pprof 要求 location_id 从叶到根排列
+		ids := make([]uint64, 0, len(s.Frames))
+		for i := len(s.Frames) - 1; i >= 0; i-- {
+			ids = append(ids, p.location(s.Frames[i]))
+		}
+
+		var sample []byte
+		sample = appendPackedField(sample, 1, ids)
+		sample = appendPackedField(sample, 2, []uint64{1, uint64(period)})
+		sample = appendBytesField(sample, 3, pprofNumLabel(pidKey, int64(s.PID)))
+		sample = appendBytesField(sample, 3, pprofStrLabel(commKey, p.str(s.Comm)))
+		body = appendBytesField(body, 2, sample)
+	}
+
+	body = append(body, p.locations...)
+	body = append(body, p.functions...)
+	for _, s := range p.strings {
+		body = appendBytesField(body, 6, []byte(s))
+	}
+	if !opts.Start.IsZero() {
+		body = appendVarintField(body, 9, uint64(opts.Start.UnixNano()))
+	}
+	body = appendVarintField(body, 10, uint64(opts.Duration))
+	body = appendBytesField(body, 11, cpuType)
+	body = appendVarintField(body, 12, uint64(period))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(body); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// pprofBuilder 维护字符串表以及按函数名去重的 Function/Location
+type pprofBuilder struct {
+	strings   []string
+	stringIDs map[string]int64
+	locIDs    map[string]uint64
+	locations []byte // 已编码的 Location 字段
+	functions []byte // 已编码的 Function 字段
+}
+
+func newPprofBuilder() *pprofBuilder {
+	// 字符串表第 0 项必须为空字符串
+	return &pprofBuilder{
+		strings:   []string{""},
+		stringIDs: map[string]int64{"": 0},
+		locIDs:    make(map[string]uint64),
+	}
+}
+
+// str 返回字符串在字符串表中的下标
+func (p *pprofBuilder) str(s string) int64 {
+	if id, ok := p.stringIDs[s]; ok {
+		return id
+	}
+	id := int64(len(p.strings))
+	p.strings = append(p.strings, s)
+	p.stringIDs[s] = id
+	return id
+}
+
+// valueType 编码 ValueType 消息
+func (p *pprofBuilder) valueType(typ, unit string) []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(p.str(typ)))
+	b = appendVarintField(b, 2, uint64(p.str(unit)))
+	return b
+}
+
+// location 返回函数对应的 Location ID，perf script 没有保留地址，同名函数共用一个 Location
+func (p *pprofBuilder) location(name string) uint64 {
+	if id, ok := p.locIDs[name]; ok {
+		return id
+	}
+	id := uint64(len(p.locIDs) + 1)
+	p.locIDs[name] = id
+
+	var fn []byte
+	fn = appendVarintField(fn, 1, id)
+	fn = appendVarintField(fn, 2, uint64(p.str(name)))
+	fn = appendVarintField(fn, 3, uint64(p.str(name)))
+	p.functions = appendBytesField(p.functions, 5, fn)
+
+	var line []byte
+	line = appendVarintField(line, 1, id)
+
+	var loc []byte
+	loc = appendVarintField(loc, 1, id)
+	loc = appendBytesField(loc, 4, line)
+	p.locations = appendBytesField(p.locations, 4, loc)
+
+	return id
+}
+
+// pprofStrLabel 编码字符串 Label 消息
+func pprofStrLabel(key, str int64) []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(key))
+	return appendVarintField(b, 2, uint64(str))
+}
+
+// pprofNumLabel 编码数值 Label 消息；pprof 会忽略值为 0 的数值标签，因此 idle 进程没有 pid 标签
+func pprofNumLabel(key, num int64) []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(key))
+	return appendVarintField(b, 3, uint64(num))
+}
+
+// protobuf 线格式编码，仅覆盖 profile.proto 用到的 varint 和 length-delimited 两种类型
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendBytesField(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendPackedField(b []byte, field int, values []uint64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = binary.AppendUvarint(packed, v)
+	}
+	return appendBytesField(b, field, packed)
+}
@@ -0,0 +1,492 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 默认的 procfs 和 sysfs 挂载点
+const (
+	DefaultProcRoot = "/proc"
+	DefaultSysRoot  = "/sys"
+)
+
+// pseudoFilesystems 不反映磁盘容量的伪文件系统
+var pseudoFilesystems = map[string]bool{
+	"autofs":      true,
+	"binfmt_misc": true,
+	"bpf":         true,
+	"cgroup":      true,
+	"cgroup2":     true,
+	"configfs":    true,
+	"debugfs":     true,
+	"devpts":      true,
+	"efivarfs":    true,
+	"fusectl":     true,
+	"hugetlbfs":   true,
+	"mqueue":      true,
+	"nsfs":        true,
+	"proc":        true,
+	"pstore":      true,
+	"rpc_pipefs":  true,
+	"securityfs":  true,
+	"selinuxfs":   true,
+	"sysfs":       true,
+	"tracefs":     true,
+}
+
+// MountEntry /proc/mounts 或 /proc/self/mountinfo 中的一条挂载记录
+type MountEntry struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    string // 挂载选项和超级块选项，与 /proc/mounts 一致
+}
+
+// parseLoadavg 解析 /proc/loadavg
+func parseLoadavg(data string) (load1, load5, load15 float64, err error) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("unexpected loadavg format: %q", strings.TrimSpace(data))
+	}
+
+	values := make([]float64, 3)
+	for i := range values {
+		values[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid loadavg value %q: %w", fields[i], err)
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// parseMeminfo 解析 /proc/meminfo，返回以字节为单位的数值
+func parseMeminfo(data string) map[string]uint64 {
+	values := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+
+	return values
+}
+
+// memoryFromMeminfo 根据 /proc/meminfo 计算内存指标
+func memoryFromMeminfo(data string) (MemoryMetrics, error) {
+	values := parseMeminfo(data)
+
+	metrics := MemoryMetrics{
+		Total:     values["MemTotal"],
+		SwapTotal: values["SwapTotal"],
+	}
+	if metrics.Total == 0 {
+		return metrics, fmt.Errorf("MemTotal not found in meminfo")
+	}
+
+	// 3.14 之前的内核没有 MemAvailable，按 free + buffers + cached 估算
+	if available, ok := values["MemAvailable"]; ok {
+		metrics.Available = available
+	} else {
+		metrics.Available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	if metrics.Available > metrics.Total {
+		metrics.Available = metrics.Total
+	}
+
+	metrics.Used = metrics.Total - metrics.Available
+	metrics.UsedPercent = float64(metrics.Used) / float64(metrics.Total) * 100
+
+	if swapFree, ok := values["SwapFree"]; ok && swapFree <= metrics.SwapTotal {
+		metrics.SwapUsed = metrics.SwapTotal - swapFree
+	}
+
+	return metrics, nil
+}
+
+// parseNetDev 解析 /proc/net/dev
+func parseNetDev(data string) []NetworkMetrics {
+	var metrics []NetworkMetrics
+
+	lines := strings.Split(data, "\n")
+	for i, line := range lines {
+		// 前两行为表头
+		if i < 2 || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		iface, counters, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(counters)
+		if len(fields) < 16 {
+			continue
+		}
+
+		values := make([]uint64, 16)
+		for j := range values {
+			values[j], _ = strconv.ParseUint(fields[j], 10, 64)
+		}
+
+		metrics = append(metrics, NetworkMetrics{
+			Interface:   strings.TrimSpace(iface),
+			BytesRecv:   values[0],
+			PacketsRecv: values[1],
+			RxErrors:    values[2],
+			RxDropped:   values[3],
+			RxFifo:      values[4],
+			BytesSent:   values[8],
+			PacketsSent: values[9],
+			TxErrors:    values[10],
+			TxDropped:   values[11],
+			TxFifo:      values[12],
+			Errors:      values[2] + values[10],
+		})
+	}
+
+	return metrics
+}
+
+// parseDiskstats 解析 /proc/diskstats，忽略 loop 和 ram 设备
+func parseDiskstats(data string) []DiskIOMetrics {
+	var metrics []DiskIOMetrics
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 14 {
+			continue
+		}
+
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+
+		values := make([]uint64, 11)
+		for i := range values {
+			values[i], _ = strconv.ParseUint(fields[i+3], 10, 64)
+		}
+
+		metrics = append(metrics, DiskIOMetrics{
+			Device:          name,
+			ReadsCompleted:  values[0],
+			SectorsRead:     values[2],
+			ReadTimeMs:      values[3],
+			WritesCompleted: values[4],
+			SectorsWritten:  values[6],
+			WriteTimeMs:     values[7],
+			InProgress:      values[8],
+			IOTimeMs:        values[9],
+		})
+	}
+
+	return metrics
+}
+
+// parseProcessState 从 /proc/[pid]/stat 中解析进程状态
+// 进程名可能包含空格和括号，因此以最后一个 ')' 作为分隔
+func parseProcessState(data string) (byte, error) {
+	end := strings.LastIndexByte(data, ')')
+	if end < 0 || end+2 >= len(data) {
+		return 0, fmt.Errorf("unexpected stat format")
+	}
+	return data[end+2], nil
+}
+
+// parseMounts 解析 /proc/mounts
+func parseMounts(data string) []MountEntry {
+	var mounts []MountEntry
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, MountEntry{
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			Options:    fields[3],
+		})
+	}
+
+	return mounts
+}
+
+// parseMountinfo 解析 /proc/self/mountinfo
+// 格式为 "ID 父ID 主:次 根 挂载点 挂载选项 [可选字段...] - 类型 设备 超级块选项"，
+// 可选字段数量不定，以 "-" 分隔
+func parseMountinfo(data string) []MountEntry {
+	var mounts []MountEntry
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || sep+2 >= len(fields) {
+			continue
+		}
+
+		options := fields[5]
+		if sep+3 < len(fields) {
+			options = mergeMountOptions(options, fields[sep+3])
+		}
+		mounts = append(mounts, MountEntry{
+			Device:     unescapeMountField(fields[sep+2]),
+			MountPoint: unescapeMountField(fields[4]),
+			FSType:     fields[sep+1],
+			Options:    options,
+		})
+	}
+
+	return mounts
+}
+
+// mergeMountOptions 合并挂载选项和超级块选项，去掉重复项（如两边都有的 rw）
+func mergeMountOptions(mount, super string) string {
+	options := strings.Split(mount, ",")
+	seen := make(map[string]bool, len(options))
+	for _, o := range options {
+		seen[o] = true
+	}
+	for _, o := range strings.Split(super, ",") {
+		if o != "" && !seen[o] {
+			seen[o] = true
+			options = append(options, o)
+		}
+	}
+	return strings.Join(options, ",")
+}
+
+// unescapeMountField 还原挂载记录中的八进制转义（如空格为 \040）
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// cpuTimes /proc/stat 中一行 CPU 时间（单位 jiffies）
+type cpuTimes struct {
+	CPU     string
+	User    uint64
+	Nice    uint64
+	System  uint64
+	Idle    uint64
+	IOWait  uint64
+	IRQ     uint64
+	SoftIRQ uint64
+	Steal   uint64
+}
+
+// total 返回总时间；guest 已计入 user，不重复累加
+func (t cpuTimes) total() uint64 {
+	return t.User + t.Nice + t.System + t.Idle + t.IOWait + t.IRQ + t.SoftIRQ + t.Steal
+}
+
+// parseProcStat 解析 /proc/stat 中的 CPU 行，返回汇总行和各核心行
+func parseProcStat(data string) (cpuTimes, []cpuTimes, error) {
+	var all cpuTimes
+	var cores []cpuTimes
+	found := false
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		values := make([]uint64, 8)
+		for i := 1; i < len(fields) && i <= len(values); i++ {
+			values[i-1], _ = strconv.ParseUint(fields[i], 10, 64)
+		}
+		t := cpuTimes{
+			CPU:     fields[0],
+			User:    values[0],
+			Nice:    values[1],
+			System:  values[2],
+			Idle:    values[3],
+			IOWait:  values[4],
+			IRQ:     values[5],
+			SoftIRQ: values[6],
+			Steal:   values[7],
+		}
+
+		if t.CPU == "cpu" {
+			all = t
+			found = true
+		} else {
+			cores = append(cores, t)
+		}
+	}
+
+	if !found {
+		return all, nil, fmt.Errorf("cpu line not found in stat")
+	}
+	return all, cores, nil
+}
+
+// cpuUsageBetween 根据两次采样计算各项 CPU 时间占比（%）
+func cpuUsageBetween(prev, cur cpuTimes) CoreCPUMetrics {
+	usage := CoreCPUMetrics{CPU: cur.CPU}
+
+	total := float64(cur.total()) - float64(prev.total())
+	if total <= 0 {
+		return usage
+	}
+
+	pct := func(a, b uint64) float64 {
+		if b < a {
+			return 0
+		}
+		return float64(b-a) / total * 100
+	}
+
+	usage.User = pct(prev.User, cur.User)
+	usage.Nice = pct(prev.Nice, cur.Nice)
+	usage.System = pct(prev.System, cur.System)
+	usage.Idle = pct(prev.Idle, cur.Idle)
+	usage.IOWait = pct(prev.IOWait, cur.IOWait)
+	usage.IRQ = pct(prev.IRQ, cur.IRQ)
+	usage.SoftIRQ = pct(prev.SoftIRQ, cur.SoftIRQ)
+	usage.Steal = pct(prev.Steal, cur.Steal)
+
+	// 使用率不含 idle 和 iowait
+	usage.Usage = 100 - usage.Idle - usage.IOWait
+	if usage.Usage < 0 {
+		usage.Usage = 0
+	}
+	return usage
+}
+
+// procStatCounters /proc/stat 中的系统级计数
+type procStatCounters struct {
+	ContextSwitches uint64
+	Interrupts      uint64
+	ProcsRunning    int
+	ProcsBlocked    int
+}
+
+// parseProcStatCounters 解析 /proc/stat 中的 ctxt、intr、procs_running、procs_blocked
+func parseProcStatCounters(data string) procStatCounters {
+	var counters procStatCounters
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "ctxt":
+			counters.ContextSwitches, _ = strconv.ParseUint(fields[1], 10, 64)
+		case "intr":
+			// 第一列为中断总数，其后为各中断号的计数
+			counters.Interrupts, _ = strconv.ParseUint(fields[1], 10, 64)
+		case "procs_running":
+			counters.ProcsRunning, _ = strconv.Atoi(fields[1])
+		case "procs_blocked":
+			counters.ProcsBlocked, _ = strconv.Atoi(fields[1])
+		}
+	}
+
+	return counters
+}
+
+// procCPUTimes 进程累计 CPU 时间
+type procCPUTimes struct {
+	PID   int
+	Comm  string
+	Ticks uint64 // utime + stime，单位 USER_HZ
+}
+
+// parseProcPIDStat 解析 /proc/[pid]/stat 中的进程名和 CPU 时间
+func parseProcPIDStat(data string) (procCPUTimes, error) {
+	var p procCPUTimes
+
+	start := strings.IndexByte(data, '(')
+	end := strings.LastIndexByte(data, ')')
+	if start < 0 || end < start {
+		return p, fmt.Errorf("unexpected stat format")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(data[:start]))
+	if err != nil {
+		return p, fmt.Errorf("invalid pid: %w", err)
+	}
+
+	// ')' 之后第一列为 state（第 3 列），utime 和 stime 为第 14、15 列
+	fields := strings.Fields(data[end+1:])
+	if len(fields) < 13 {
+		return p, fmt.Errorf("unexpected stat format")
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+
+	p.PID = pid
+	p.Comm = data[start+1 : end]
+	p.Ticks = utime + stime
+	return p, nil
+}
+
+// parseStatusUID 从 /proc/[pid]/status 中解析真实 UID
+func parseStatusUID(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(line, "Uid:") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				return fields[1]
+			}
+		}
+	}
+	return ""
+}
+
+// parsePasswd 解析 /etc/passwd，返回 UID 到用户名的映射
+func parsePasswd(data string) map[string]string {
+	users := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) >= 3 && fields[0] != "" && !strings.HasPrefix(fields[0], "#") {
+			users[fields[2]] = fields[0]
+		}
+	}
+	return users
+}
@@ -0,0 +1,406 @@
+package collector
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFixtureMetricsCollector 创建读取 testdata/proc 的指标采集器
+func newFixtureMetricsCollector(t *testing.T) *MetricsCollector {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("metrics sections are only collected on Linux")
+	}
+
+	mc := NewMetricsCollectorWithOptions(Config{}, MetricsOptions{
+		ProcRoot:          "testdata/proc",
+		CPUSampleInterval: time.Millisecond,
+	})
+	mc.statfs = func(path string) (FilesystemUsage, error) {
+		switch path {
+		case "/":
+			return FilesystemUsage{Total: 1000, Free: 250, Available: 200, Files: 100, FilesFree: 5}, nil
+		case "/data disk":
+			return FilesystemUsage{Total: 4000, Free: 4000, Available: 4000}, nil
+		case "/run":
+			return FilesystemUsage{}, nil
+		}
+		return FilesystemUsage{}, fmt.Errorf("unexpected statfs path %s", path)
+	}
+	return mc
+}
+
+func TestMetricsFromProcFixture(t *testing.T) {
+	mc := newFixtureMetricsCollector(t)
+
+	metrics, err := mc.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+	if metrics.Partial() {
+		t.Fatalf("Expected all sections from fixture, got %+v", metrics.SectionErrors)
+	}
+
+	if metrics.CPU.Cores != 2 || len(metrics.CPU.PerCore) != 2 {
+		t.Errorf("Expected 2 cores from stat fixture, got %d (%d per-core)", metrics.CPU.Cores, len(metrics.CPU.PerCore))
+	}
+	if metrics.CPU.LoadAvg1 != 1.25 || metrics.CPU.LoadAvg15 != 0.5 {
+		t.Errorf("Unexpected load average: %+v", metrics.CPU)
+	}
+
+	mem := metrics.Memory
+	if mem.Total != 16384000*1024 || mem.Available != 4096000*1024 {
+		t.Errorf("Unexpected memory totals: %+v", mem)
+	}
+	if mem.UsedPercent != 75 {
+		t.Errorf("Expected 75%% memory used, got %.2f", mem.UsedPercent)
+	}
+	if mem.SwapUsed != 1048576*1024 {
+		t.Errorf("Expected 1GiB swap used, got %d", mem.SwapUsed)
+	}
+
+	if len(metrics.Disk) != 2 {
+		t.Fatalf("Expected 2 real filesystems, got %+v", metrics.Disk)
+	}
+	if metrics.Disk[0].MountPoint != "/" || metrics.Disk[0].Used != 750 || metrics.Disk[0].UsedPercent != 75 {
+		t.Errorf("Unexpected root filesystem: %+v", metrics.Disk[0])
+	}
+	if metrics.Disk[1].MountPoint != "/data disk" || metrics.Disk[1].FSType != "xfs" {
+		t.Errorf("Expected escaped mount point to be decoded, got %+v", metrics.Disk[1])
+	}
+	if root := metrics.Disk[0]; root.InodesUsed != 95 || root.InodesUsedPercent != 95 ||
+		root.MountOptions != "rw,relatime,errors=remount-ro" {
+		t.Errorf("Expected inode usage and options from mountinfo, got %+v", root)
+	}
+
+	if len(metrics.Network) != 2 || metrics.Network[1].Interface != "eth0" ||
+		metrics.Network[1].BytesRecv != 98765432 || metrics.Network[1].BytesSent != 12345678 {
+		t.Errorf("Unexpected network metrics: %+v", metrics.Network)
+	}
+
+	if eth0 := metrics.Network[1]; eth0.Errors != 2 || eth0.RxDropped != 1 || eth0.TxDropped != 3 {
+		t.Errorf("Unexpected eth0 error counters: %+v", eth0)
+	}
+
+	if len(metrics.DiskIO) != 3 || metrics.DiskIO[0].Device != "sda" {
+		t.Fatalf("Expected loop devices to be skipped, got %+v", metrics.DiskIO)
+	}
+	if sda := metrics.DiskIO[0]; sda.ReadsCompleted != 184530 || sda.SectorsWritten != 21850024 || sda.IOTimeMs != 673228 {
+		t.Errorf("Unexpected sda counters: %+v", sda)
+	}
+
+	proc := metrics.Process
+	if proc.Total != 3 || proc.Running != 1 || proc.Sleeping != 1 || proc.Zombie != 1 {
+		t.Errorf("Unexpected process metrics: %+v", proc)
+	}
+}
+
+func TestMemoryFromMeminfoWithoutMemAvailable(t *testing.T) {
+	mem, err := memoryFromMeminfo("MemTotal: 1000 kB\nMemFree: 100 kB\nBuffers: 50 kB\nCached: 250 kB\n")
+	if err != nil {
+		t.Fatalf("memoryFromMeminfo failed: %v", err)
+	}
+	if mem.Available != 400*1024 || mem.Used != 600*1024 {
+		t.Errorf("Expected available estimated from free+buffers+cached, got %+v", mem)
+	}
+
+	if _, err := memoryFromMeminfo("MemFree: 100 kB\n"); err == nil {
+		t.Error("Expected error without MemTotal")
+	}
+}
+
+func TestParseProcessState(t *testing.T) {
+	tests := map[string]byte{
+		"1 (systemd) S 0 1":              'S',
+		"42 (a) b) (c) R 2 0":            'R',
+		"7 (name with spaces) D 1 7 7 0": 'D',
+	}
+	for input, want := range tests {
+		got, err := parseProcessState(input)
+		if err != nil || got != want {
+			t.Errorf("parseProcessState(%q) = %c, %v; want %c", input, got, err, want)
+		}
+	}
+
+	if _, err := parseProcessState("garbage"); err == nil {
+		t.Error("Expected error for malformed stat")
+	}
+}
+
+func TestCPUUsageBetween(t *testing.T) {
+	prev, prevCores, err := parseProcStat("cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 100 0 100 700 100 0 0 0 0 0\n")
+	if err != nil {
+		t.Fatalf("parseProcStat failed: %v", err)
+	}
+	cur, curCores, err := parseProcStat("cpu  400 0 200 1000 200 50 50 100 0 0\ncpu0 400 0 200 1000 200 50 50 100 0 0\n")
+	if err != nil {
+		t.Fatalf("parseProcStat failed: %v", err)
+	}
+	if len(prevCores) != 1 || len(curCores) != 1 {
+		t.Fatalf("Expected one core, got %d/%d", len(prevCores), len(curCores))
+	}
+
+	// 窗口内总计 1000 jiffies：user 300, system 100, idle 300, iowait 100, irq 50, softirq 50, steal 100
+	usage := cpuUsageBetween(prev, cur)
+	want := map[string][2]float64{
+		"usage":   {usage.Usage, 60},
+		"user":    {usage.User, 30},
+		"system":  {usage.System, 10},
+		"idle":    {usage.Idle, 30},
+		"iowait":  {usage.IOWait, 10},
+		"irq":     {usage.IRQ, 5},
+		"softirq": {usage.SoftIRQ, 5},
+		"steal":   {usage.Steal, 10},
+	}
+	for name, v := range want {
+		if math.Abs(v[0]-v[1]) > 1e-9 {
+			t.Errorf("Expected %s %.2f%%, got %.2f%%", name, v[1], v[0])
+		}
+	}
+
+	// 计数器未变化时不应产生除零
+	if idle := cpuUsageBetween(cur, cur); idle.Usage != 0 {
+		t.Errorf("Expected zero usage for empty window, got %.2f", idle.Usage)
+	}
+
+	if _, _, err := parseProcStat("intr 1 2 3\n"); err == nil {
+		t.Error("Expected error without cpu line")
+	}
+}
+
+func TestNetworkRates(t *testing.T) {
+	prev := NetworkMetrics{BytesRecv: 1000, BytesSent: 500, PacketsRecv: 10, RxErrors: 1, TxDropped: 4}
+	cur := NetworkMetrics{BytesRecv: 3000, BytesSent: 1500, PacketsRecv: 30, RxErrors: 3, TxDropped: 2}
+
+	rates := networkRates(prev, cur, 2)
+	if rates.RxBytesPerSec != 1000 || rates.TxBytesPerSec != 500 || rates.RxPacketsPerSec != 10 || rates.RxErrorsPerSec != 1 {
+		t.Errorf("Unexpected network rates: %+v", rates)
+	}
+	if rates.TxDroppedPerSec != 0 {
+		t.Errorf("Expected counter reset to yield zero rate, got %.2f", rates.TxDroppedPerSec)
+	}
+}
+
+func TestDiskIORates(t *testing.T) {
+	prev := DiskIOMetrics{ReadsCompleted: 100, WritesCompleted: 200, SectorsRead: 1000, SectorsWritten: 2000,
+		ReadTimeMs: 50, WriteTimeMs: 100, IOTimeMs: 1000}
+	cur := DiskIOMetrics{ReadsCompleted: 300, WritesCompleted: 400, SectorsRead: 5000, SectorsWritten: 6000,
+		ReadTimeMs: 450, WriteTimeMs: 1100, IOTimeMs: 1500}
+
+	rates := diskIORates(prev, cur, 2)
+	if rates.ReadIOPS != 100 || rates.WriteIOPS != 100 {
+		t.Errorf("Unexpected IOPS: %+v", rates)
+	}
+	if rates.ReadBytesPerSec != 4000*512/2 || rates.WriteBytesPerSec != 4000*512/2 {
+		t.Errorf("Unexpected throughput: %+v", rates)
+	}
+	if rates.ReadAwaitMs != 2 || rates.WriteAwaitMs != 5 || rates.AwaitMs != 3.5 {
+		t.Errorf("Unexpected await: %+v", rates)
+	}
+	if rates.UtilPercent != 25 {
+		t.Errorf("Expected 25%% utilisation, got %.2f", rates.UtilPercent)
+	}
+}
+
+func TestCollectMetricsRateMode(t *testing.T) {
+	mc := newFixtureMetricsCollector(t)
+	mc.SetRateInterval(20 * time.Millisecond)
+
+	metrics, err := mc.CollectMetrics()
+	if err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+
+	// 静态夹具的计数器不变，速率应存在且为 0
+	for _, n := range metrics.Network {
+		if n.Rates == nil || n.Rates.RxBytesPerSec != 0 {
+			t.Errorf("Expected zero rates for %s, got %+v", n.Interface, n.Rates)
+		}
+	}
+	for _, d := range metrics.DiskIO {
+		if d.Rates == nil {
+			t.Errorf("Expected rates for %s", d.Device)
+		}
+	}
+}
+
+func TestParseProcPIDStat(t *testing.T) {
+	p, err := parseProcPIDStat("42 (my (weird) proc) R 1 42 42 0 -1 4194560 500 0 0 0 150 50 0 0 20 0 1 0 100 1000000 200\n")
+	if err != nil {
+		t.Fatalf("parseProcPIDStat failed: %v", err)
+	}
+	if p.PID != 42 || p.Comm != "my (weird) proc" || p.Ticks != 200 {
+		t.Errorf("Unexpected process times: %+v", p)
+	}
+
+	if _, err := parseProcPIDStat("garbage"); err == nil {
+		t.Error("Expected error for malformed stat")
+	}
+}
+
+func TestPerfSnapNativeDerivations(t *testing.T) {
+	first := &perfSnapSample{
+		cpuCores: []cpuTimes{{CPU: "cpu0", User: 100, System: 100, Idle: 800}},
+		counters: procStatCounters{ContextSwitches: 1000, Interrupts: 500},
+		networks: map[string]NetworkMetrics{
+			"lo":   {Interface: "lo"},
+			"eth0": {Interface: "eth0", BytesRecv: 0, BytesSent: 0, PacketsRecv: 0, PacketsSent: 0},
+		},
+		procs: map[int]procCPUTimes{
+			1:  {PID: 1, Comm: "init", Ticks: 100},
+			42: {PID: 42, Comm: "busy", Ticks: 100},
+		},
+	}
+	second := &perfSnapSample{
+		cpuCores: []cpuTimes{{CPU: "cpu0", User: 400, System: 200, Idle: 1400}},
+		counters: procStatCounters{ContextSwitches: 3000, Interrupts: 1500, ProcsRunning: 3, ProcsBlocked: 1},
+		networks: map[string]NetworkMetrics{
+			"lo":   {Interface: "lo", BytesRecv: 1 << 20},
+			"eth0": {Interface: "eth0", BytesRecv: 2048 * 2, BytesSent: 1024 * 2, PacketsRecv: 20, PacketsSent: 10},
+		},
+		procs: map[int]procCPUTimes{
+			1:  {PID: 1, Comm: "init", Ticks: 101},
+			42: {PID: 42, Comm: "busy", Ticks: 200},
+			99: {PID: 99, Comm: "new", Ticks: 500},
+		},
+	}
+
+	vm := nativeVMStat(first, second, 2)
+	if vm.RunQueue != 3 || vm.BlockedProcesses != 1 || vm.ContextSwitches != 1000 || vm.Interrupts != 500 {
+		t.Errorf("Unexpected vmstat: %+v", vm)
+	}
+
+	cpus := nativeCPUStats(first, second)
+	if len(cpus) != 1 || cpus[0].CPU != "0" || cpus[0].User != 30 || cpus[0].System != 10 || cpus[0].Idle != 60 {
+		t.Errorf("Unexpected CPU stats: %+v", cpus)
+	}
+
+	nets := nativeNetworkStats(first, second, 2)
+	if len(nets) != 1 || nets[0].Interface != "eth0" || nets[0].RxKBps != 2 || nets[0].TxKBps != 1 || nets[0].RxPckps != 10 {
+		t.Errorf("Unexpected network stats: %+v", nets)
+	}
+
+	executor := NewFakeExecutor("node-a")
+	executor.SetFile("/etc/passwd", "root:x:0:0:root:/root:/bin/bash\nappuser:x:1000:1000::/home/appuser:/bin/sh\n")
+	executor.SetFile("/proc/42/status", "Name:\tbusy\nUid:\t1000\t1000\t1000\t1000\n")
+	c := NewPerfSnapCollector()
+	c.SetExecutor(executor)
+
+	// 100 ticks / 2s = 50%；init 仅 0.5%，新出现的进程没有基线
+	procs := c.nativeProcessStats(first, second, 2)
+	if len(procs) != 1 || procs[0].PID != 42 || procs[0].CPUPct != 50 || procs[0].User != "appuser" {
+		t.Errorf("Unexpected process stats: %+v", procs)
+	}
+}
+
+func TestPerfSnapNativeBackend(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	for _, name := range []string{"stat", "diskstats", "net/dev", "meminfo", "loadavg"} {
+		data, err := os.ReadFile(filepath.Join("testdata/proc", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		executor.SetFile("/proc/"+name, string(data))
+	}
+	executor.SetFile("/sys/block/sda/stat", "")
+	executor.SetFile("/sys/block/nvme0n1/stat", "")
+
+	c := NewPerfSnapCollectorWithOptions(0, false)
+	c.SetExecutor(executor)
+
+	data, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if data.Backend != PerfSnapBackendNative {
+		t.Errorf("Expected native backend recorded, got '%s'", data.Backend)
+	}
+	if data.VMStat.RunQueue != 2 {
+		t.Errorf("Expected run queue from procs_running, got %d", data.VMStat.RunQueue)
+	}
+	if len(data.CPUStats) != 2 || data.CPUStats[0].CPU != "0" {
+		t.Errorf("Expected per-core stats for 2 CPUs, got %+v", data.CPUStats)
+	}
+	if len(data.DiskIOStats) != 2 || data.DiskIOStats[0].Device != "nvme0n1" || data.DiskIOStats[1].Device != "sda" {
+		t.Errorf("Expected whole-disk I/O stats only, got %+v", data.DiskIOStats)
+	}
+	if len(data.NetworkStats) != 1 || data.NetworkStats[0].Interface != "eth0" {
+		t.Errorf("Expected eth0 network stats, got %+v", data.NetworkStats)
+	}
+	if data.MemoryStats.TotalMB != 16000 {
+		t.Errorf("Expected 16000 MB total memory from meminfo, got %d", data.MemoryStats.TotalMB)
+	}
+	for _, call := range executor.Calls() {
+		for _, tool := range []string{"vmstat", "mpstat", "pidstat", "iostat", "sar"} {
+			if strings.HasPrefix(call, tool+" ") {
+				t.Errorf("Native backend should not run %s", call)
+			}
+		}
+	}
+}
+
+func TestSummarizeSeries(t *testing.T) {
+	var points []PerfSnapSeriesPoint
+	for i := 1; i <= 20; i++ {
+		metrics := map[string]float64{"cpu.usage": float64(i)}
+		if i > 15 {
+			metrics["disk.sdb.util_pct"] = 50
+		}
+		points = append(points, PerfSnapSeriesPoint{Metrics: metrics})
+	}
+
+	summary := summarizeSeries(points)
+	cpu := summary["cpu.usage"]
+	if cpu.Samples != 20 || cpu.Min != 1 || cpu.Max != 20 || cpu.Avg != 10.5 || cpu.P95 != 19 {
+		t.Errorf("Unexpected cpu summary: %+v", cpu)
+	}
+	if disk := summary["disk.sdb.util_pct"]; disk.Samples != 5 || disk.P95 != 50 {
+		t.Errorf("Expected device summary over its own samples, got %+v", disk)
+	}
+}
+
+func TestPerfSnapSeriesMode(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	for _, name := range []string{"stat", "diskstats", "net/dev", "meminfo", "loadavg"} {
+		data, err := os.ReadFile(filepath.Join("testdata/proc", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		executor.SetFile("/proc/"+name, string(data))
+	}
+
+	c := NewPerfSnapCollectorWithOptions(1, false)
+	c.SetExecutor(executor)
+	if err := c.SetInterval(2 * time.Second); err == nil {
+		t.Error("Expected error for interval longer than duration")
+	}
+	if err := c.SetInterval(250 * time.Millisecond); err != nil {
+		t.Fatalf("SetInterval failed: %v", err)
+	}
+
+	data, err := c.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	series := data.Series
+	if series == nil || len(series.Points) != 4 {
+		t.Fatalf("Expected 4 points over 1s at 250ms, got %+v", series)
+	}
+	if load := series.Summary["load.1m"]; load.Samples != 4 || load.Max != 1.25 {
+		t.Errorf("Unexpected load summary: %+v", load)
+	}
+	if _, ok := series.Summary["net.eth0.rx_kbps"]; !ok {
+		t.Errorf("Expected per-interface metrics in summary, got %v", series.Summary)
+	}
+	if series.Duration < 0.9 {
+		t.Errorf("Expected series to span the duration, got %.2fs", series.Duration)
+	}
+}
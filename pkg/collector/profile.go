@@ -0,0 +1,395 @@
+package collector
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// builtinProfiles 内置调优配置
+//
+//go:embed profiles/*.yaml
+var builtinProfiles embed.FS
+
+// 调优配置中各项设置对应的节点文件
+const (
+	sysctlRoot       = "/proc/sys"
+	thpEnabledPath   = "/sys/kernel/mm/transparent_hugepage/enabled"
+	cpuGovernorGlob  = "/sys/devices/system/cpu/cpu*/cpufreq/scaling_governor"
+	diskSchedulerDir = "/sys/block"
+	ulimitsDir       = "/etc/security/limits.d"
+)
+
+// TuningProfile 声明式调优配置，描述节点期望的内核参数、CPU 调频、内核模块、THP、磁盘调度器和资源限制
+type TuningProfile struct {
+	Name           string            `json:"name" yaml:"name"`
+	Description    string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Sysctls        map[string]string `json:"sysctls,omitempty" yaml:"sysctls,omitempty"`
+	CPUGovernor    string            `json:"cpu_governor,omitempty" yaml:"cpu_governor,omitempty"`
+	KernelModules  []string          `json:"kernel_modules,omitempty" yaml:"kernel_modules,omitempty"`
+	THP            string            `json:"transparent_hugepage,omitempty" yaml:"transparent_hugepage,omitempty"`
+	DiskSchedulers map[string]string `json:"disk_schedulers,omitempty" yaml:"disk_schedulers,omitempty"` // 设备名通配符 -> 调度器
+	Ulimits        map[string]string `json:"ulimits,omitempty" yaml:"ulimits,omitempty"`                 // 资源名 -> 软硬限制
+}
+
+var (
+	validSysctlKey = regexp.MustCompile(`^[a-z0-9_]+(\.[A-Za-z0-9_\-]+)+$`)
+	validToken     = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+	validLimit     = regexp.MustCompile(`^([0-9]+|unlimited)$`)
+)
+
+// ulimitItems limits.conf 支持的资源名
+var ulimitItems = map[string]bool{
+	"core": true, "data": true, "fsize": true, "memlock": true, "nofile": true, "rss": true,
+	"stack": true, "cpu": true, "nproc": true, "as": true, "maxlogins": true, "maxsyslogins": true,
+	"locks": true, "sigpending": true, "msgqueue": true, "nice": true, "rtprio": true,
+}
+
+// Validate 校验调优配置，避免将非法路径或参数写入节点
+func (p *TuningProfile) Validate() error {
+	if !validToken.MatchString(p.Name) {
+		return fmt.Errorf("invalid profile name: %q", p.Name)
+	}
+	for key := range p.Sysctls {
+		if !validSysctlKey.MatchString(key) {
+			return fmt.Errorf("profile %s: invalid sysctl key: %q", p.Name, key)
+		}
+	}
+	if p.CPUGovernor != "" && !validToken.MatchString(p.CPUGovernor) {
+		return fmt.Errorf("profile %s: invalid cpu_governor: %q", p.Name, p.CPUGovernor)
+	}
+	for _, name := range p.KernelModules {
+		if !validToken.MatchString(name) {
+			return fmt.Errorf("profile %s: invalid kernel module: %q", p.Name, name)
+		}
+	}
+	switch p.THP {
+	case "", "always", "madvise", "never":
+	default:
+		return fmt.Errorf("profile %s: transparent_hugepage must be always, madvise or never: %q", p.Name, p.THP)
+	}
+	for pattern, scheduler := range p.DiskSchedulers {
+		if _, err := path.Match(pattern, ""); err != nil || strings.Contains(pattern, "/") {
+			return fmt.Errorf("profile %s: invalid disk pattern: %q", p.Name, pattern)
+		}
+		if !validToken.MatchString(scheduler) {
+			return fmt.Errorf("profile %s: invalid disk scheduler: %q", p.Name, scheduler)
+		}
+	}
+	for item, value := range p.Ulimits {
+		if !ulimitItems[item] {
+			return fmt.Errorf("profile %s: unknown ulimit item: %q", p.Name, item)
+		}
+		if !validLimit.MatchString(value) {
+			return fmt.Errorf("profile %s: ulimit %s must be a number or unlimited: %q", p.Name, item, value)
+		}
+	}
+	return nil
+}
+
+// ParseTuningProfile 解析 YAML 格式的调优配置
+func ParseTuningProfile(data []byte) (*TuningProfile, error) {
+	var profile TuningProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse tuning profile: %w", err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// LoadTuningProfile 按名称或文件路径加载调优配置
+// name 为文件路径时直接读取；否则依次查找 dirs 中的 <name>.yaml 和内置配置
+func LoadTuningProfile(name string, dirs ...string) (*TuningProfile, error) {
+	if strings.ContainsRune(name, os.PathSeparator) || strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tuning profile: %w", err)
+		}
+		return ParseTuningProfile(data)
+	}
+	if !validToken.MatchString(name) {
+		return nil, fmt.Errorf("invalid profile name: %q", name)
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name+".yaml"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tuning profile: %w", err)
+		}
+		return ParseTuningProfile(data)
+	}
+
+	data, err := builtinProfiles.ReadFile("profiles/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("tuning profile not found: %s", name)
+	}
+	return ParseTuningProfile(data)
+}
+
+// TuningProfiles 返回 dirs 中和内置的所有调优配置，同名时 dirs 中的优先
+func TuningProfiles(dirs ...string) ([]*TuningProfile, error) {
+	byName := make(map[string]*TuningProfile)
+
+	entries, err := builtinProfiles.ReadDir("profiles")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := builtinProfiles.ReadFile("profiles/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		profile, err := ParseTuningProfile(data)
+		if err != nil {
+			return nil, fmt.Errorf("builtin profile %s: %w", entry.Name(), err)
+		}
+		byName[profile.Name] = profile
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tuning profile: %w", err)
+			}
+			profile, err := ParseTuningProfile(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			byName[profile.Name] = profile
+		}
+	}
+
+	profiles := make([]*TuningProfile, 0, len(byName))
+	for _, profile := range byName {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// ProfileCheck 调优配置中单项设置的检查结果
+type ProfileCheck struct {
+	Action    RemediationAction `json:"action" yaml:"action"`
+	Compliant bool              `json:"compliant" yaml:"compliant"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ProfileReport 节点与调优配置的对比结果
+type ProfileReport struct {
+	Profile   string         `json:"profile" yaml:"profile"`
+	Node      string         `json:"node" yaml:"node"`
+	CheckedAt time.Time      `json:"checked_at" yaml:"checked_at"`
+	Checks    []ProfileCheck `json:"checks" yaml:"checks"`
+}
+
+// Drift 返回与配置不一致的检查项，无法读取的项不计入
+func (r *ProfileReport) Drift() []ProfileCheck {
+	var drift []ProfileCheck
+	for _, c := range r.Checks {
+		if !c.Compliant && c.Error == "" {
+			drift = append(drift, c)
+		}
+	}
+	return drift
+}
+
+// Plan 将偏离配置的检查项转换为修复计划
+// 计划加载内核模块时，无法读取的 sysctl 也加入计划：它们可能由待加载的模块提供，
+// Apply 在模块加载后重新读取原值，仍无法读取时记为失败
+func (r *ProfileReport) Plan() *RemediationPlan {
+	loadsModule := false
+	for _, c := range r.Drift() {
+		if c.Action.Kind == ActionLoadModule {
+			loadsModule = true
+		}
+	}
+
+	plan := &RemediationPlan{Node: r.Node, CreatedAt: time.Now()}
+	for _, c := range r.Checks {
+		drift := !c.Compliant && c.Error == ""
+		pending := loadsModule && c.Error != "" && strings.HasPrefix(c.Action.ID, "sysctl:")
+		if drift || pending {
+			plan.Actions = append(plan.Actions, c.Action)
+		}
+	}
+	return plan
+}
+
+// Evaluate 读取节点当前配置并与调优配置逐项对比
+func (p *TuningProfile) Evaluate(ctx context.Context, executor Executor) (*ProfileReport, error) {
+	actions, err := p.actions(ctx, executor)
+	if err != nil {
+		return nil, err
+	}
+
+	node := executor.Target()
+	if output, err := executor.Run(ctx, "hostname"); err == nil && strings.TrimSpace(output) != "" {
+		node = strings.TrimSpace(output)
+	}
+
+	report := &ProfileReport{Profile: p.Name, Node: node, CheckedAt: time.Now()}
+	remediator := NewRemediator(executor)
+	for _, action := range actions {
+		check := ProfileCheck{Action: action}
+		current, err := remediator.Current(ctx, action)
+		if err != nil {
+			check.Error = err.Error()
+		} else {
+			check.Action.From = current
+			check.Compliant = current == action.To
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// actions 将调优配置展开为节点上的具体动作，From 留空由 Evaluate 读取
+// 内核模块排在最前面：br_netfilter、nf_conntrack 等模块加载后才会出现对应的 sysctl
+func (p *TuningProfile) actions(ctx context.Context, executor Executor) ([]RemediationAction, error) {
+	var actions []RemediationAction
+
+	for _, name := range p.KernelModules {
+		actions = append(actions, RemediationAction{
+			ID:          "kernel_module:" + name,
+			Kind:        ActionLoadModule,
+			Target:      name,
+			Description: "内核模块 " + name,
+			To:          moduleLoaded,
+		})
+	}
+
+	keys := make([]string, 0, len(p.Sysctls))
+	for key := range p.Sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		actions = append(actions, RemediationAction{
+			ID:          "sysctl:" + key,
+			Kind:        ActionWriteFile,
+			Target:      path.Join(sysctlRoot, strings.ReplaceAll(key, ".", "/")),
+			Description: "sysctl " + key,
+			To:          strings.Join(strings.Fields(p.Sysctls[key]), " "),
+		})
+	}
+
+	if p.CPUGovernor != "" {
+		files, err := executor.Glob(ctx, cpuGovernorGlob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cpu governors: %w", err)
+		}
+		for _, file := range files {
+			cpu := path.Base(path.Dir(path.Dir(file)))
+			actions = append(actions, RemediationAction{
+				ID:          "cpu_governor:" + cpu,
+				Kind:        ActionWriteFile,
+				Target:      file,
+				Description: cpu + " 调频模式",
+				To:          p.CPUGovernor,
+			})
+		}
+	}
+
+	if p.THP != "" {
+		actions = append(actions, RemediationAction{
+			ID:          "transparent_hugepage",
+			Kind:        ActionWriteSelect,
+			Target:      thpEnabledPath,
+			Description: "透明大页 (THP)",
+			To:          p.THP,
+		})
+	}
+
+	if len(p.DiskSchedulers) > 0 {
+		files, err := executor.Glob(ctx, diskSchedulerDir+"/*/queue/scheduler")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list disk schedulers: %w", err)
+		}
+		for _, file := range files {
+			device := path.Base(path.Dir(path.Dir(file)))
+			scheduler, ok := p.diskScheduler(device)
+			if !ok {
+				continue
+			}
+			actions = append(actions, RemediationAction{
+				ID:          "disk_scheduler:" + device,
+				Kind:        ActionWriteSelect,
+				Target:      file,
+				Description: device + " I/O 调度器",
+				To:          scheduler,
+			})
+		}
+	}
+
+	if len(p.Ulimits) > 0 {
+		actions = append(actions, RemediationAction{
+			ID:          "ulimits",
+			Kind:        ActionWriteConfig,
+			Target:      path.Join(ulimitsDir, "99-clusterreport-"+p.Name+".conf"),
+			Description: "资源限制 (limits.d，新会话生效)",
+			To:          p.limitsConf(),
+		})
+	}
+
+	return actions, nil
+}
+
+// diskScheduler 返回设备匹配的调度器；精确匹配优先，其次是最长的通配符
+func (p *TuningProfile) diskScheduler(device string) (string, bool) {
+	if scheduler, ok := p.DiskSchedulers[device]; ok {
+		return scheduler, true
+	}
+
+	best := ""
+	for pattern := range p.DiskSchedulers {
+		if ok, _ := path.Match(pattern, device); ok && (len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best)) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return p.DiskSchedulers[best], true
+}
+
+// limitsConf 生成 limits.d 配置文件内容，软硬限制设为相同的值
+func (p *TuningProfile) limitsConf() string {
+	items := make([]string, 0, len(p.Ulimits))
+	for item := range p.Ulimits {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+
+	lines := []string{"# managed by clusterreport tuning profile " + p.Name}
+	for _, item := range items {
+		lines = append(lines,
+			fmt.Sprintf("* soft %s %s", item, p.Ulimits[item]),
+			fmt.Sprintf("* hard %s %s", item, p.Ulimits[item]))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTuningProfiles(t *testing.T) {
+	profiles, err := TuningProfiles()
+	if err != nil {
+		t.Fatalf("TuningProfiles failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range profiles {
+		names[p.Name] = true
+	}
+	for _, name := range []string{"kubernetes-node", "database-host", "low-latency"} {
+		if !names[name] {
+			t.Errorf("Expected builtin profile %s", name)
+		}
+		if _, err := LoadTuningProfile(name); err != nil {
+			t.Errorf("LoadTuningProfile(%s) failed: %v", name, err)
+		}
+	}
+
+	if _, err := LoadTuningProfile("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestLoadTuningProfileOverride(t *testing.T) {
+	dir := t.TempDir()
+	content := "name: low-latency\ndescription: custom\nsysctls:\n  vm.swappiness: \"5\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "low-latency.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadTuningProfile("low-latency", dir)
+	if err != nil {
+		t.Fatalf("LoadTuningProfile failed: %v", err)
+	}
+	if p.Description != "custom" || p.Sysctls["vm.swappiness"] != "5" {
+		t.Errorf("Expected profile from directory, got %+v", p)
+	}
+}
+
+func TestTuningProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile TuningProfile
+	}{
+		{"path traversal", TuningProfile{Name: "bad", Sysctls: map[string]string{"../../etc/passwd": "1"}}},
+		{"thp", TuningProfile{Name: "bad", THP: "sometimes"}},
+		{"module", TuningProfile{Name: "bad", KernelModules: []string{"foo; reboot"}}},
+		{"disk pattern", TuningProfile{Name: "bad", DiskSchedulers: map[string]string{"../sda": "none"}}},
+		{"ulimit item", TuningProfile{Name: "bad", Ulimits: map[string]string{"files": "1024"}}},
+		{"ulimit value", TuningProfile{Name: "bad", Ulimits: map[string]string{"nofile": "many"}}},
+	}
+	for _, tt := range tests {
+		if err := tt.profile.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+	}
+}
+
+func TestTuningProfileEvaluateAndApply(t *testing.T) {
+	ctx := context.Background()
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("hostname", "node-a\n")
+	executor.SetCommand("lsmod", "Module                  Size  Used by\noverlay               151552  0\n")
+	executor.SetCommand("modprobe br_netfilter", "")
+	executor.SetCommand("modprobe -r br_netfilter", "")
+	executor.SetCommand("rm -f /etc/security/limits.d/99-clusterreport-test.conf", "")
+	executor.SetFile("/proc/sys/vm/swappiness", "60\n")
+	executor.SetFile("/proc/sys/net/ipv4/tcp_rmem", "4096\t131072\t6291456\n")
+	executor.SetFile("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor", "performance\n")
+	executor.SetFile(thpEnabledPath, "always [madvise] never\n")
+	executor.SetFile("/sys/block/nvme0n1/queue/scheduler", "[none] mq-deadline\n")
+	executor.SetFile("/sys/block/sda/queue/scheduler", "[mq-deadline] kyber bfq none\n")
+	executor.SetFile("/sys/block/loop0/queue/scheduler", "[none] mq-deadline\n")
+
+	profile := &TuningProfile{
+		Name: "test",
+		Sysctls: map[string]string{
+			"vm.swappiness":     "1",
+			"net.ipv4.tcp_rmem": "4096 131072 6291456",
+		},
+		CPUGovernor:    "performance",
+		KernelModules:  []string{"overlay", "br_netfilter"},
+		THP:            "never",
+		DiskSchedulers: map[string]string{"sd*": "bfq", "nvme*": "none"},
+		Ulimits:        map[string]string{"nofile": "1048576"},
+	}
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	report, err := profile.Evaluate(ctx, executor)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if report.Node != "node-a" || len(report.Checks) != 9 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	drift := make(map[string]RemediationAction)
+	for _, c := range report.Drift() {
+		drift[c.Action.ID] = c.Action
+	}
+	want := map[string]string{
+		"sysctl:vm.swappiness":       "60",
+		"kernel_module:br_netfilter": moduleUnloaded,
+		"transparent_hugepage":       "madvise",
+		"disk_scheduler:sda":         "mq-deadline",
+		"ulimits":                    "",
+	}
+	if len(drift) != len(want) {
+		t.Errorf("Expected drift %v, got %+v", want, drift)
+	}
+	for id, from := range want {
+		if a, ok := drift[id]; !ok || a.From != from {
+			t.Errorf("Drift %s: expected from %q, got %+v", id, from, a)
+		}
+	}
+
+	journal, err := NewRemediator(executor).Apply(ctx, report.Plan())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if data, _ := executor.Written(thpEnabledPath); string(data) != "never\n" {
+		t.Errorf("Expected THP written, got %q", data)
+	}
+	if data, _ := executor.Written("/sys/block/sda/queue/scheduler"); string(data) != "bfq\n" {
+		t.Errorf("Expected scheduler written, got %q", data)
+	}
+	limits, _ := executor.Written("/etc/security/limits.d/99-clusterreport-test.conf")
+	if !strings.Contains(string(limits), "* soft nofile 1048576\n* hard nofile 1048576") {
+		t.Errorf("Unexpected limits file: %q", limits)
+	}
+
+	// limits 文件原本不存在，回滚时应删除
+	if err := NewRemediator(executor).Rollback(ctx, journal); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	removed := false
+	for _, call := range executor.Calls() {
+		if call == "rm -f /etc/security/limits.d/99-clusterreport-test.conf" {
+			removed = true
+		}
+	}
+	if !removed {
+		t.Error("Expected limits file to be removed on rollback")
+	}
+	if data, _ := executor.Written(thpEnabledPath); string(data) != "madvise\n" {
+		t.Errorf("Expected THP restored, got %q", data)
+	}
+}
+
+// moduleSysctlExecutor 模拟加载 br_netfilter 后出现 net.bridge.* sysctl
+type moduleSysctlExecutor struct {
+	*FakeExecutor
+}
+
+func (e *moduleSysctlExecutor) RunCombined(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := e.FakeExecutor.RunCombined(ctx, name, args...)
+	if err == nil && commandLine(name, args...) == "modprobe br_netfilter" {
+		e.SetFile("/proc/sys/net/bridge/bridge-nf-call-iptables", "0\n")
+	}
+	return output, err
+}
+
+func TestTuningProfileModulesBeforeSysctls(t *testing.T) {
+	ctx := context.Background()
+	executor := &moduleSysctlExecutor{FakeExecutor: NewFakeExecutor("node-a")}
+	executor.SetCommand("hostname", "node-a\n")
+	executor.SetCommand("lsmod", "Module                  Size  Used by\n")
+	executor.SetCommand("modprobe br_netfilter", "")
+
+	profile := &TuningProfile{
+		Name:          "k8s",
+		Sysctls:       map[string]string{"net.bridge.bridge-nf-call-iptables": "1"},
+		KernelModules: []string{"br_netfilter"},
+	}
+
+	report, err := profile.Evaluate(ctx, executor)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Action.Kind != ActionLoadModule {
+		t.Fatalf("Expected module check before sysctl check, got %+v", report.Checks)
+	}
+
+	// 模块加载前 sysctl 不存在，仍应排在模块之后进入计划
+	plan := report.Plan()
+	if len(plan.Actions) != 2 || plan.Actions[0].ID != "kernel_module:br_netfilter" ||
+		plan.Actions[1].ID != "sysctl:net.bridge.bridge-nf-call-iptables" {
+		t.Fatalf("Expected module load then sysctl, got %+v", plan.Actions)
+	}
+
+	journal, err := NewRemediator(executor).Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Apply failed: %v (%+v)", err, journal.Entries)
+	}
+	if from := journal.Entries[1].Action.From; from != "0" {
+		t.Errorf("Expected sysctl original value read after loading the module, got %q", from)
+	}
+	if data, _ := executor.Written("/proc/sys/net/bridge/bridge-nf-call-iptables"); string(data) != "1\n" {
+		t.Errorf("Expected bridge sysctl written, got %q", data)
+	}
+}
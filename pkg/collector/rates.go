@@ -0,0 +1,132 @@
+package collector
+
+import "time"
+
+// diskSectorSize /proc/diskstats 中扇区的固定大小
+const diskSectorSize = 512
+
+// counterSample 一次累计计数器采样
+type counterSample struct {
+	at      time.Time
+	network map[string]NetworkMetrics
+	diskIO  map[string]DiskIOMetrics
+}
+
+// sampleCounters 读取网络和磁盘 I/O 累计计数器
+func (mc *MetricsCollector) sampleCounters() *counterSample {
+	sample := &counterSample{
+		at:      time.Now(),
+		network: make(map[string]NetworkMetrics),
+		diskIO:  make(map[string]DiskIOMetrics),
+	}
+
+	if data, err := mc.readProc("net", "dev"); err == nil {
+		for _, n := range parseNetDev(data) {
+			sample.network[n.Interface] = n
+		}
+	}
+	if data, err := mc.readProc("diskstats"); err == nil {
+		for _, d := range parseDiskstats(data) {
+			sample.diskIO[d.Device] = d
+		}
+	}
+
+	return sample
+}
+
+// applyRates 等待速率窗口结束后再次采样，为网络接口和块设备计算速率
+func (mc *MetricsCollector) applyRates(metrics *SystemMetrics, baseline *counterSample) {
+	if wait := mc.rateWindow - time.Since(baseline.at); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	current := mc.sampleCounters()
+	seconds := current.at.Sub(baseline.at).Seconds()
+	if seconds <= 0 {
+		return
+	}
+
+	// 以第二次采样的计数为准，使累计值与速率对应同一时刻
+	for i := range metrics.Network {
+		name := metrics.Network[i].Interface
+		prev, okPrev := baseline.network[name]
+		cur, okCur := current.network[name]
+		if !okPrev || !okCur {
+			continue
+		}
+		rates := networkRates(prev, cur, seconds)
+		cur.Rates = &rates
+		metrics.Network[i] = cur
+	}
+
+	for i := range metrics.DiskIO {
+		name := metrics.DiskIO[i].Device
+		prev, okPrev := baseline.diskIO[name]
+		cur, okCur := current.diskIO[name]
+		if !okPrev || !okCur {
+			continue
+		}
+		rates := diskIORates(prev, cur, seconds)
+		cur.Rates = &rates
+		metrics.DiskIO[i] = cur
+	}
+}
+
+// counterDelta 计算计数器差值，计数器回绕或重置时返回 0
+func counterDelta(prev, cur uint64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur - prev)
+}
+
+// networkRates 根据两次采样计算网络接口速率
+func networkRates(prev, cur NetworkMetrics, seconds float64) NetworkRates {
+	rate := func(a, b uint64) float64 {
+		return counterDelta(a, b) / seconds
+	}
+
+	return NetworkRates{
+		RxBytesPerSec:   rate(prev.BytesRecv, cur.BytesRecv),
+		TxBytesPerSec:   rate(prev.BytesSent, cur.BytesSent),
+		RxPacketsPerSec: rate(prev.PacketsRecv, cur.PacketsRecv),
+		TxPacketsPerSec: rate(prev.PacketsSent, cur.PacketsSent),
+		RxErrorsPerSec:  rate(prev.RxErrors, cur.RxErrors),
+		TxErrorsPerSec:  rate(prev.TxErrors, cur.TxErrors),
+		RxDroppedPerSec: rate(prev.RxDropped, cur.RxDropped),
+		TxDroppedPerSec: rate(prev.TxDropped, cur.TxDropped),
+		RxFifoPerSec:    rate(prev.RxFifo, cur.RxFifo),
+		TxFifoPerSec:    rate(prev.TxFifo, cur.TxFifo),
+	}
+}
+
+// diskIORates 根据两次采样计算块设备速率，await 和利用率的计算方式与 iostat 一致
+func diskIORates(prev, cur DiskIOMetrics, seconds float64) DiskIORates {
+	reads := counterDelta(prev.ReadsCompleted, cur.ReadsCompleted)
+	writes := counterDelta(prev.WritesCompleted, cur.WritesCompleted)
+	readMs := counterDelta(prev.ReadTimeMs, cur.ReadTimeMs)
+	writeMs := counterDelta(prev.WriteTimeMs, cur.WriteTimeMs)
+
+	rates := DiskIORates{
+		ReadIOPS:         reads / seconds,
+		WriteIOPS:        writes / seconds,
+		ReadBytesPerSec:  counterDelta(prev.SectorsRead, cur.SectorsRead) * diskSectorSize / seconds,
+		WriteBytesPerSec: counterDelta(prev.SectorsWritten, cur.SectorsWritten) * diskSectorSize / seconds,
+		UtilPercent:      counterDelta(prev.IOTimeMs, cur.IOTimeMs) / (seconds * 1000) * 100,
+	}
+
+	if reads > 0 {
+		rates.ReadAwaitMs = readMs / reads
+	}
+	if writes > 0 {
+		rates.WriteAwaitMs = writeMs / writes
+	}
+	if reads+writes > 0 {
+		rates.AwaitMs = (readMs + writeMs) / (reads + writes)
+	}
+	if rates.UtilPercent > 100 {
+		rates.UtilPercent = 100
+	}
+
+	return rates
+}
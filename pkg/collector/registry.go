@@ -0,0 +1,285 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Configurable 可通过配置项定制的采集器
+// 配置项来自配置文件 collectors 段中除 enabled/timeout/retry 之外的字段
+type Configurable interface {
+	Configure(options map[string]interface{}) error
+}
+
+// Settings 单个采集器的运行配置，对应配置文件 collectors 段的一项
+type Settings struct {
+	Enabled bool                   `yaml:"enabled" mapstructure:"enabled"`
+	Timeout time.Duration          `yaml:"timeout" mapstructure:"timeout"`
+	Retry   int                    `yaml:"retry" mapstructure:"retry"`
+	Backoff time.Duration          `yaml:"backoff" mapstructure:"backoff"` // 第一次重试前的等待时间，之后翻倍，默认 1s
+	Options map[string]interface{} `yaml:",inline" mapstructure:",remain"`
+}
+
+// Registry 采集器注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry 创建采集器注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]Collector),
+	}
+}
+
+// Register 注册采集器，名称重复时返回错误
+func (r *Registry) Register(name string, c Collector) error {
+	if name == "" {
+		return fmt.Errorf("collector name is empty")
+	}
+	if c == nil {
+		return fmt.Errorf("collector %s is nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[name]; exists {
+		return fmt.Errorf("collector %s already registered", name)
+	}
+	r.collectors[name] = c
+	return nil
+}
+
+// Get 按名称获取采集器
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	return c, ok
+}
+
+// Names 返回已注册的采集器名称（按字母排序）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select 选择要运行的采集器
+// names 非空时按给出的名称选择（忽略 enabled），否则选择 settings 中启用的采集器
+func (r *Registry) Select(names []string, settings map[string]Settings) ([]Collector, error) {
+	if len(names) == 0 {
+		for name, s := range settings {
+			if s.Enabled {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	seen := make(map[string]bool)
+	var selected []Collector
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		c, ok := r.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown collector %s (available: %s)", name, strings.Join(r.Names(), ", "))
+		}
+		selected = append(selected, c)
+	}
+
+	return selected, nil
+}
+
+// defaultRegistry 全局采集器注册表，采集器在 init() 中自注册
+var defaultRegistry = NewRegistry()
+
+// Register 向全局注册表注册采集器，名称重复时 panic
+func Register(name string, c Collector) {
+	if err := defaultRegistry.Register(name, c); err != nil {
+		panic(err)
+	}
+}
+
+// Get 从全局注册表获取采集器
+func Get(name string) (Collector, bool) {
+	return defaultRegistry.Get(name)
+}
+
+// Names 返回全局注册表中的采集器名称
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// Select 从全局注册表选择要运行的采集器
+func Select(names []string, settings map[string]Settings) ([]Collector, error) {
+	return defaultRegistry.Select(names, settings)
+}
+
+// LongRunning 采集本身需要持续一段时间的采集器，如按时长采样的性能采集器
+type LongRunning interface {
+	// MinDuration 返回单次采集至少需要的时间
+	MinDuration() time.Duration
+}
+
+// CollectWithSettings 按配置的超时和重试次数运行采集器
+// Timeout 作用于每次尝试，Retry 为首次失败后的重试次数，只有临时性错误（超时、网络错误）才重试，
+// 重试间隔按 Backoff 指数退避；对 LongRunning 采集器，超时不短于其采集时长加上单条命令的默认超时
+func CollectWithSettings(ctx context.Context, c Collector, node Node, s Settings) (*Data, error) {
+	timeout := s.Timeout
+	if lr, ok := c.(LongRunning); ok && timeout > 0 {
+		if min := lr.MinDuration() + DefaultCommandTimeout; timeout < min {
+			timeout = min
+		}
+	}
+
+	policy := RetryPolicy{Timeout: timeout, Retries: s.Retry, Backoff: s.Backoff, MaxBackoff: DefaultMaxBackoff}
+	data, attempts, _, err := collectWithRetry(ctx, policy, func(ctx context.Context) (*Data, error) {
+		return c.Collect(ctx, node)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collector %s failed after %d attempts: %w", c.Name(), len(attempts), err)
+	}
+	return data, nil
+}
+
+// optionBool 读取布尔配置项
+func optionBool(options map[string]interface{}, key string, def bool) (bool, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case string:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return def, fmt.Errorf("option %s: %w", key, err)
+		}
+		return b, nil
+	default:
+		return def, fmt.Errorf("option %s: expected bool, got %T", key, v)
+	}
+}
+
+// optionInt 读取整数配置项
+func optionInt(options map[string]interface{}, key string, def int) (int, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	switch val := v.(type) {
+	case int:
+		return val, nil
+	case int64:
+		return int(val), nil
+	case float64:
+		return int(val), nil
+	case string:
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return def, fmt.Errorf("option %s: %w", key, err)
+		}
+		return n, nil
+	default:
+		return def, fmt.Errorf("option %s: expected integer, got %T", key, v)
+	}
+}
+
+// optionString 读取字符串配置项
+func optionString(options map[string]interface{}, key string, def string) (string, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return def, fmt.Errorf("option %s: expected string, got %T", key, v)
+	}
+	return s, nil
+}
+
+// optionIntList 读取整数列表配置项，支持列表或逗号分隔的字符串
+func optionIntList(options map[string]interface{}, key string, def []int) ([]int, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	var items []interface{}
+	switch val := v.(type) {
+	case []int:
+		return val, nil
+	case []interface{}:
+		items = val
+	case string:
+		for _, item := range strings.Split(val, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	default:
+		items = []interface{}{v}
+	}
+
+	list := make([]int, 0, len(items))
+	for _, item := range items {
+		n, err := optionInt(map[string]interface{}{key: item}, key, 0)
+		if err != nil {
+			return def, err
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// optionDuration 读取时长配置项，支持 "500ms" 形式的字符串或秒数（数字或数字字符串）
+func optionDuration(options map[string]interface{}, key string, def time.Duration) (time.Duration, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+
+	switch val := v.(type) {
+	case time.Duration:
+		return val, nil
+	case int:
+		return time.Duration(val) * time.Second, nil
+	case float64:
+		return time.Duration(val * float64(time.Second)), nil
+	case string:
+		if secs, err := strconv.ParseFloat(val, 64); err == nil {
+			return time.Duration(secs * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return def, fmt.Errorf("option %s: %w", key, err)
+		}
+		return d, nil
+	default:
+		return def, fmt.Errorf("option %s: expected duration, got %T", key, v)
+	}
+}
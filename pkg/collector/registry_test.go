@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyCollector 前 failures 次采集失败的测试采集器
+type flakyCollector struct {
+	failures  int
+	permanent bool         // 失败时返回不可重试的错误
+	calls     atomic.Int32 // 超时的尝试在后台 goroutine 中继续运行
+	delay     time.Duration
+}
+
+func (c *flakyCollector) Name() string { return "flaky" }
+
+func (c *flakyCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	calls := int(c.calls.Add(1))
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if calls <= c.failures {
+		if c.permanent {
+			return nil, errors.New("permission denied")
+		}
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+	return &Data{Node: node.Name, Type: DataTypeCustom, Collector: c.Name()}, nil
+}
+
+func (c *flakyCollector) Validate(config Config) error { return nil }
+
+func (c *flakyCollector) SupportedTypes() []DataType { return []DataType{DataTypeCustom} }
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("flaky", &flakyCollector{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register("flaky", &flakyCollector{}); err == nil {
+		t.Error("Expected error for duplicate registration")
+	}
+	if err := r.Register("metrics", NewMetricsAdapter(Config{})); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if names := r.Names(); strings.Join(names, ",") != "flaky,metrics" {
+		t.Errorf("Unexpected names %v", names)
+	}
+
+	settings := map[string]Settings{
+		"flaky":   {Enabled: false},
+		"metrics": {Enabled: true},
+	}
+
+	selected, err := r.Select(nil, settings)
+	if err != nil || len(selected) != 1 || selected[0].Name() != "metrics" {
+		t.Errorf("Expected only enabled collectors, got %v (err %v)", selected, err)
+	}
+
+	selected, err = r.Select([]string{"flaky", " flaky"}, settings)
+	if err != nil || len(selected) != 1 || selected[0].Name() != "flaky" {
+		t.Errorf("Expected explicit selection to override enabled, got %v (err %v)", selected, err)
+	}
+
+	if _, err := r.Select([]string{"mysql"}, settings); err == nil || !strings.Contains(err.Error(), "available: flaky, metrics") {
+		t.Errorf("Expected unknown collector error listing available ones, got %v", err)
+	}
+}
+
+func TestBuiltinCollectorsRegistered(t *testing.T) {
+	for _, name := range []string{"system", "metrics", "nodeprobe", "perfsnap"} {
+		if _, ok := Get(name); !ok {
+			t.Errorf("Expected built-in collector %s to be registered", name)
+		}
+	}
+}
+
+func TestCollectWithSettingsRetry(t *testing.T) {
+	c := &flakyCollector{failures: 2}
+
+	if _, err := CollectWithSettings(context.Background(), c, Node{Name: "node-a"}, Settings{Retry: 1, Backoff: time.Millisecond}); err == nil {
+		t.Fatal("Expected failure with only one retry")
+	}
+
+	c = &flakyCollector{failures: 2}
+	start := time.Now()
+	data, err := CollectWithSettings(context.Background(), c, Node{Name: "node-a"}, Settings{Retry: 3, Backoff: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if c.calls.Load() != 3 || data.Node != "node-a" {
+		t.Errorf("Expected 3 attempts, got %d", c.calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected exponential backoff between retries (20ms + 40ms), took %v", elapsed)
+	}
+
+	// 权限、配置等错误重试也不会成功
+	c = &flakyCollector{failures: 2, permanent: true}
+	if _, err := CollectWithSettings(context.Background(), c, Node{Name: "node-a"}, Settings{Retry: 3, Backoff: time.Millisecond}); err == nil || c.calls.Load() != 1 {
+		t.Errorf("Expected permanent error not to be retried, got %d calls (err %v)", c.calls.Load(), err)
+	}
+}
+
+func TestCollectWithSettingsTimeout(t *testing.T) {
+	c := &flakyCollector{delay: time.Second}
+
+	start := time.Now()
+	_, err := CollectWithSettings(context.Background(), c, Node{Name: "node-a"}, Settings{Timeout: 20 * time.Millisecond, Retry: 1, Backoff: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if c.calls.Load() != 2 {
+		t.Errorf("Expected timeout to apply per attempt, got %d calls", c.calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected attempts to be bounded by timeout, took %v", elapsed)
+	}
+}
+
+func TestPerfSnapAdapterConfigure(t *testing.T) {
+	a := NewPerfSnapAdapter(5, false)
+	if err := a.Configure(map[string]interface{}{"duration": 30, "flame_graph": "true"}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if a.duration != 30 || !a.generateFlame {
+		t.Errorf("Unexpected adapter state: %+v", a)
+	}
+	if err := a.Configure(map[string]interface{}{"duration": []int{1}}); err == nil {
+		t.Error("Expected error for invalid duration type")
+	}
+
+	if err := a.Configure(map[string]interface{}{"duration": 10 * time.Minute, "interval": "5s", "flame_graph": false}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if a.duration != 600 || a.interval != 5*time.Second {
+		t.Errorf("Unexpected series settings: %+v", a)
+	}
+	if a.MinDuration() != 10*time.Minute {
+		t.Errorf("Expected min duration to follow duration, got %s", a.MinDuration())
+	}
+	if err := a.Configure(map[string]interface{}{"profile_dir": "/var/tmp/profiles", "profile_pids": []interface{}{42}, "profile_top_processes": true}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if a.profile.OutputDir != "/var/tmp/profiles" || len(a.profile.PIDs) != 1 || a.profile.PIDs[0] != 42 || !a.profile.TopProcesses {
+		t.Errorf("Unexpected profile options: %+v", a.profile)
+	}
+
+	if err := a.Configure(map[string]interface{}{"interval": "20m"}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	if err := a.Validate(Config{}); err == nil {
+		t.Error("Expected error for interval longer than duration")
+	}
+}
+
+func TestPerfSnapAdapterMinDurationWithFlameGraph(t *testing.T) {
+	a := NewPerfSnapAdapter(60, true)
+	// 采样 60s + perf record 60s + perf script 最多 60s+DefaultCommandTimeout
+	want := 3*time.Minute + DefaultCommandTimeout
+	if got := a.MinDuration(); got != want {
+		t.Errorf("Expected flame graph run to need %s, got %s", want, got)
+	}
+
+	// 默认超时短于火焰图所需时间时，每次尝试的超时应被放宽，而不是超时后重试
+	var calls atomic.Int32
+	c := &longRunningCollector{min: a.MinDuration(), calls: &calls}
+	ctx := context.Background()
+	if _, err := CollectWithSettings(ctx, c, Node{Name: "node-a"}, Settings{Timeout: time.Minute, Retry: 2}); err != nil {
+		t.Fatalf("CollectWithSettings failed: %v", err)
+	}
+	if c.timeout < want+DefaultCommandTimeout-time.Second {
+		t.Errorf("Expected attempt timeout of at least %s, got %s", want+DefaultCommandTimeout, c.timeout)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls.Load())
+	}
+}
+
+// longRunningCollector 记录每次尝试的剩余超时
+type longRunningCollector struct {
+	min     time.Duration
+	calls   *atomic.Int32
+	timeout time.Duration
+}
+
+func (c *longRunningCollector) Name() string                 { return "long" }
+func (c *longRunningCollector) Validate(config Config) error { return nil }
+func (c *longRunningCollector) SupportedTypes() []DataType   { return []DataType{DataTypePerformance} }
+func (c *longRunningCollector) MinDuration() time.Duration   { return c.min }
+
+func (c *longRunningCollector) Collect(ctx context.Context, node Node) (*Data, error) {
+	c.calls.Add(1)
+	if deadline, ok := ctx.Deadline(); ok {
+		c.timeout = time.Until(deadline)
+	}
+	return newData(c.Name(), DataTypePerformance, node, nil)
+}
+
+func TestOptionIntList(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []int
+	}{
+		{[]int{1, 2}, []int{1, 2}},
+		{[]interface{}{3, "4"}, []int{3, 4}},
+		{"5, 6", []int{5, 6}},
+		{7, []int{7}},
+	}
+	for _, tt := range tests {
+		got, err := optionIntList(map[string]interface{}{"pids": tt.value}, "pids", nil)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("optionIntList(%v) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := optionIntList(map[string]interface{}{"pids": "1,x"}, "pids", nil); err == nil {
+		t.Error("Expected error for non-numeric pid")
+	}
+}
@@ -0,0 +1,422 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 修复动作类型
+const (
+	ActionWriteFile   = "write_file"   // 写入 sysfs/procfs 等文件
+	ActionWriteSelect = "write_select" // 写入多选一的 sysfs 文件，当前值以 [] 标出，如 I/O 调度器
+	ActionWriteConfig = "write_config" // 写入配置文件，原值为空表示文件不存在，回滚时删除
+	ActionLoadModule  = "load_module"  // 加载内核模块
+	ActionSetTimezone = "set_timezone" // 设置系统时区
+)
+
+// 模块状态，作为 load_module 动作的 From/To
+const (
+	moduleLoaded   = "loaded"
+	moduleUnloaded = "unloaded"
+)
+
+// 变更日志条目状态
+const (
+	JournalPending        = "pending"         // 已记录原值、正在应用；中断时视为可能已应用
+	JournalApplied        = "applied"         // 已应用
+	JournalUnchanged      = "unchanged"       // 应用时已是目标值，未做修改
+	JournalFailed         = "failed"          // 应用失败
+	JournalRolledBack     = "rolled_back"     // 已回滚
+	JournalRollbackFailed = "rollback_failed" // 回滚失败
+)
+
+// RemediationAction 一项修复动作，描述从 From 变更为 To
+type RemediationAction struct {
+	ID          string `json:"id" yaml:"id"`
+	Kind        string `json:"kind" yaml:"kind"`
+	Target      string `json:"target" yaml:"target"` // 文件路径、模块名或 "timezone"
+	Description string `json:"description" yaml:"description"`
+	From        string `json:"from" yaml:"from"`
+	To          string `json:"to" yaml:"to"`
+}
+
+// RemediationPlan 一个节点上待执行的修复动作
+type RemediationPlan struct {
+	Node      string              `json:"node" yaml:"node"`
+	CreatedAt time.Time           `json:"created_at" yaml:"created_at"`
+	Actions   []RemediationAction `json:"actions" yaml:"actions"`
+}
+
+// JournalEntry 一项动作的执行记录，Action.From 为应用前实际读取到的原值
+type JournalEntry struct {
+	Action       RemediationAction `json:"action" yaml:"action"`
+	Status       string            `json:"status" yaml:"status"`
+	AppliedAt    time.Time         `json:"applied_at" yaml:"applied_at"`
+	RolledBackAt *time.Time        `json:"rolled_back_at,omitempty" yaml:"rolled_back_at,omitempty"`
+	Error        string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Journal 变更日志，记录已应用的修改及原值，用于审计和回滚
+type Journal struct {
+	Node      string         `json:"node" yaml:"node"`
+	Target    string         `json:"target" yaml:"target"`
+	CreatedAt time.Time      `json:"created_at" yaml:"created_at"`
+	Entries   []JournalEntry `json:"entries" yaml:"entries"`
+
+	// Path 变更日志文件路径，Remediator 设置了日志目录时由 Apply 填写
+	Path string `json:"-" yaml:"-"`
+}
+
+// ErrJournalNotSaved 变更日志无法保存，Apply 不会在此之后继续修改节点
+var ErrJournalNotSaved = errors.New("journal not saved")
+
+// Changed 返回是否有实际应用（或可能已应用）的修改
+func (j *Journal) Changed() bool {
+	for _, e := range j.Entries {
+		if e.Status == JournalApplied || e.Status == JournalPending {
+			return true
+		}
+	}
+	return false
+}
+
+// Remediator 在节点上执行修复动作并记录变更日志
+type Remediator struct {
+	executor   Executor
+	journalDir string
+}
+
+// NewRemediator 创建修复执行器
+func NewRemediator(executor Executor) *Remediator {
+	return &Remediator{executor: executor}
+}
+
+// SetJournalDir 设置变更日志目录
+// 设置后 Apply 在第一个动作之前保存日志，每个动作执行前后更新日志，中途退出也不会丢失原值
+func (r *Remediator) SetJournalDir(dir string) {
+	r.journalDir = dir
+}
+
+// Current 读取动作目标的当前值
+func (r *Remediator) Current(ctx context.Context, action RemediationAction) (string, error) {
+	switch action.Kind {
+	case ActionWriteFile:
+		data, err := r.executor.ReadFile(ctx, action.Target)
+		if err != nil {
+			return "", err
+		}
+		// 多值参数（如 tcp_rmem）在 /proc/sys 中以制表符分隔，统一为单个空格
+		return strings.Join(strings.Fields(string(data)), " "), nil
+	case ActionWriteSelect:
+		data, err := r.executor.ReadFile(ctx, action.Target)
+		if err != nil {
+			return "", err
+		}
+		selected, _ := parseSelected(string(data))
+		return selected, nil
+	case ActionWriteConfig:
+		// 远程执行器读取不存在的文件时无法区分错误类型，先确认文件是否存在
+		if matches, err := r.executor.Glob(ctx, action.Target); err != nil {
+			return "", err
+		} else if len(matches) == 0 {
+			return "", nil
+		}
+		data, err := r.executor.ReadFile(ctx, action.Target)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case ActionLoadModule:
+		output, err := r.executor.Run(ctx, "lsmod")
+		if err != nil {
+			return "", err
+		}
+		if moduleListed(output, action.Target) {
+			return moduleLoaded, nil
+		}
+		return moduleUnloaded, nil
+	case ActionSetTimezone:
+		return currentTimezone(ctx, r.executor)
+	default:
+		return "", fmt.Errorf("unknown action kind: %s", action.Kind)
+	}
+}
+
+// Apply 按顺序执行计划中的动作
+// 每个动作执行前重新读取当前值作为原值记入日志；单个动作失败不影响后续动作
+// 设置了日志目录时，修改节点前先以 pending 状态保存原值，日志无法保存时停止并返回 ErrJournalNotSaved
+func (r *Remediator) Apply(ctx context.Context, plan *RemediationPlan) (*Journal, error) {
+	journal := &Journal{
+		Node:      plan.Node,
+		Target:    r.executor.Target(),
+		CreatedAt: time.Now(),
+	}
+	if r.journalDir != "" && len(plan.Actions) > 0 {
+		path, err := createJournalFile(r.journalDir, journal)
+		if err != nil {
+			return journal, fmt.Errorf("%w: %v", ErrJournalNotSaved, err)
+		}
+		journal.Path = path
+		if err := r.saveJournal(journal); err != nil {
+			return journal, err
+		}
+	}
+
+	failed := 0
+	for _, action := range plan.Actions {
+		journal.Entries = append(journal.Entries, JournalEntry{Action: action, AppliedAt: time.Now()})
+		entry := &journal.Entries[len(journal.Entries)-1]
+
+		current, err := r.Current(ctx, action)
+		if err != nil {
+			entry.Status = JournalFailed
+			entry.Error = fmt.Sprintf("read current value: %v", err)
+			failed++
+		} else if current == action.To {
+			entry.Action.From = current
+			entry.Status = JournalUnchanged
+		} else {
+			entry.Action.From = current
+			entry.Status = JournalPending
+			if err := r.saveJournal(journal); err != nil {
+				return journal, err
+			}
+			if err := r.set(ctx, action, action.To); err != nil {
+				entry.Status = JournalFailed
+				entry.Error = err.Error()
+				failed++
+			} else {
+				entry.Status = JournalApplied
+			}
+		}
+
+		if err := r.saveJournal(journal); err != nil {
+			return journal, err
+		}
+	}
+
+	if failed > 0 {
+		return journal, fmt.Errorf("%d of %d actions failed", failed, len(plan.Actions))
+	}
+	return journal, nil
+}
+
+// saveJournal 在设置了日志文件时保存变更日志
+func (r *Remediator) saveJournal(journal *Journal) error {
+	if journal.Path == "" {
+		return nil
+	}
+	if err := WriteJournal(journal.Path, journal); err != nil {
+		return fmt.Errorf("%w: %v", ErrJournalNotSaved, err)
+	}
+	return nil
+}
+
+// Rollback 按相反顺序将已应用（或中断时可能已应用）的动作恢复为原值，已回滚的条目会被跳过
+func (r *Remediator) Rollback(ctx context.Context, journal *Journal) error {
+	failed := 0
+	for i := len(journal.Entries) - 1; i >= 0; i-- {
+		entry := &journal.Entries[i]
+		if entry.Status != JournalApplied && entry.Status != JournalPending && entry.Status != JournalRollbackFailed {
+			continue
+		}
+
+		now := time.Now()
+		if err := r.set(ctx, entry.Action, entry.Action.From); err != nil {
+			entry.Status = JournalRollbackFailed
+			entry.Error = err.Error()
+			failed++
+			continue
+		}
+		entry.Status = JournalRolledBack
+		entry.RolledBackAt = &now
+		entry.Error = ""
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d actions failed to roll back", failed)
+	}
+	return nil
+}
+
+// set 将动作目标设置为 value
+func (r *Remediator) set(ctx context.Context, action RemediationAction, value string) error {
+	switch action.Kind {
+	case ActionWriteFile, ActionWriteSelect:
+		return r.executor.WriteFile(ctx, action.Target, []byte(value+"\n"))
+	case ActionWriteConfig:
+		if value == "" {
+			_, err := r.executor.RunCombined(ctx, "rm", "-f", action.Target)
+			return err
+		}
+		return r.executor.WriteFile(ctx, action.Target, []byte(value+"\n"))
+	case ActionLoadModule:
+		if value == moduleLoaded {
+			_, err := r.executor.RunCombined(ctx, "modprobe", action.Target)
+			return err
+		}
+		_, err := r.executor.RunCombined(ctx, "modprobe", "-r", action.Target)
+		return err
+	case ActionSetTimezone:
+		if !validTimezone.MatchString(value) {
+			return fmt.Errorf("invalid timezone: %q", value)
+		}
+		if _, err := r.executor.RunCombined(ctx, "timedatectl", "set-timezone", value); err == nil {
+			return nil
+		}
+		// 没有 systemd 的系统直接更新 /etc/localtime
+		_, err := r.executor.RunCombined(ctx, "ln", "-sf", "/usr/share/zoneinfo/"+value, "/etc/localtime")
+		return err
+	default:
+		return fmt.Errorf("unknown action kind: %s", action.Kind)
+	}
+}
+
+// validTimezone 时区名称，如 Asia/Shanghai、UTC
+var validTimezone = regexp.MustCompile(`^[A-Za-z0-9_+\-]+(/[A-Za-z0-9_+\-]+)*$`)
+
+// currentTimezone 读取节点的当前时区
+func currentTimezone(ctx context.Context, executor Executor) (string, error) {
+	if output, err := executor.Run(ctx, "timedatectl", "show", "--property=Timezone", "--value"); err == nil {
+		if tz := strings.TrimSpace(output); tz != "" {
+			return tz, nil
+		}
+	}
+	if data, err := executor.ReadFile(ctx, "/etc/timezone"); err == nil {
+		if tz := strings.TrimSpace(string(data)); tz != "" {
+			return tz, nil
+		}
+	}
+	output, err := executor.Run(ctx, "readlink", "/etc/localtime")
+	if err != nil {
+		return "", err
+	}
+	if parts := strings.Split(strings.TrimSpace(output), "zoneinfo/"); len(parts) == 2 {
+		return parts[1], nil
+	}
+	return "", fmt.Errorf("unable to determine timezone")
+}
+
+// parseSelected 解析 sysfs 多选一文件，如 THP 的 "always [madvise] never" 或 I/O 调度器的 "mq-deadline [none]"
+// 返回方括号内的选中值和所有可选值；只有一个选项时不带方括号，可选值为空；
+// 多个选项但没有 [] 时返回整个内容
+func parseSelected(content string) (string, []string) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	if len(fields) == 1 {
+		return strings.Trim(fields[0], "[]"), nil
+	}
+
+	selected := ""
+	options := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			field = strings.Trim(field, "[]")
+			selected = field
+		}
+		options = append(options, field)
+	}
+	if selected == "" {
+		selected = strings.TrimSpace(content)
+	}
+	return selected, options
+}
+
+// moduleListed 判断 lsmod 输出中是否包含指定模块
+func moduleListed(lsmod, name string) bool {
+	for _, line := range strings.Split(lsmod, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// journalFileName 返回变更日志的文件名前缀，由节点名和创建时间组成
+func journalFileName(journal *Journal) string {
+	node := strings.Map(func(r rune) rune {
+		if r == '/' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, journal.Node)
+	return fmt.Sprintf("%s-%s", node, journal.CreatedAt.Format("20060102-150405"))
+}
+
+// createJournalFile 在 dir 中新建一个不与已有日志重名的空文件并返回路径
+// 同一秒内对同一节点（如重试或主机名相同的节点）多次 Apply 时依次追加 -2、-3 等后缀，
+// 以 O_EXCL 创建保证不会覆盖其他日志中的原值
+func createJournalFile(dir string, journal *Journal) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	base := journalFileName(journal)
+	for i := 1; ; i++ {
+		name := base + ".json"
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d.json", base, i)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create journal: %w", err)
+		}
+		f.Close()
+		return path, nil
+	}
+}
+
+// SaveJournal 将变更日志保存到 dir 中的新文件，返回文件路径
+func SaveJournal(dir string, journal *Journal) (string, error) {
+	path, err := createJournalFile(dir, journal)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteJournal(path, journal); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteJournal 将变更日志写入指定文件
+// 先写临时文件再重命名，保证日志在任何时刻都是完整的
+func WriteJournal(path string, journal *Journal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// LoadJournal 读取变更日志
+func LoadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var journal Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+	return &journal, nil
+}
@@ -0,0 +1,334 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGovernorPath = "/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"
+
+func newRemediationFakeExecutor() *FakeExecutor {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("hostname", "node-a\n")
+	executor.SetCommand("id -u", "0\n")
+	executor.SetCommand("timedatectl show --property=Timezone --value", "UTC\n")
+	executor.SetCommand("lsmod", "Module                  Size  Used by\nnf_conntrack          172032  1\n")
+	executor.SetCommand("modprobe br_netfilter", "")
+	executor.SetCommand("modprobe -r br_netfilter", "")
+	executor.SetFile(testGovernorPath, "powersave\n")
+	executor.SetFile("/sys/devices/system/cpu/cpu1/cpufreq/scaling_governor", "performance\n")
+	return executor
+}
+
+func TestNodeProbePlan(t *testing.T) {
+	executor := newRemediationFakeExecutor()
+	c := NewNodeProbeCollector(false)
+	c.SetExecutor(executor)
+
+	plan := c.Plan()
+	if plan.Node != "node-a" {
+		t.Errorf("Expected plan for node-a, got %q", plan.Node)
+	}
+
+	ids := make(map[string]RemediationAction)
+	for _, action := range plan.Actions {
+		ids[action.ID] = action
+	}
+	if len(plan.Actions) != 3 {
+		t.Fatalf("Expected 3 actions, got %+v", plan.Actions)
+	}
+	if a := ids["cpu_governor:cpu0"]; a.Target != testGovernorPath || a.From != "powersave" || a.To != "performance" {
+		t.Errorf("Unexpected governor action: %+v", a)
+	}
+	if a := ids["kernel_module:br_netfilter"]; a.Kind != ActionLoadModule || a.To != moduleLoaded {
+		t.Errorf("Unexpected module action: %+v", a)
+	}
+	if a := ids["timezone"]; a.From != "UTC" || a.To != nodeProbeTimezone {
+		t.Errorf("Unexpected timezone action: %+v", a)
+	}
+
+	// 生成计划不应修改节点
+	if _, ok := executor.Written(testGovernorPath); ok {
+		t.Error("Expected Plan not to write any file")
+	}
+}
+
+func TestRemediatorApplyAndRollback(t *testing.T) {
+	ctx := context.Background()
+	executor := newRemediationFakeExecutor()
+	executor.SetFile("/proc/sys/vm/swappiness", "60\n")
+
+	plan := &RemediationPlan{
+		Node: "node-a",
+		Actions: []RemediationAction{
+			{ID: "cpu_governor:cpu0", Kind: ActionWriteFile, Target: testGovernorPath, From: "powersave", To: "performance"},
+			// 计划中的原值已过期，应以执行时读取到的值为准
+			{ID: "swappiness", Kind: ActionWriteFile, Target: "/proc/sys/vm/swappiness", From: "30", To: "10"},
+			{ID: "kernel_module:br_netfilter", Kind: ActionLoadModule, Target: "br_netfilter", From: moduleUnloaded, To: moduleLoaded},
+			{ID: "kernel_module:nf_conntrack", Kind: ActionLoadModule, Target: "nf_conntrack", From: moduleUnloaded, To: moduleLoaded},
+		},
+	}
+
+	remediator := NewRemediator(executor)
+	journal, err := remediator.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if journal.Target != "node-a" || !journal.Changed() {
+		t.Errorf("Unexpected journal: %+v", journal)
+	}
+
+	wantStatus := []string{JournalApplied, JournalApplied, JournalApplied, JournalUnchanged}
+	for i, entry := range journal.Entries {
+		if entry.Status != wantStatus[i] {
+			t.Errorf("Entry %s: expected status %s, got %s (%s)", entry.Action.ID, wantStatus[i], entry.Status, entry.Error)
+		}
+	}
+	if from := journal.Entries[1].Action.From; from != "60" {
+		t.Errorf("Expected recorded original value 60, got %q", from)
+	}
+	if data, _ := executor.Written(testGovernorPath); string(data) != "performance\n" {
+		t.Errorf("Expected governor written, got %q", data)
+	}
+
+	// 从文件重新加载后回滚
+	path, err := SaveJournal(t.TempDir(), journal)
+	if err != nil {
+		t.Fatalf("SaveJournal failed: %v", err)
+	}
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("LoadJournal failed: %v", err)
+	}
+
+	if err := remediator.Rollback(ctx, loaded); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if data, _ := executor.Written(testGovernorPath); string(data) != "powersave\n" {
+		t.Errorf("Expected governor restored, got %q", data)
+	}
+	if data, _ := executor.Written("/proc/sys/vm/swappiness"); string(data) != "60\n" {
+		t.Errorf("Expected swappiness restored, got %q", data)
+	}
+	for _, entry := range loaded.Entries {
+		if entry.Status == JournalApplied || entry.Status == JournalRollbackFailed {
+			t.Errorf("Entry %s not rolled back: %s", entry.Action.ID, entry.Status)
+		}
+	}
+
+	calls := len(executor.Calls())
+	if err := remediator.Rollback(ctx, loaded); err != nil {
+		t.Fatalf("Second rollback failed: %v", err)
+	}
+	if len(executor.Calls()) != calls {
+		t.Error("Expected second rollback to be a no-op")
+	}
+}
+
+func TestRemediatorApplyFailure(t *testing.T) {
+	executor := NewFakeExecutor("node-a")
+	executor.SetCommand("lsmod", "Module                  Size  Used by\n")
+
+	plan := &RemediationPlan{
+		Node: "node-a",
+		Actions: []RemediationAction{
+			{ID: "missing", Kind: ActionWriteFile, Target: "/proc/sys/missing", To: "1"},
+			{ID: "kernel_module:br_netfilter", Kind: ActionLoadModule, Target: "br_netfilter", To: moduleLoaded},
+		},
+	}
+
+	journal, err := NewRemediator(executor).Apply(context.Background(), plan)
+	if err == nil {
+		t.Fatal("Expected error for failed actions")
+	}
+	if len(journal.Entries) != 2 || journal.Changed() {
+		t.Fatalf("Unexpected journal: %+v", journal)
+	}
+	for _, entry := range journal.Entries {
+		if entry.Status != JournalFailed || entry.Error == "" {
+			t.Errorf("Expected failed entry with error, got %+v", entry)
+		}
+	}
+}
+
+// journalProbeExecutor 在每次写入节点前回调，用于检查此时磁盘上的变更日志
+type journalProbeExecutor struct {
+	*FakeExecutor
+	beforeWrite func(path string)
+}
+
+func (e *journalProbeExecutor) WriteFile(ctx context.Context, path string, data []byte) error {
+	e.beforeWrite(path)
+	return e.FakeExecutor.WriteFile(ctx, path, data)
+}
+
+func TestRemediatorJournalWrittenBeforeChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	executor := &journalProbeExecutor{FakeExecutor: newRemediationFakeExecutor()}
+	executor.SetFile("/proc/sys/vm/swappiness", "60\n")
+
+	plan := &RemediationPlan{
+		Node: "node-a",
+		Actions: []RemediationAction{
+			{ID: "cpu_governor:cpu0", Kind: ActionWriteFile, Target: testGovernorPath, To: "performance"},
+			{ID: "swappiness", Kind: ActionWriteFile, Target: "/proc/sys/vm/swappiness", To: "10"},
+		},
+	}
+
+	// 每次修改节点时，日志中都应已有该项的原值，且之前的修改已记为 applied
+	writes := 0
+	executor.beforeWrite = func(path string) {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		if len(files) != 1 {
+			t.Fatalf("Expected journal on disk before writing %s, got %v", path, files)
+		}
+		journal, err := LoadJournal(files[0])
+		if err != nil {
+			t.Fatalf("LoadJournal failed: %v", err)
+		}
+		if len(journal.Entries) != writes+1 {
+			t.Fatalf("Expected %d journal entries before writing %s, got %+v", writes+1, path, journal.Entries)
+		}
+		entry := journal.Entries[writes]
+		if entry.Status != JournalPending || entry.Action.Target != path || entry.Action.From == "" {
+			t.Errorf("Expected pending entry with original value for %s, got %+v", path, entry)
+		}
+		if writes > 0 && journal.Entries[writes-1].Status != JournalApplied {
+			t.Errorf("Expected previous entry to be applied, got %+v", journal.Entries[writes-1])
+		}
+		writes++
+	}
+
+	remediator := NewRemediator(executor)
+	remediator.SetJournalDir(dir)
+	journal, err := remediator.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if writes != 2 {
+		t.Fatalf("Expected 2 writes, got %d", writes)
+	}
+
+	loaded, err := LoadJournal(journal.Path)
+	if err != nil {
+		t.Fatalf("LoadJournal failed: %v", err)
+	}
+	for _, entry := range loaded.Entries {
+		if entry.Status != JournalApplied {
+			t.Errorf("Expected final journal entry applied, got %+v", entry)
+		}
+	}
+	if _, err := os.Stat(journal.Path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected temporary journal file to be renamed, got %v", err)
+	}
+
+	// 中断时停留在 pending 的条目也会被回滚
+	loaded.Entries[1].Status = JournalPending
+	executor.beforeWrite = func(string) {}
+	if err := remediator.Rollback(ctx, loaded); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if data, _ := executor.Written("/proc/sys/vm/swappiness"); string(data) != "60\n" {
+		t.Errorf("Expected pending swappiness change rolled back, got %q", data)
+	}
+}
+
+func TestRemediatorJournalNotSaved(t *testing.T) {
+	// 日志目录的父路径是普通文件，无法创建目录
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	executor := newRemediationFakeExecutor()
+	plan := &RemediationPlan{
+		Node: "node-a",
+		Actions: []RemediationAction{
+			{ID: "cpu_governor:cpu0", Kind: ActionWriteFile, Target: testGovernorPath, To: "performance"},
+		},
+	}
+
+	remediator := NewRemediator(executor)
+	remediator.SetJournalDir(filepath.Join(parent, "journal"))
+	if _, err := remediator.Apply(context.Background(), plan); !errors.Is(err, ErrJournalNotSaved) {
+		t.Fatalf("Expected ErrJournalNotSaved, got %v", err)
+	}
+	if _, ok := executor.Written(testGovernorPath); ok {
+		t.Error("Expected no changes when the journal cannot be saved")
+	}
+}
+
+func TestParseSelected(t *testing.T) {
+	tests := []struct {
+		input    string
+		selected string
+		options  int
+	}{
+		{"mq-deadline kyber [bfq] none", "bfq", 4},
+		{"[none] mq-deadline", "none", 2},
+		{"none", "none", 0},
+		{"noop deadline [cfq]", "cfq", 3},
+		{"always [madvise] never\n", "madvise", 3},
+		{"always defer defer+madvise [madvise] never", "madvise", 5},
+		{"performance\n", "performance", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		selected, options := parseSelected(tt.input)
+		if selected != tt.selected || len(options) != tt.options {
+			t.Errorf("parseSelected(%q) = %q, %v", tt.input, selected, options)
+		}
+	}
+}
+
+func TestRemediatorJournalUniquePerApply(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	executor := newRemediationFakeExecutor()
+	plan := &RemediationPlan{
+		Node: "node-a",
+		Actions: []RemediationAction{
+			{ID: "cpu_governor:cpu0", Kind: ActionWriteFile, Target: testGovernorPath, To: "performance"},
+		},
+	}
+
+	// 同一秒内对同一节点执行两次（如重试），第二次不能覆盖第一次记录的原值
+	remediator := NewRemediator(executor)
+	remediator.SetJournalDir(dir)
+	first, err := remediator.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("First Apply failed: %v", err)
+	}
+	second, err := remediator.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Second Apply failed: %v", err)
+	}
+	if first.Path == second.Path {
+		t.Fatalf("Expected distinct journal files, both wrote %s", first.Path)
+	}
+
+	loaded, err := LoadJournal(first.Path)
+	if err != nil {
+		t.Fatalf("LoadJournal failed: %v", err)
+	}
+	if from := loaded.Entries[0].Action.From; from != "powersave" || loaded.Entries[0].Status != JournalApplied {
+		t.Errorf("Expected first journal to keep original value powersave, got %+v", loaded.Entries[0])
+	}
+
+	// 创建时间相同的日志也保存为不同文件
+	journal := &Journal{Node: "node-a", CreatedAt: first.CreatedAt}
+	a, err := SaveJournal(dir, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SaveJournal(dir, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("Expected SaveJournal to create a new file each time, got %s twice", a)
+	}
+}
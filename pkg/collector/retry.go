@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// 节点采集结果状态
+const (
+	NodeStatusOK      = "ok"
+	NodeStatusFailed  = "failed"
+	NodeStatusTimeout = "timeout"
+)
+
+// 节点采集的默认超时和重试策略
+const (
+	DefaultNodeTimeout  = 2 * time.Minute
+	DefaultNodeRetries  = 2
+	DefaultRetryBackoff = time.Second
+	DefaultMaxBackoff   = 30 * time.Second
+)
+
+// RetryPolicy 节点采集的超时和重试策略
+type RetryPolicy struct {
+	Timeout    time.Duration // 单次尝试的超时时间，0 表示只受整体上下文约束
+	Retries    int           // 首次失败后的最大重试次数，仅对临时性错误重试
+	Backoff    time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoff time.Duration // 重试等待时间上限
+}
+
+// DefaultRetryPolicy 返回默认的节点采集策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Timeout:    DefaultNodeTimeout,
+		Retries:    DefaultNodeRetries,
+		Backoff:    DefaultRetryBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// normalize 修正非法值
+func (p RetryPolicy) normalize() RetryPolicy {
+	if p.Timeout < 0 {
+		p.Timeout = 0
+	}
+	if p.Retries < 0 {
+		p.Retries = 0
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRetryBackoff
+	}
+	if p.MaxBackoff < p.Backoff {
+		p.MaxBackoff = p.Backoff
+	}
+	return p
+}
+
+// backoff 返回第 retry 次重试（从 1 开始）前的等待时间
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// NodeAttempt 一次节点采集尝试的记录
+type NodeAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Retryable  bool      `json:"retryable,omitempty"`
+}
+
+// collectWithRetry 按策略多次尝试采集，返回最后一次的结果、尝试记录和最终状态
+// 每次尝试在独立 goroutine 中运行，超时后立即返回，不等待不响应上下文的采集逻辑；
+// 节点采集（SystemInfo）和注册表中的采集器（Data）共用这一重试逻辑
+func collectWithRetry[T any](ctx context.Context, p RetryPolicy, collect func(ctx context.Context) (T, error)) (T, []NodeAttempt, string, error) {
+	p = p.normalize()
+
+	var (
+		attempts []NodeAttempt
+		info     T
+		err      error
+		status   string
+	)
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		info, err = collectAttempt(ctx, p.Timeout, collect)
+
+		status = NodeStatusOK
+		if err != nil {
+			status = NodeStatusFailed
+			if isTimeout(err) {
+				status = NodeStatusTimeout
+			}
+		}
+		record := NodeAttempt{
+			Attempt:    attempt,
+			StartedAt:  start,
+			DurationMs: time.Since(start).Milliseconds(),
+			Status:     status,
+		}
+		if err != nil {
+			record.Error = err.Error()
+			record.Retryable = isTransient(err)
+		}
+		attempts = append(attempts, record)
+
+		if err == nil || !record.Retryable || attempt > p.Retries || ctx.Err() != nil {
+			return info, attempts, status, err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return info, attempts, status, err
+		}
+	}
+}
+
+// collectAttempt 在超时限制内运行一次采集
+func collectAttempt[T any](ctx context.Context, timeout time.Duration, collect func(ctx context.Context) (T, error)) (T, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		info T
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		info, err := collect(ctx)
+		done <- result{info: info, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		// 本地采集在超时后仍会返回部分结果，按超时处理
+		if r.err == nil && ctx.Err() != nil {
+			return r.info, ctx.Err()
+		}
+		return r.info, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// isTimeout 判断错误是否由超时引起
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCommandTimeout) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isTransient 判断错误是否为值得重试的临时性网络错误
+// 认证失败、主机密钥不匹配、缺少配置等错误重试也不会成功，不重试
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var (
+		keyErr     *knownhosts.KeyError
+		revokedErr *knownhosts.RevokedError
+	)
+	if errors.As(err, &keyErr) || errors.As(err, &revokedErr) {
+		return false
+	}
+	msg := err.Error()
+	for _, permanent := range []string{"unable to authenticate", "no supported methods remain", "ssh config is not set", "no ssh configuration"} {
+		if strings.Contains(msg, permanent) {
+			return false
+		}
+	}
+
+	if isTimeout(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED,
+		syscall.EHOSTUNREACH, syscall.ENETUNREACH, syscall.EPIPE} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCollectWithRetryTransient(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{Timeout: time.Second, Retries: 3, Backoff: time.Millisecond}
+	info, attempts, status, err := collectWithRetry(context.Background(), policy, func(ctx context.Context) (*SystemInfo, error) {
+		calls++
+		if calls < 3 {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+		}
+		return &SystemInfo{Hostname: "node-a"}, nil
+	})
+	if err != nil || info == nil || info.Hostname != "node-a" {
+		t.Fatalf("Expected success after retries, got %+v, %v", info, err)
+	}
+	if status != NodeStatusOK || len(attempts) != 3 {
+		t.Fatalf("Expected 3 attempts ending ok, got %s %+v", status, attempts)
+	}
+	if attempts[0].Status != NodeStatusFailed || !attempts[0].Retryable || attempts[0].Error == "" {
+		t.Errorf("Expected first attempt logged as retryable failure, got %+v", attempts[0])
+	}
+}
+
+func TestCollectWithRetryPermanent(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{Retries: 3, Backoff: time.Millisecond}
+	_, attempts, status, err := collectWithRetry(context.Background(), policy, func(ctx context.Context) (*SystemInfo, error) {
+		calls++
+		return nil, fmt.Errorf("failed to connect to node-a: %w",
+			errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey]"))
+	})
+	if err == nil || status != NodeStatusFailed {
+		t.Fatalf("Expected failure, got %s %v", status, err)
+	}
+	if calls != 1 || len(attempts) != 1 || attempts[0].Retryable {
+		t.Errorf("Expected auth failure not to be retried, got %d calls %+v", calls, attempts)
+	}
+}
+
+func TestCollectWithRetryTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	policy := RetryPolicy{Timeout: 20 * time.Millisecond, Retries: 1, Backoff: time.Millisecond}
+	start := time.Now()
+	_, attempts, status, err := collectWithRetry(context.Background(), policy, func(ctx context.Context) (*SystemInfo, error) {
+		// 模拟不响应上下文的采集逻辑
+		<-block
+		return &SystemInfo{}, nil
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected attempts to be abandoned after timeout, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || status != NodeStatusTimeout {
+		t.Fatalf("Expected timeout status, got %s %v", status, err)
+	}
+	if len(attempts) != 2 || attempts[1].Status != NodeStatusTimeout {
+		t.Errorf("Expected timed out attempt to be retried once, got %+v", attempts)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}.normalize()
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 运行目录中的文件
+const (
+	RunManifestFile = "manifest.json"
+	RunResultsFile  = "results.ndjson"
+)
+
+// RunManifestVersion 运行清单格式版本
+const RunManifestVersion = 1
+
+// NodeStatusPending 尚未完成采集的节点
+const NodeStatusPending = "pending"
+
+// RunNode 运行清单中一个节点的状态
+type RunNode struct {
+	Node      string    `json:"node"`
+	Status    string    `json:"status"` // pending、ok、failed 或 timeout
+	Attempts  int       `json:"attempts"`
+	Runs      int       `json:"runs"` // 参与过的运行（含续跑）次数
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// RunManifest 集群采集运行清单，记录每个节点的采集状态，用于中断后续跑
+type RunManifest struct {
+	Version   int       `json:"version"`
+	RunID     string    `json:"run_id"`
+	Cluster   string    `json:"cluster,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Resumes   int       `json:"resumes"`
+	Nodes     []RunNode `json:"nodes"`
+}
+
+// Counts 按状态统计节点数
+func (m *RunManifest) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, n := range m.Nodes {
+		counts[n.Status]++
+	}
+	return counts
+}
+
+// Run 一次可续跑的集群采集
+// 每个节点完成后立即把结果追加到 results.ndjson 并原子地更新 manifest.json，
+// 进程中断时已完成的节点不会丢失，续跑只处理未成功的节点
+type Run struct {
+	mu       sync.Mutex
+	dir      string
+	manifest *RunManifest
+	index    map[string]int
+	results  *os.File
+	writer   *NDJSONWriter
+}
+
+// CreateRun 在 dir 中创建新的运行，dir 中已有运行清单时返回错误
+func CreateRun(dir, cluster string, nodes []string) (*Run, error) {
+	if _, err := os.Stat(filepath.Join(dir, RunManifestFile)); err == nil {
+		return nil, fmt.Errorf("run directory %s already contains a manifest, resume it instead", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	id, err := newRunID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	manifest := &RunManifest{
+		Version:   RunManifestVersion,
+		RunID:     id,
+		Cluster:   cluster,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		manifest.Nodes = append(manifest.Nodes, RunNode{Node: node, Status: NodeStatusPending})
+	}
+
+	return openRun(dir, manifest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+}
+
+// OpenRun 打开已有的运行用于续跑
+// 结果文件末尾因中断而不完整的记录会被截掉，新结果追加在其后
+func OpenRun(dir string) (*Run, error) {
+	manifest, err := LoadRunManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := truncatePartialRecord(filepath.Join(dir, RunResultsFile)); err != nil {
+		return nil, err
+	}
+	manifest.Resumes++
+	return openRun(dir, manifest, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+}
+
+// openRun 打开结果文件并写出初始清单
+func openRun(dir string, manifest *RunManifest, flag int) (*Run, error) {
+	results, err := os.OpenFile(filepath.Join(dir, RunResultsFile), flag, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run results: %w", err)
+	}
+
+	r := &Run{
+		dir:      dir,
+		manifest: manifest,
+		index:    make(map[string]int, len(manifest.Nodes)),
+		results:  results,
+		writer:   NewNDJSONWriter(results),
+	}
+	for i, n := range manifest.Nodes {
+		r.index[n.Node] = i
+	}
+	if err := r.saveManifest(); err != nil {
+		results.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadRunManifest 读取运行目录中的清单
+func LoadRunManifest(dir string) (*RunManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RunManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run manifest: %w", err)
+	}
+
+	var manifest RunManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse run manifest: %w", err)
+	}
+	if manifest.Version > RunManifestVersion {
+		return nil, fmt.Errorf("unsupported run manifest version %d", manifest.Version)
+	}
+	return &manifest, nil
+}
+
+// ID 返回运行标识
+func (r *Run) ID() string {
+	return r.manifest.RunID
+}
+
+// Dir 返回运行目录
+func (r *Run) Dir() string {
+	return r.dir
+}
+
+// ResultsPath 返回结果文件路径
+func (r *Run) ResultsPath() string {
+	return filepath.Join(r.dir, RunResultsFile)
+}
+
+// Manifest 返回当前清单的副本
+func (r *Run) Manifest() RunManifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := *r.manifest
+	m.Nodes = append([]RunNode(nil), r.manifest.Nodes...)
+	return m
+}
+
+// Pending 返回尚未成功的节点，按清单顺序
+func (r *Run) Pending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var nodes []string
+	for _, n := range r.manifest.Nodes {
+		if n.Status != NodeStatusOK {
+			nodes = append(nodes, n.Node)
+		}
+	}
+	return nodes
+}
+
+// Record 追加一个节点的采集结果并更新清单，可作为 CollectStream 的 sink
+func (r *Run) Record(info *SystemInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index[info.Node]
+	if !ok {
+		return fmt.Errorf("node %s is not part of run %s", info.Node, r.manifest.RunID)
+	}
+
+	info.RunID = r.manifest.RunID
+	if err := r.writer.Write(info); err != nil {
+		return err
+	}
+
+	node := &r.manifest.Nodes[i]
+	node.Status = info.Status
+	if node.Status == "" {
+		node.Status = NodeStatusOK
+		if info.CollectError != "" {
+			node.Status = NodeStatusFailed
+		}
+	}
+	node.Error = info.CollectError
+	node.Attempts += len(info.Attempts)
+	node.Runs++
+	node.UpdatedAt = time.Now()
+	return r.saveManifest()
+}
+
+// Close 关闭结果文件
+func (r *Run) Close() error {
+	return r.results.Close()
+}
+
+// saveManifest 先写临时文件再重命名，保证清单在任何时刻都是完整的
+func (r *Run) saveManifest() error {
+	r.manifest.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run manifest: %w", err)
+	}
+
+	path := filepath.Join(r.dir, RunManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	return nil
+}
+
+// truncatePartialRecord 截掉文件末尾没有换行结尾的不完整记录
+func truncatePartialRecord(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open run results: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// 从末尾向前查找最后一个换行
+	const chunk = 64 << 10
+	end := info.Size()
+	for end > 0 {
+		start := end - chunk
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, end-start)
+		if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read run results: %w", err)
+		}
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+	if end == info.Size() {
+		return nil
+	}
+	if err := f.Truncate(end); err != nil {
+		return fmt.Errorf("failed to truncate partial record: %w", err)
+	}
+	return nil
+}
+
+// newRunID 生成运行标识：创建时间加随机后缀
+func newRunID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate run id: %w", err)
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b), nil
+}
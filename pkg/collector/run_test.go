@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRecordAndResume(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	run, err := CreateRun(dir, "prod", []string{"node-a", "node-b", "node-c", "node-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(run.Pending(), ","); got != "node-a,node-b,node-c" {
+		t.Fatalf("Expected all nodes pending, got %s", got)
+	}
+
+	if err := run.Record(&SystemInfo{Node: "node-a", Status: NodeStatusOK, Attempts: []NodeAttempt{{Attempt: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Record(&SystemInfo{Node: "node-b", Status: NodeStatusTimeout, CollectError: "deadline exceeded"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Record(&SystemInfo{Node: "node-x"}); err == nil {
+		t.Error("Expected error recording a node outside the run")
+	}
+	id := run.ID()
+	run.Close()
+
+	// 模拟进程在写入 node-c 时被中断
+	f, err := os.OpenFile(filepath.Join(dir, RunResultsFile), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"node":"node-c","host`)
+	f.Close()
+
+	if _, err := CreateRun(dir, "prod", []string{"node-a"}); err == nil {
+		t.Error("Expected CreateRun to refuse an existing run directory")
+	}
+
+	run, err = OpenRun(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.ID() != id {
+		t.Errorf("Expected run id %s to survive resume, got %s", id, run.ID())
+	}
+	if got := strings.Join(run.Pending(), ","); got != "node-b,node-c" {
+		t.Fatalf("Expected only unsuccessful nodes pending, got %s", got)
+	}
+	if err := run.Record(&SystemInfo{Node: "node-b", Status: NodeStatusOK}); err != nil {
+		t.Fatal(err)
+	}
+	run.Close()
+
+	manifest, err := LoadRunManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Resumes != 1 || manifest.Cluster != "prod" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	counts := manifest.Counts()
+	if counts[NodeStatusOK] != 2 || counts[NodeStatusPending] != 1 {
+		t.Errorf("Unexpected status counts: %v", counts)
+	}
+	if b := manifest.Nodes[1]; b.Runs != 2 || b.Error != "" {
+		t.Errorf("Expected node-b to record both runs and clear its error, got %+v", b)
+	}
+
+	// 截断的记录已被清除，结果文件可以完整读取
+	results, err := os.Open(filepath.Join(dir, RunResultsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer results.Close()
+	r := NewNDJSONReader(results)
+	var nodes []string
+	for {
+		var info SystemInfo
+		err := r.Next(&info)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.RunID != id {
+			t.Errorf("Expected record tagged with run id, got %q", info.RunID)
+		}
+		nodes = append(nodes, info.Node)
+	}
+	if got := strings.Join(nodes, ","); got != "node-a,node-b,node-b" {
+		t.Errorf("Unexpected results: %s", got)
+	}
+}
@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// SMBIOS 结构类型
+const (
+	smbiosTypeBIOS         = 0
+	smbiosTypeSystem       = 1
+	smbiosTypeBoard        = 2
+	smbiosTypeChassis      = 3
+	smbiosTypeMemoryDevice = 17
+	smbiosTypeEndOfTable   = 127
+)
+
+// smbiosMemoryTypes SMBIOS 类型 17 的 Memory Type 字段取值
+var smbiosMemoryTypes = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "DRAM",
+	0x07: "RAM",
+	0x0F: "SDRAM",
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x14: "DDR2 FB-DIMM",
+	0x18: "DDR3",
+	0x1A: "DDR4",
+	0x1B: "LPDDR",
+	0x1C: "LPDDR2",
+	0x1D: "LPDDR3",
+	0x1E: "LPDDR4",
+	0x1F: "Logical non-volatile device",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
+
+// smbiosChassisTypes SMBIOS 类型 3 的 Chassis Type 字段取值（常见服务器机箱）
+var smbiosChassisTypes = map[byte]string{
+	0x01: "Other",
+	0x02: "Unknown",
+	0x03: "Desktop",
+	0x07: "Tower",
+	0x09: "Laptop",
+	0x0A: "Notebook",
+	0x11: "Main Server Chassis",
+	0x17: "Rack Mount Chassis",
+	0x18: "Sealed-case PC",
+	0x19: "Multi-system",
+	0x1C: "Blade",
+	0x1D: "Blade Enclosure",
+	0x23: "Mini PC",
+}
+
+// smbiosStructure SMBIOS 表中的一个结构
+type smbiosStructure struct {
+	Type      byte
+	Handle    uint16
+	Formatted []byte   // 包含 4 字节头部的格式化区域
+	Strings   []string // 字符串区，索引从 1 开始引用
+}
+
+// byteAt 返回格式化区域中 offset 处的字节，超出结构长度时返回 false
+func (s *smbiosStructure) byteAt(offset int) (byte, bool) {
+	if offset >= len(s.Formatted) {
+		return 0, false
+	}
+	return s.Formatted[offset], true
+}
+
+// wordAt 返回 offset 处的小端 16 位整数
+func (s *smbiosStructure) wordAt(offset int) (uint16, bool) {
+	if offset+2 > len(s.Formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(s.Formatted[offset:]), true
+}
+
+// dwordAt 返回 offset 处的小端 32 位整数
+func (s *smbiosStructure) dwordAt(offset int) (uint32, bool) {
+	if offset+4 > len(s.Formatted) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(s.Formatted[offset:]), true
+}
+
+// stringAt 返回 offset 处字符串索引引用的字符串
+func (s *smbiosStructure) stringAt(offset int) string {
+	index, ok := s.byteAt(offset)
+	if !ok || index == 0 || int(index) > len(s.Strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.Strings[index-1])
+}
+
+// parseSMBIOSTable 解析 /sys/firmware/dmi/tables/DMI 中的结构表
+// 表在中途损坏时返回已解析的结构和错误
+func parseSMBIOSTable(data []byte) ([]smbiosStructure, error) {
+	var structures []smbiosStructure
+	offset := 0
+	for offset+4 <= len(data) {
+		length := int(data[offset+1])
+		if length < 4 || offset+length > len(data) {
+			return structures, fmt.Errorf("invalid structure length %d at offset %d", length, offset)
+		}
+		s := smbiosStructure{
+			Type:      data[offset],
+			Handle:    binary.LittleEndian.Uint16(data[offset+2:]),
+			Formatted: data[offset : offset+length],
+		}
+
+		// 字符串区由若干以 NUL 结尾的字符串组成，以额外的 NUL 结束
+		pos := offset + length
+		for {
+			end := pos
+			for end < len(data) && data[end] != 0 {
+				end++
+			}
+			if end >= len(data) {
+				return structures, fmt.Errorf("unterminated string set in structure type %d at offset %d", s.Type, offset)
+			}
+			if end == pos {
+				pos++
+				break
+			}
+			s.Strings = append(s.Strings, string(data[pos:end]))
+			pos = end + 1
+		}
+		// 没有字符串的结构以两个 NUL 结束
+		if len(s.Strings) == 0 && pos < len(data) && data[pos] == 0 {
+			pos++
+		}
+
+		if s.Type == smbiosTypeEndOfTable {
+			return structures, nil
+		}
+		structures = append(structures, s)
+		offset = pos
+	}
+	if offset < len(data) {
+		return structures, fmt.Errorf("truncated structure at offset %d", offset)
+	}
+	return structures, nil
+}
+
+// smbiosUUID 按 SMBIOS 2.6+ 的字节序格式化 UUID（前三段为小端）
+func smbiosUUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	allSame := true
+	for _, c := range b[1:] {
+		if c != b[0] {
+			allSame = false
+			break
+		}
+	}
+	if allSame && (b[0] == 0x00 || b[0] == 0xFF) {
+		return ""
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:4]), binary.LittleEndian.Uint16(b[4:6]), binary.LittleEndian.Uint16(b[6:8]), b[8:10], b[10:16])
+}
+
+// smbiosMemoryDevice 解析类型 17 Memory Device
+// 返回的 Size 为 0 表示插槽未安装内存
+func smbiosMemoryDevice(s *smbiosStructure) DIMM {
+	dimm := DIMM{
+		Locator:      s.stringAt(0x10),
+		BankLocator:  s.stringAt(0x11),
+		Manufacturer: s.stringAt(0x17),
+		Serial:       s.stringAt(0x18),
+		AssetTag:     s.stringAt(0x19),
+		PartNumber:   s.stringAt(0x1A),
+	}
+
+	if size, ok := s.wordAt(0x0C); ok && size != 0 && size != 0xFFFF {
+		switch {
+		case size == 0x7FFF:
+			// 32GB 及以上使用扩展大小字段，单位 MB
+			if ext, ok := s.dwordAt(0x1C); ok {
+				dimm.Size = uint64(ext&0x7FFFFFFF) << 20
+			}
+		case size&0x8000 != 0:
+			dimm.Size = uint64(size&0x7FFF) << 10
+		default:
+			dimm.Size = uint64(size) << 20
+		}
+	}
+
+	if t, ok := s.byteAt(0x12); ok {
+		dimm.Type = smbiosMemoryTypes[t]
+		if dimm.Type == "" {
+			dimm.Type = fmt.Sprintf("0x%02X", t)
+		}
+	}
+	if speed, ok := s.wordAt(0x15); ok {
+		dimm.SpeedMTs = uint32(speed)
+		if speed == 0xFFFF {
+			dimm.SpeedMTs, _ = s.dwordAt(0x54)
+		}
+	}
+	if speed, ok := s.wordAt(0x20); ok {
+		dimm.ConfiguredSpeedMTs = uint32(speed)
+		if speed == 0xFFFF {
+			dimm.ConfiguredSpeedMTs, _ = s.dwordAt(0x58)
+		}
+	}
+	if attr, ok := s.byteAt(0x1B); ok {
+		dimm.Rank = int(attr & 0x0F)
+	}
+
+	// 未安装的插槽常以 "NO DIMM"、"Unknown" 等占位
+	for _, field := range []*string{&dimm.Manufacturer, &dimm.Serial, &dimm.AssetTag, &dimm.PartNumber} {
+		switch strings.ToLower(*field) {
+		case "unknown", "not specified", "no dimm", "none", "[empty]":
+			*field = ""
+		}
+	}
+	return dimm
+}
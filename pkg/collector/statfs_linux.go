@@ -0,0 +1,26 @@
+//go:build linux
+
+package collector
+
+import "syscall"
+
+// statfs 通过 statfs(2) 获取文件系统容量和 inode 数量
+func statfs(path string) (FilesystemUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return FilesystemUsage{}, err
+	}
+
+	bsize := uint64(st.Frsize)
+	if bsize == 0 {
+		bsize = uint64(st.Bsize)
+	}
+
+	return FilesystemUsage{
+		Total:     st.Blocks * bsize,
+		Free:      st.Bfree * bsize,
+		Available: st.Bavail * bsize,
+		Files:     st.Files,
+		FilesFree: st.Ffree,
+	}, nil
+}
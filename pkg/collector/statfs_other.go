@@ -0,0 +1,13 @@
+//go:build !linux
+
+package collector
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// statfs 非 Linux 平台不支持
+func statfs(path string) (FilesystemUsage, error) {
+	return FilesystemUsage{}, fmt.Errorf("statfs is not supported on %s", runtime.GOOS)
+}
@@ -1,35 +1,42 @@
 package collector
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"net"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/devops-toolkit/clusterreport/pkg/parser"
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 // SystemInfo 系统信息
 type SystemInfo struct {
-	Hostname     string            `json:"hostname"`
-	OS           string            `json:"os"`
-	Kernel       string            `json:"kernel"`
-	CPUInfo      CPUInfo           `json:"cpu_info"`
-	MemoryInfo   MemoryInfo        `json:"memory_info"`
-	DiskInfo     []DiskInfo        `json:"disk_info"`
-	NetworkInfo  []NetworkInfo     `json:"network_info"`
-	LoadAverage  LoadAverage       `json:"load_average"`
-	Uptime       string            `json:"uptime"`
-	CollectedAt  time.Time         `json:"collected_at"`
-	CollectError string            `json:"collect_error,omitempty"`
-	Metadata     map[string]string `json:"metadata,omitempty"`
+	Node          string            `json:"node,omitempty"`   // CollectMultiple 中使用的节点名
+	RunID         string            `json:"run_id,omitempty"` // 可续跑采集的运行标识
+	Hostname      string            `json:"hostname"`
+	OS            string            `json:"os"`
+	Kernel        string            `json:"kernel"`
+	CPUInfo       CPUInfo           `json:"cpu_info"`
+	MemoryInfo    MemoryInfo        `json:"memory_info"`
+	DiskInfo      []DiskInfo        `json:"disk_info"`
+	NetworkInfo   []NetworkInfo     `json:"network_info"`
+	LoadAverage   LoadAverage       `json:"load_average"`
+	Uptime        string            `json:"uptime"`
+	CollectedAt   time.Time         `json:"collected_at"`
+	CollectError  string            `json:"collect_error,omitempty"`
+	Status        string            `json:"status,omitempty"`   // ok、failed 或 timeout，仅 CollectMultiple 设置
+	Attempts      []NodeAttempt     `json:"attempts,omitempty"` // 每次采集尝试的记录
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	ParseWarnings []string          `json:"parse_warnings,omitempty"`
 }
 
 // CPUInfo CPU 信息
@@ -52,13 +59,15 @@ type MemoryInfo struct {
 
 // DiskInfo 磁盘信息
 type DiskInfo struct {
-	Device     string  `json:"device"`
-	MountPoint string  `json:"mount_point"`
-	FSType     string  `json:"fs_type"`
-	Total      uint64  `json:"total"`
-	Used       uint64  `json:"used"`
-	Free       uint64  `json:"free"`
-	UsageRate  float64 `json:"usage_rate"`
+	Device         string  `json:"device"`
+	MountPoint     string  `json:"mount_point"`
+	FSType         string  `json:"fs_type"`
+	Total          uint64  `json:"total"`
+	Used           uint64  `json:"used"`
+	Free           uint64  `json:"free"`
+	UsageRate      float64 `json:"usage_rate"`
+	InodeUsageRate float64 `json:"inode_usage_rate"`
+	Stale          bool    `json:"stale,omitempty"`
 }
 
 // NetworkInfo 网络信息
@@ -78,20 +87,129 @@ type LoadAverage struct {
 	Load15 float64 `json:"load15"`
 }
 
+// SSHOptions SSH 连接选项
+type SSHOptions struct {
+	Username       string        // 登录用户名
+	KeyFile        string        // 私钥文件路径，支持 ~ 开头
+	Password       string        // 密码（可选，与私钥二选一或同时使用）
+	Port           int           // 默认端口，节点未显式指定端口时使用
+	Timeout        time.Duration // 建立连接超时
+	KnownHostsFile string        // known_hosts 文件路径，为空时使用 DefaultKnownHostsFile
+
+	// InsecureIgnoreHostKey 不校验主机密钥，连接可能被中间人劫持，仅用于测试环境
+	InsecureIgnoreHostKey bool
+}
+
+// DefaultSSHPort 默认 SSH 端口
+const DefaultSSHPort = 22
+
+// DefaultKnownHostsFile 默认的 known_hosts 文件
+const DefaultKnownHostsFile = "~/.ssh/known_hosts"
+
+// NewSSHClientConfig 根据 SSH 选项创建客户端配置
+func NewSSHClientConfig(opts SSHOptions) (*ssh.ClientConfig, error) {
+	if opts.Username == "" {
+		return nil, fmt.Errorf("ssh username is required")
+	}
+
+	var auths []ssh.AuthMethod
+	if opts.KeyFile != "" {
+		keyData, err := os.ReadFile(expandHome(opts.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh key %s: %w", opts.KeyFile, err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh key %s: %w", opts.KeyFile, err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		auths = append(auths, ssh.Password(opts.Password))
+	}
+	if len(auths) == 0 {
+		return nil, fmt.Errorf("no ssh auth method configured (ssh_key or password)")
+	}
+
+	// 默认校验主机密钥，只有显式设置 InsecureIgnoreHostKey 时才跳过
+	var hostKeyCallback ssh.HostKeyCallback
+	if opts.InsecureIgnoreHostKey {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	} else {
+		knownHostsFile := opts.KnownHostsFile
+		if knownHostsFile == "" {
+			knownHostsFile = DefaultKnownHostsFile
+		}
+		cb, err := knownhosts.New(expandHome(knownHostsFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts %s: %w", knownHostsFile, err)
+		}
+		hostKeyCallback = cb
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &ssh.ClientConfig{
+		User:            opts.Username,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// expandHome 展开路径开头的 ~
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
 // SystemCollector 系统信息采集器
 type SystemCollector struct {
-	config  Config
-	verbose bool
+	config      Config
+	verbose     bool
+	sshConfig   *ssh.ClientConfig
+	sshPort     int
+	execOptions ExecOptions
+	retry       RetryPolicy
 }
 
 // NewSystemCollector 创建系统采集器
 func NewSystemCollector(config Config, verbose bool) *SystemCollector {
 	return &SystemCollector{
-		config:  config,
-		verbose: verbose,
+		config:      config,
+		verbose:     verbose,
+		sshPort:     DefaultSSHPort,
+		execOptions: DefaultExecOptions(),
+		retry:       DefaultRetryPolicy(),
 	}
 }
 
+// SetSSHConfig 设置远程节点采集使用的 SSH 配置
+// port 为节点未显式指定端口（host:port）时使用的默认端口
+func (sc *SystemCollector) SetSSHConfig(sshConfig *ssh.ClientConfig, port int) {
+	sc.sshConfig = sshConfig
+	if port > 0 {
+		sc.sshPort = port
+	}
+}
+
+// SetExecOptions 设置命令执行选项（单条命令超时、输出上限）
+func (sc *SystemCollector) SetExecOptions(opts ExecOptions) {
+	sc.execOptions = opts.normalize()
+}
+
+// SetRetryPolicy 设置 CollectMultiple 中单个节点的超时和重试策略
+func (sc *SystemCollector) SetRetryPolicy(policy RetryPolicy) {
+	sc.retry = policy.normalize()
+}
+
 // CollectLocal 采集本地系统信息
 func (sc *SystemCollector) CollectLocal(ctx context.Context) (*SystemInfo, error) {
 	info := &SystemInfo{
@@ -108,97 +226,164 @@ func (sc *SystemCollector) CollectLocal(ctx context.Context) (*SystemInfo, error
 	info.OS = runtime.GOOS
 	info.Metadata["arch"] = runtime.GOARCH
 
+	sc.collectDetails(ctx, NewLocalExecutor(sc.execOptions), info)
+
+	return info, nil
+}
+
+// CollectRemote 采集远程系统信息
+// host 需为 host:port 形式，采集项与 CollectLocal 在 Linux 上保持一致
+func (sc *SystemCollector) CollectRemote(ctx context.Context, host string, sshConfig *ssh.ClientConfig) (*SystemInfo, error) {
+	executor, err := DialSSHExecutor(ctx, host, sshConfig, sc.execOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+	defer executor.Close()
+
+	return sc.CollectWithExecutor(ctx, executor)
+}
+
+// CollectWithExecutor 通过指定执行器采集系统信息，用于远程节点或测试
+func (sc *SystemCollector) CollectWithExecutor(ctx context.Context, executor Executor) (*SystemInfo, error) {
+	info := &SystemInfo{
+		CollectedAt: time.Now(),
+		Metadata:    make(map[string]string),
+	}
+	info.Metadata["remote_host"] = executor.Target()
+
+	// 主机名是判断连接可用的基础信息，失败则视为整体采集失败
+	hostname, err := executor.Run(ctx, "hostname")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname from %s: %w", executor.Target(), err)
+	}
+	info.Hostname = strings.TrimSpace(hostname)
+
+	if output, err := executor.Run(ctx, "uname", "-s"); err == nil {
+		info.OS = strings.ToLower(strings.TrimSpace(output))
+	}
+	if output, err := executor.Run(ctx, "uname", "-m"); err == nil {
+		info.Metadata["arch"] = strings.TrimSpace(output)
+	}
+
+	sc.collectDetails(ctx, executor, info)
+
+	return info, ctx.Err()
+}
+
+// collectDetails 采集主机名和操作系统以外的各项信息，单项失败不影响其他项
+func (sc *SystemCollector) collectDetails(ctx context.Context, executor Executor, info *SystemInfo) {
+	var warnings parseWarnings
+	defer func() { info.ParseWarnings = warnings }()
+
 	// 获取内核版本
-	if kernel, err := sc.getKernelVersion(); err == nil {
+	if kernel, err := sc.getKernelVersion(ctx, executor); err == nil {
 		info.Kernel = kernel
 	}
 
 	// 获取 CPU 信息
-	if cpuInfo, err := sc.getCPUInfo(); err == nil {
+	if cpuInfo, err := sc.getCPUInfo(ctx, executor, info.OS); err == nil {
 		info.CPUInfo = cpuInfo
 	}
 
 	// 获取内存信息
-	if memInfo, err := sc.getMemoryInfo(); err == nil {
+	if memInfo, err := sc.getMemoryInfo(ctx, executor, info.OS, &warnings); err == nil {
 		info.MemoryInfo = memInfo
 	}
 
 	// 获取磁盘信息
-	if diskInfo, err := sc.getDiskInfo(); err == nil {
+	if diskInfo, err := sc.getDiskInfo(ctx, executor, info.OS); err == nil {
 		info.DiskInfo = diskInfo
 	}
 
 	// 获取网络信息
-	if netInfo, err := sc.getNetworkInfo(); err == nil {
+	if netInfo, err := sc.getNetworkInfo(ctx, executor, info.OS, &warnings); err == nil {
 		info.NetworkInfo = netInfo
 	}
 
 	// 获取系统负载
-	if load, err := sc.getLoadAverage(); err == nil {
+	if load, err := sc.getLoadAverage(ctx, executor, info.OS); err == nil {
 		info.LoadAverage = load
 	}
 
 	// 获取运行时间
-	if uptime, err := sc.getUptime(); err == nil {
+	if uptime, err := sc.getUptime(ctx, executor, info.OS); err == nil {
 		info.Uptime = uptime
 	}
-
-	return info, nil
 }
 
-// CollectRemote 采集远程系统信息
-func (sc *SystemCollector) CollectRemote(ctx context.Context, host string, sshConfig *ssh.ClientConfig) (*SystemInfo, error) {
-	client, err := ssh.Dial("tcp", host, sshConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+// dialSSH 在上下文约束下建立 SSH 连接
+func dialSSH(ctx context.Context, host string, sshConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	if sshConfig == nil {
+		return nil, fmt.Errorf("ssh config is not set")
 	}
-	defer client.Close()
 
-	info := &SystemInfo{
-		CollectedAt: time.Now(),
-		Metadata:    make(map[string]string),
+	dialer := net.Dialer{Timeout: sshConfig.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
 	}
-	info.Metadata["remote_host"] = host
 
-	// 执行远程命令获取系统信息
-	commands := map[string]*string{
-		"hostname":  &info.Hostname,
-		"uname -s":  &info.OS,
-		"uname -r":  &info.Kernel,
-		"uptime -p": &info.Uptime,
+	// 握手阶段同样受上下文截止时间约束
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
 	}
 
-	for cmd, target := range commands {
-		output, err := sc.executeRemoteCommand(client, cmd)
-		if err == nil {
-			*target = strings.TrimSpace(output)
-		}
+	c, chans, reqs, err := ssh.NewClientConn(conn, host, sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
+	conn.SetDeadline(time.Time{})
 
-	// 获取更详细的信息
-	if cpuInfo, err := sc.getRemoteCPUInfo(client); err == nil {
-		info.CPUInfo = cpuInfo
-	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
 
-	if memInfo, err := sc.getRemoteMemoryInfo(client); err == nil {
-		info.MemoryInfo = memInfo
+// remoteAddress 将节点名转换为 host:port，节点已带端口时保持不变
+func (sc *SystemCollector) remoteAddress(node string) string {
+	if _, _, err := net.SplitHostPort(node); err == nil {
+		return node
 	}
-
-	if diskInfo, err := sc.getRemoteDiskInfo(client); err == nil {
-		info.DiskInfo = diskInfo
+	port := sc.sshPort
+	if port <= 0 {
+		port = DefaultSSHPort
 	}
+	return net.JoinHostPort(node, fmt.Sprintf("%d", port))
+}
 
-	return info, nil
+// isLocalNode 判断节点是否为本机
+func isLocalNode(node string) bool {
+	return node == "localhost" || node == "127.0.0.1"
 }
 
 // CollectMultiple 并发采集多个节点
+// 每个节点按重试策略独立限时，超时的节点标记为 timeout，不影响其他节点
 func (sc *SystemCollector) CollectMultiple(ctx context.Context, nodes []string, parallel int) map[string]*SystemInfo {
 	results := make(map[string]*SystemInfo)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	sc.CollectStream(ctx, nodes, parallel, func(info *SystemInfo) error {
+		results[info.Node] = info
+		return nil
+	})
+	return results
+}
 
-	// 创建进度条
+// CollectStream 并发采集多个节点，每个节点完成后立即交给 sink，不在内存中保留结果
+// sink 串行调用；sink 返回错误时停止采集尚未开始的节点并返回该错误
+func (sc *SystemCollector) CollectStream(ctx context.Context, nodes []string, parallel int, sink func(info *SystemInfo) error) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sinkErr error
+	)
+
+	// 创建进度条，输出到标准错误，避免与标准输出上的结果混在一起
 	bar := progressbar.NewOptions(len(nodes),
+		progressbar.OptionSetWriter(os.Stderr),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(50),
@@ -222,22 +407,24 @@ func (sc *SystemCollector) CollectMultiple(ctx context.Context, nodes []string,
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			var info *SystemInfo
-			var err error
+			mu.Lock()
+			stopped := sinkErr != nil
+			mu.Unlock()
+			if stopped {
+				return
+			}
 
-			// 判断是本地还是远程
-			if n == "localhost" || n == "127.0.0.1" {
-				info, err = sc.CollectLocal(ctx)
-			} else {
-				// 远程采集（这里简化处理，实际需要 SSH 配置）
-				info = &SystemInfo{
-					Hostname:     n,
-					CollectedAt:  time.Now(),
-					CollectError: "Remote collection not fully implemented yet",
+			info, attempts, status, err := collectWithRetry(ctx, sc.retry, func(ctx context.Context) (*SystemInfo, error) {
+				// 判断是本地还是远程
+				if isLocalNode(n) {
+					return sc.CollectLocal(ctx)
 				}
-			}
+				if sc.sshConfig == nil {
+					return nil, fmt.Errorf("no ssh configuration for remote node %s", n)
+				}
+				return sc.CollectRemote(ctx, sc.remoteAddress(n), sc.sshConfig)
+			})
 
-			mu.Lock()
 			if err != nil {
 				info = &SystemInfo{
 					Hostname:     n,
@@ -245,7 +432,17 @@ func (sc *SystemCollector) CollectMultiple(ctx context.Context, nodes []string,
 					CollectError: err.Error(),
 				}
 			}
-			results[n] = info
+			info.Node = n
+			info.Status = status
+			info.Attempts = attempts
+
+			mu.Lock()
+			if sinkErr == nil {
+				if err := sink(info); err != nil {
+					sinkErr = err
+					cancel()
+				}
+			}
 			mu.Unlock()
 
 			bar.Add(1)
@@ -254,38 +451,40 @@ func (sc *SystemCollector) CollectMultiple(ctx context.Context, nodes []string,
 
 	wg.Wait()
 	bar.Finish()
-	fmt.Println()
+	fmt.Fprintln(os.Stderr)
 
-	return results
+	return sinkErr
 }
 
 // 辅助方法：获取内核版本
-func (sc *SystemCollector) getKernelVersion() (string, error) {
-	cmd := exec.Command("uname", "-r")
-	output, err := cmd.Output()
+func (sc *SystemCollector) getKernelVersion(ctx context.Context, executor Executor) (string, error) {
+	output, err := executor.Run(ctx, "uname", "-r")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
 // 辅助方法：获取 CPU 信息
-func (sc *SystemCollector) getCPUInfo() (CPUInfo, error) {
-	info := CPUInfo{
-		Cores:   runtime.NumCPU(),
-		Threads: runtime.NumCPU(),
+func (sc *SystemCollector) getCPUInfo(ctx context.Context, executor Executor, goos string) (CPUInfo, error) {
+	info := CPUInfo{}
+
+	// 本地采集直接使用运行时的核心数，远程采集使用 nproc
+	if isLocal(executor) {
+		info.Cores = runtime.NumCPU()
+	} else if output, err := executor.Run(ctx, "nproc"); err == nil {
+		fmt.Sscanf(strings.TrimSpace(output), "%d", &info.Cores)
 	}
+	info.Threads = info.Cores
 
-	// 获取 CPU 型号（Linux）
-	if runtime.GOOS == "linux" {
-		cmd := exec.Command("sh", "-c", "cat /proc/cpuinfo | grep 'model name' | head -1 | cut -d: -f2")
-		if output, err := cmd.Output(); err == nil {
-			info.Model = strings.TrimSpace(string(output))
+	// 获取 CPU 型号
+	if goos == "linux" {
+		if data, err := executor.ReadFile(ctx, "/proc/cpuinfo"); err == nil {
+			info.Model = parseCPUModel(string(data))
 		}
-	} else if runtime.GOOS == "darwin" {
-		cmd := exec.Command("sysctl", "-n", "machdep.cpu.brand_string")
-		if output, err := cmd.Output(); err == nil {
-			info.Model = strings.TrimSpace(string(output))
+	} else if goos == "darwin" {
+		if output, err := executor.Run(ctx, "sysctl", "-n", "machdep.cpu.brand_string"); err == nil {
+			info.Model = strings.TrimSpace(output)
 		}
 	}
 
@@ -293,30 +492,19 @@ func (sc *SystemCollector) getCPUInfo() (CPUInfo, error) {
 }
 
 // 辅助方法：获取内存信息
-func (sc *SystemCollector) getMemoryInfo() (MemoryInfo, error) {
+func (sc *SystemCollector) getMemoryInfo(ctx context.Context, executor Executor, goos string, warnings *parseWarnings) (MemoryInfo, error) {
 	info := MemoryInfo{}
 
-	if runtime.GOOS == "linux" {
-		cmd := exec.Command("sh", "-c", "free -b | grep Mem:")
-		output, err := cmd.Output()
+	if goos == "linux" {
+		output, err := executor.Run(ctx, "free", "-b")
 		if err != nil {
 			return info, err
 		}
 
-		fields := strings.Fields(string(output))
-		if len(fields) >= 7 {
-			fmt.Sscanf(fields[1], "%d", &info.Total)
-			fmt.Sscanf(fields[2], "%d", &info.Used)
-			fmt.Sscanf(fields[3], "%d", &info.Free)
-			fmt.Sscanf(fields[6], "%d", &info.Available)
-			if info.Total > 0 {
-				info.UsageRate = float64(info.Used) / float64(info.Total) * 100
-			}
-		}
-	} else if runtime.GOOS == "darwin" {
-		cmd := exec.Command("sysctl", "-n", "hw.memsize")
-		if output, err := cmd.Output(); err == nil {
-			fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &info.Total)
+		return parseFreeMemLine(output, warnings)
+	} else if goos == "darwin" {
+		if output, err := executor.Run(ctx, "sysctl", "-n", "hw.memsize"); err == nil {
+			fmt.Sscanf(strings.TrimSpace(output), "%d", &info.Total)
 		}
 	}
 
@@ -324,236 +512,168 @@ func (sc *SystemCollector) getMemoryInfo() (MemoryInfo, error) {
 }
 
 // 辅助方法：获取磁盘信息
-func (sc *SystemCollector) getDiskInfo() ([]DiskInfo, error) {
-	var disks []DiskInfo
-
-	cmd := exec.Command("df", "-B1")
-	output, err := cmd.Output()
-	if err != nil {
-		return disks, err
+// 与 MetricsCollector 相同，逐个挂载点 statfs 并限制等待时间，卡死的挂载不会阻塞整个节点
+func (sc *SystemCollector) getDiskInfo(ctx context.Context, executor Executor, goos string) ([]DiskInfo, error) {
+	if goos != "linux" {
+		return nil, nil
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for i, line := range lines {
-		if i == 0 || line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 6 {
-			disk := DiskInfo{
-				Device:     fields[0],
-				MountPoint: fields[5],
-			}
-
-			fmt.Sscanf(fields[1], "%d", &disk.Total)
-			fmt.Sscanf(fields[2], "%d", &disk.Used)
-			fmt.Sscanf(fields[3], "%d", &disk.Free)
-
-			if disk.Total > 0 {
-				disk.UsageRate = float64(disk.Used) / float64(disk.Total) * 100
-			}
-
-			disks = append(disks, disk)
-		}
+	stat := remoteStatfs(executor)
+	if isLocal(executor) {
+		stat = localStatfs(statfs)
+	}
+	usages, err := statMounts(ctx, executor, DefaultProcRoot, stat, DefaultMountTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	disks := make([]DiskInfo, 0, len(usages))
+	for _, u := range usages {
+		m := diskMetricsFromUsage(u)
+		disks = append(disks, DiskInfo{
+			Device:         m.Device,
+			MountPoint:     m.MountPoint,
+			FSType:         m.FSType,
+			Total:          m.Total,
+			Used:           m.Used,
+			Free:           m.Available,
+			UsageRate:      m.UsedPercent,
+			InodeUsageRate: m.InodesUsedPercent,
+			Stale:          m.Stale,
+		})
 	}
-
 	return disks, nil
 }
 
 // 辅助方法：获取网络信息
-func (sc *SystemCollector) getNetworkInfo() ([]NetworkInfo, error) {
+func (sc *SystemCollector) getNetworkInfo(ctx context.Context, executor Executor, goos string, warnings *parseWarnings) ([]NetworkInfo, error) {
 	var networks []NetworkInfo
 
-	if runtime.GOOS == "linux" {
-		cmd := exec.Command("ip", "-o", "addr", "show")
-		output, err := cmd.Output()
+	if goos == "linux" {
+		output, err := executor.Run(ctx, "ip", "-o", "addr", "show")
 		if err != nil {
 			return networks, err
 		}
 
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-
-			fields := strings.Fields(line)
-			if len(fields) >= 4 {
-				net := NetworkInfo{
-					Interface: fields[1],
-					Status:    "up",
-				}
-
-				// 提取 IP 地址
-				for i, field := range fields {
-					if field == "inet" && i+1 < len(fields) {
-						net.IPAddress = strings.Split(fields[i+1], "/")[0]
-						break
-					}
-				}
-
-				if net.IPAddress != "" {
-					networks = append(networks, net)
-				}
-			}
-		}
+		return parseIPAddrOutput(output, warnings)
 	}
 
 	return networks, nil
 }
 
 // 辅助方法：获取系统负载
-func (sc *SystemCollector) getLoadAverage() (LoadAverage, error) {
+func (sc *SystemCollector) getLoadAverage(ctx context.Context, executor Executor, goos string) (LoadAverage, error) {
 	load := LoadAverage{}
 
-	if runtime.GOOS == "linux" {
-		cmd := exec.Command("cat", "/proc/loadavg")
-		output, err := cmd.Output()
+	if goos == "linux" {
+		data, err := executor.ReadFile(ctx, "/proc/loadavg")
 		if err != nil {
 			return load, err
 		}
 
-		fields := strings.Fields(string(output))
-		if len(fields) >= 3 {
-			fmt.Sscanf(fields[0], "%f", &load.Load1)
-			fmt.Sscanf(fields[1], "%f", &load.Load5)
-			fmt.Sscanf(fields[2], "%f", &load.Load15)
-		}
-	} else if runtime.GOOS == "darwin" {
-		cmd := exec.Command("sysctl", "-n", "vm.loadavg")
-		output, err := cmd.Output()
+		load = parseLoadAvg(string(data))
+	} else if goos == "darwin" {
+		output, err := executor.Run(ctx, "sysctl", "-n", "vm.loadavg")
 		if err != nil {
 			return load, err
 		}
 
 		// macOS 格式: { 1.23 2.34 3.45 }
-		str := strings.Trim(string(output), "{} \n")
-		fields := strings.Fields(str)
-		if len(fields) >= 3 {
-			fmt.Sscanf(fields[0], "%f", &load.Load1)
-			fmt.Sscanf(fields[1], "%f", &load.Load5)
-			fmt.Sscanf(fields[2], "%f", &load.Load15)
-		}
+		load = parseLoadAvg(strings.Trim(output, "{} \n"))
 	}
 
 	return load, nil
 }
 
 // 辅助方法：获取运行时间
-func (sc *SystemCollector) getUptime() (string, error) {
-	var cmd *exec.Cmd
-
-	if runtime.GOOS == "linux" {
-		cmd = exec.Command("uptime", "-p")
-	} else if runtime.GOOS == "darwin" {
-		cmd = exec.Command("uptime")
+func (sc *SystemCollector) getUptime(ctx context.Context, executor Executor, goos string) (string, error) {
+	var output string
+	var err error
+
+	if goos == "linux" {
+		output, err = executor.Run(ctx, "uptime", "-p")
+	} else if goos == "darwin" {
+		output, err = executor.Run(ctx, "uptime")
 	} else {
 		return "", fmt.Errorf("unsupported OS")
 	}
 
-	output, err := cmd.Output()
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
-// 远程执行命令的辅助方法
-func (sc *SystemCollector) executeRemoteCommand(client *ssh.Client, command string) (string, error) {
-	session, err := client.NewSession()
-	if err != nil {
-		return "", err
-	}
-	defer session.Close()
-
-	var buf bytes.Buffer
-	session.Stdout = &buf
-
-	if err := session.Run(command); err != nil {
-		return "", err
-	}
-
-	return buf.String(), nil
-}
-
-// 获取远程 CPU 信息
-func (sc *SystemCollector) getRemoteCPUInfo(client *ssh.Client) (CPUInfo, error) {
-	info := CPUInfo{}
-
-	// 获取 CPU 核心数
-	if output, err := sc.executeRemoteCommand(client, "nproc"); err == nil {
-		fmt.Sscanf(strings.TrimSpace(output), "%d", &info.Cores)
-		info.Threads = info.Cores
-	}
-
-	// 获取 CPU 型号
-	if output, err := sc.executeRemoteCommand(client, "cat /proc/cpuinfo | grep 'model name' | head -1 | cut -d: -f2"); err == nil {
-		info.Model = strings.TrimSpace(output)
+// parseCPUModel 从 /proc/cpuinfo 中提取 CPU 型号
+// ARM 上没有 model name，按 CPU implementer/part 推断
+func parseCPUModel(cpuinfo string) string {
+	processors := parseCPUInfoBlocks(cpuinfo)
+	if len(processors) == 0 {
+		return ""
 	}
-
-	return info, nil
+	return cpuModel(processors[0])
 }
 
-// 获取远程内存信息
-func (sc *SystemCollector) getRemoteMemoryInfo(client *ssh.Client) (MemoryInfo, error) {
+// parseFreeMemLine 解析 `free -b` 输出中的 Mem: 行
+func parseFreeMemLine(output string, warnings *parseWarnings) (MemoryInfo, error) {
 	info := MemoryInfo{}
 
-	output, err := sc.executeRemoteCommand(client, "free -b | grep Mem:")
+	result, err := parser.ParseFree(output, 1)
 	if err != nil {
 		return info, err
 	}
+	warnings.add("free", result.Warnings...)
 
-	fields := strings.Fields(output)
-	if len(fields) >= 7 {
-		fmt.Sscanf(fields[1], "%d", &info.Total)
-		fmt.Sscanf(fields[2], "%d", &info.Used)
-		fmt.Sscanf(fields[3], "%d", &info.Free)
-		fmt.Sscanf(fields[6], "%d", &info.Available)
-		if info.Total > 0 {
-			info.UsageRate = float64(info.Used) / float64(info.Total) * 100
-		}
+	info.Total = result.Mem.Total
+	info.Used = result.Mem.Used
+	info.Free = result.Mem.Free
+	info.Available = result.Mem.Available
+	if info.Total > 0 {
+		info.UsageRate = float64(info.Used) / float64(info.Total) * 100
 	}
 
 	return info, nil
 }
 
-// 获取远程磁盘信息
-func (sc *SystemCollector) getRemoteDiskInfo(client *ssh.Client) ([]DiskInfo, error) {
-	var disks []DiskInfo
+// parseIPAddrOutput 解析 `ip -o addr show` 的输出，仅保留有 IPv4 地址的网卡
+func parseIPAddrOutput(output string, warnings *parseWarnings) ([]NetworkInfo, error) {
+	var networks []NetworkInfo
 
-	output, err := sc.executeRemoteCommand(client, "df -B1")
+	result, err := parser.ParseIPAddr(output)
 	if err != nil {
-		return disks, err
+		return nil, err
 	}
+	warnings.add("ip", result.Warnings...)
 
-	lines := strings.Split(output, "\n")
-	for i, line := range lines {
-		if i == 0 || line == "" {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 6 {
-			disk := DiskInfo{
-				Device:     fields[0],
-				MountPoint: fields[5],
+	for _, iface := range result.Interfaces {
+		for _, addr := range iface.Addresses {
+			if addr.Family != "inet" {
+				continue
 			}
+			networks = append(networks, NetworkInfo{
+				Interface: iface.Name,
+				IPAddress: addr.Address,
+				Status:    "up",
+			})
+		}
+	}
 
-			fmt.Sscanf(fields[1], "%d", &disk.Total)
-			fmt.Sscanf(fields[2], "%d", &disk.Used)
-			fmt.Sscanf(fields[3], "%d", &disk.Free)
+	return networks, nil
+}
 
-			if disk.Total > 0 {
-				disk.UsageRate = float64(disk.Used) / float64(disk.Total) * 100
-			}
+// parseLoadAvg 解析 /proc/loadavg 的内容
+func parseLoadAvg(output string) LoadAverage {
+	load := LoadAverage{}
 
-			disks = append(disks, disk)
-		}
+	fields := strings.Fields(output)
+	if len(fields) >= 3 {
+		fmt.Sscanf(fields[0], "%f", &load.Load1)
+		fmt.Sscanf(fields[1], "%f", &load.Load5)
+		fmt.Sscanf(fields[2], "%f", &load.Load15)
 	}
 
-	return disks, nil
+	return load
 }
 
 // PrintSummary 打印采集摘要
@@ -564,9 +684,13 @@ func (sc *SystemCollector) PrintSummary(results map[string]*SystemInfo) {
 
 	successCount := 0
 	failCount := 0
+	timeoutCount := 0
 
 	for node, info := range results {
-		if info.CollectError == "" {
+		if info.Status == NodeStatusTimeout {
+			timeoutCount++
+			color.Yellow("⏱ %s: timeout after %d attempt(s): %s", node, len(info.Attempts), info.CollectError)
+		} else if info.CollectError == "" {
 			successCount++
 			color.Green("✓ %s: %s (%s)", node, info.Hostname, info.OS)
 			if sc.verbose {
@@ -586,7 +710,7 @@ func (sc *SystemCollector) PrintSummary(results map[string]*SystemInfo) {
 	}
 
 	fmt.Println()
-	color.Cyan("Total: %d nodes, %d succeeded, %d failed",
-		len(results), successCount, failCount)
+	color.Cyan("Total: %d nodes, %d succeeded, %d failed, %d timed out",
+		len(results), successCount, failCount, timeoutCount)
 	fmt.Println()
 }
@@ -0,0 +1,405 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSSHServer 进程内 SSH 测试服务器，按命令返回预设输出
+type testSSHServer struct {
+	listener  net.Listener
+	config    *ssh.ServerConfig
+	hostKey   ssh.PublicKey
+	responses map[string]string
+
+	mu       sync.Mutex
+	commands []string
+}
+
+// newTestSSHServer 启动测试 SSH 服务器，仅允许 clientKey 以 user 身份登录
+func newTestSSHServer(t *testing.T, user string, clientKey ssh.PublicKey, responses map[string]string) *testSSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("host signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == user && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	s := &testSSHServer{
+		listener:  listener,
+		config:    config,
+		hostKey:   hostSigner.PublicKey(),
+		responses: responses,
+	}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+// Addr 返回服务器监听地址
+func (s *testSSHServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// KnownHosts 写入只包含该服务器主机密钥的 known_hosts 文件
+func (s *testSSHServer) KnownHosts(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.Addr())}, s.hostKey)
+	if err := os.WriteFile(path, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("write known_hosts: %v", err)
+	}
+	return path
+}
+
+// Commands 返回服务器收到的所有命令
+func (s *testSSHServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *testSSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+
+		// exec 请求负载为 uint32 长度 + 命令字符串
+		command := ""
+		if len(req.Payload) >= 4 {
+			n := binary.BigEndian.Uint32(req.Payload[:4])
+			if int(n) <= len(req.Payload)-4 {
+				command = string(req.Payload[4 : 4+n])
+			}
+		}
+		req.Reply(true, nil)
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		// 响应按去掉区域设置前缀后的命令查找
+		status := uint32(0)
+		if output, ok := s.responses[strings.TrimPrefix(command, "LC_ALL=C LANG=C ")]; ok {
+			channel.Write([]byte(output))
+		} else {
+			channel.Stderr().Write([]byte("command not found\n"))
+			status = 127
+		}
+
+		exit := make([]byte, 4)
+		binary.BigEndian.PutUint32(exit, status)
+		channel.SendRequest("exit-status", false, exit)
+		return
+	}
+}
+
+// newTestClientKey 生成客户端密钥并写入临时文件
+func newTestClientKey(t *testing.T) (ssh.PublicKey, string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("client public key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "test")
+	if err != nil {
+		t.Fatalf("marshal client key: %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("write client key: %v", err)
+	}
+
+	return sshPub, keyFile
+}
+
+// testRemoteResponses 模拟一台 Linux 节点的命令输出
+var testRemoteResponses = map[string]string{
+	"hostname":          "node-a\n",
+	"uname -s":          "Linux\n",
+	"uname -m":          "x86_64\n",
+	"uname -r":          "5.15.0-91-generic\n",
+	"uptime -p":         "up 3 days, 4 hours\n",
+	"nproc":             "8\n",
+	"cat /proc/cpuinfo": "processor\t: 0\nmodel name\t: Intel(R) Xeon(R) Gold 6230 CPU @ 2.10GHz\n",
+	"free -b": "               total        used        free      shared  buff/cache   available\n" +
+		"Mem:     16000000000  4000000000  8000000000   1000000  4000000000  12000000000\n" +
+		"Swap:     2000000000           0  2000000000\n",
+	"cat /proc/self/mountinfo": "22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n" +
+		"23 22 0:21 / /proc rw,nosuid shared:12 - proc proc rw\n",
+	"stat -f -c '%S %b %f %a %c %d' -- /": "4096 24414062 18310546 18310546 6000000 5400000\n",
+	"ip -o addr show": "1: lo    inet 127.0.0.1/8 scope host lo\\       valid_lft forever preferred_lft forever\n" +
+		"2: eth0    inet 10.0.0.5/24 brd 10.0.0.255 scope global eth0\\       valid_lft forever preferred_lft forever\n",
+	"cat /proc/loadavg": "0.50 0.40 0.30 1/200 12345\n",
+}
+
+func TestCollectMultipleRemote(t *testing.T) {
+	clientPub, keyFile := newTestClientKey(t)
+	server := newTestSSHServer(t, "admin", clientPub, testRemoteResponses)
+
+	sshConfig, err := NewSSHClientConfig(SSHOptions{
+		Username:       "admin",
+		KeyFile:        keyFile,
+		Timeout:        5 * time.Second,
+		KnownHostsFile: server.KnownHosts(t),
+	})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+
+	sc := NewSystemCollector(Config{}, false)
+	sc.SetSSHConfig(sshConfig, 22)
+
+	node := server.Addr()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results := sc.CollectMultiple(ctx, []string{node}, 2)
+
+	info, ok := results[node]
+	if !ok {
+		t.Fatalf("Expected result for node %s", node)
+	}
+	if info.CollectError != "" {
+		t.Fatalf("Expected no collect error, got %q", info.CollectError)
+	}
+
+	if info.Hostname != "node-a" {
+		t.Errorf("Expected hostname 'node-a', got '%s'", info.Hostname)
+	}
+	if info.OS != "linux" {
+		t.Errorf("Expected OS 'linux', got '%s'", info.OS)
+	}
+	if info.Kernel != "5.15.0-91-generic" {
+		t.Errorf("Expected kernel '5.15.0-91-generic', got '%s'", info.Kernel)
+	}
+	if info.Metadata["arch"] != "x86_64" {
+		t.Errorf("Expected arch 'x86_64', got '%s'", info.Metadata["arch"])
+	}
+	if info.CPUInfo.Cores != 8 {
+		t.Errorf("Expected 8 cores, got %d", info.CPUInfo.Cores)
+	}
+	if !strings.Contains(info.CPUInfo.Model, "Xeon") {
+		t.Errorf("Expected Xeon CPU model, got '%s'", info.CPUInfo.Model)
+	}
+	if info.MemoryInfo.Total != 16000000000 || info.MemoryInfo.UsageRate != 25 {
+		t.Errorf("Unexpected memory info: %+v", info.MemoryInfo)
+	}
+	if len(info.DiskInfo) != 1 || info.DiskInfo[0].MountPoint != "/" || info.DiskInfo[0].InodeUsageRate != 10 {
+		t.Errorf("Unexpected disk info: %+v", info.DiskInfo)
+	}
+	if len(info.NetworkInfo) != 2 || info.NetworkInfo[1].IPAddress != "10.0.0.5" {
+		t.Errorf("Unexpected network info: %+v", info.NetworkInfo)
+	}
+	if info.LoadAverage.Load1 != 0.5 {
+		t.Errorf("Expected load1 0.5, got %.2f", info.LoadAverage.Load1)
+	}
+	if info.Uptime != "up 3 days, 4 hours" {
+		t.Errorf("Unexpected uptime '%s'", info.Uptime)
+	}
+}
+
+func TestCollectMultipleRemoteAuthFailure(t *testing.T) {
+	clientPub, _ := newTestClientKey(t)
+	server := newTestSSHServer(t, "admin", clientPub, testRemoteResponses)
+
+	// 使用另一把未授权的密钥
+	_, otherKeyFile := newTestClientKey(t)
+	sshConfig, err := NewSSHClientConfig(SSHOptions{
+		Username:       "admin",
+		KeyFile:        otherKeyFile,
+		Timeout:        5 * time.Second,
+		KnownHostsFile: server.KnownHosts(t),
+	})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+
+	sc := NewSystemCollector(Config{}, false)
+	sc.SetSSHConfig(sshConfig, 22)
+
+	node := server.Addr()
+	results := sc.CollectMultiple(context.Background(), []string{node}, 1)
+
+	if results[node] == nil || results[node].CollectError == "" {
+		t.Fatal("Expected collect error for unauthorized key")
+	}
+	if info := results[node]; info.Status != NodeStatusFailed || len(info.Attempts) != 1 {
+		t.Errorf("Expected auth failure without retries, got %s %+v", info.Status, info.Attempts)
+	}
+}
+
+func TestCollectMultipleRemoteHostKey(t *testing.T) {
+	clientPub, keyFile := newTestClientKey(t)
+	server := newTestSSHServer(t, "admin", clientPub, testRemoteResponses)
+	other := newTestSSHServer(t, "admin", clientPub, testRemoteResponses)
+
+	if _, err := NewSSHClientConfig(SSHOptions{Username: "admin", KeyFile: keyFile,
+		KnownHostsFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Expected error for missing known_hosts file")
+	}
+
+	// known_hosts 中记录的是另一台主机的密钥，连接必须被拒绝且不重试
+	sshConfig, err := NewSSHClientConfig(SSHOptions{Username: "admin", KeyFile: keyFile, Timeout: 5 * time.Second,
+		KnownHostsFile: other.KnownHosts(t)})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+	sc := NewSystemCollector(Config{}, false)
+	sc.SetSSHConfig(sshConfig, 22)
+	node := server.Addr()
+	info := sc.CollectMultiple(context.Background(), []string{node}, 1)[node]
+	if info.Status != NodeStatusFailed || len(info.Attempts) != 1 || len(server.Commands()) != 0 {
+		t.Errorf("Expected unknown host key to be rejected without retries, got %s %+v", info.Status, info.Attempts)
+	}
+
+	sshConfig, err = NewSSHClientConfig(SSHOptions{Username: "admin", KeyFile: keyFile, Timeout: 5 * time.Second,
+		KnownHostsFile: filepath.Join(t.TempDir(), "missing"), InsecureIgnoreHostKey: true})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+	sc.SetSSHConfig(sshConfig, 22)
+	if info := sc.CollectMultiple(context.Background(), []string{node}, 1)[node]; info.Status != NodeStatusOK {
+		t.Errorf("Expected explicit insecure mode to skip host key checks, got %s %s", info.Status, info.CollectError)
+	}
+}
+
+func TestCollectMultipleSlowNodeTimeout(t *testing.T) {
+	// 接受连接但从不响应 SSH 握手的节点
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	clientPub, keyFile := newTestClientKey(t)
+	server := newTestSSHServer(t, "admin", clientPub, testRemoteResponses)
+	sshConfig, err := NewSSHClientConfig(SSHOptions{Username: "admin", KeyFile: keyFile, Timeout: 5 * time.Second, KnownHostsFile: server.KnownHosts(t)})
+	if err != nil {
+		t.Fatalf("NewSSHClientConfig failed: %v", err)
+	}
+
+	sc := NewSystemCollector(Config{}, false)
+	sc.SetSSHConfig(sshConfig, 22)
+	sc.SetRetryPolicy(RetryPolicy{Timeout: 200 * time.Millisecond, Retries: 1, Backoff: 10 * time.Millisecond})
+
+	slow, good := listener.Addr().String(), server.Addr()
+	start := time.Now()
+	results := sc.CollectMultiple(context.Background(), []string{slow, good}, 2)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected slow node to be bounded by the attempt timeout, took %v", elapsed)
+	}
+
+	if info := results[slow]; info.Status != NodeStatusTimeout || len(info.Attempts) != 2 {
+		t.Errorf("Expected slow node to time out after 2 attempts, got %s %+v", info.Status, info.Attempts)
+	}
+	if info := results[good]; info.Status != NodeStatusOK || info.Hostname != "node-a" || len(info.Attempts) != 1 {
+		t.Errorf("Expected healthy node to be collected, got %+v", info)
+	}
+}
+
+func TestCollectMultipleWithoutSSHConfig(t *testing.T) {
+	sc := NewSystemCollector(Config{}, false)
+	results := sc.CollectMultiple(context.Background(), []string{"10.255.255.1"}, 1)
+
+	info := results["10.255.255.1"]
+	if info == nil || !strings.Contains(info.CollectError, "no ssh configuration") {
+		t.Fatalf("Expected missing ssh configuration error, got %+v", info)
+	}
+}
+
+func TestRemoteAddress(t *testing.T) {
+	sc := NewSystemCollector(Config{}, false)
+	sc.SetSSHConfig(nil, 2222)
+
+	if addr := sc.remoteAddress("10.0.0.1"); addr != "10.0.0.1:2222" {
+		t.Errorf("Expected default port to be applied, got %s", addr)
+	}
+	if addr := sc.remoteAddress("10.0.0.1:22"); addr != "10.0.0.1:22" {
+		t.Errorf("Expected explicit port to be kept, got %s", addr)
+	}
+}
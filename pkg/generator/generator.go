@@ -201,16 +201,21 @@ func (g *MarkdownGenerator) Generate(data interface{}) ([]byte, error) {
 		// 磁盘
 		if len(reportData.Metrics.Disk) > 0 {
 			buf.WriteString("### 磁盘\n\n")
-			buf.WriteString("| 挂载点 | 总容量 | 已用 | 可用 | 使用率 |\n")
-			buf.WriteString("|--------|--------|------|------|--------|\n")
+			buf.WriteString("| 挂载点 | 总容量 | 已用 | 可用 | 使用率 | inode 使用率 |\n")
+			buf.WriteString("|--------|--------|------|------|--------|--------------|\n")
 
 			for _, disk := range reportData.Metrics.Disk {
-				buf.WriteString(fmt.Sprintf("| %s | %.2f GB | %.2f GB | %.2f GB | %.1f%% |\n",
+				if disk.Stale {
+					buf.WriteString(fmt.Sprintf("| %s | 无响应 | - | - | - | - |\n", disk.MountPoint))
+					continue
+				}
+				buf.WriteString(fmt.Sprintf("| %s | %.2f GB | %.2f GB | %.2f GB | %.1f%% | %.1f%% |\n",
 					disk.MountPoint,
 					float64(disk.Total)/1024/1024/1024,
 					float64(disk.Used)/1024/1024/1024,
 					float64(disk.Available)/1024/1024/1024,
 					disk.UsedPercent,
+					disk.InodesUsedPercent,
 				))
 			}
 			buf.WriteString("\n")
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filesystem df 输出中的一个文件系统，容量单位为字节
+type Filesystem struct {
+	Filesystem string  `json:"filesystem"`
+	Type       string  `json:"type,omitempty"`
+	Size       uint64  `json:"size"`
+	Used       uint64  `json:"used"`
+	Available  uint64  `json:"available"`
+	UsePercent float64 `json:"use_percent"`
+	MountPoint string  `json:"mount_point"`
+}
+
+// DF df 的解析结果
+type DF struct {
+	Filesystems []Filesystem `json:"filesystems"`
+	Warnings    []string     `json:"warnings,omitempty"`
+}
+
+// dfColumns df 表头列名（含 zh_CN 翻译）到字段的映射，容量列单独识别
+var dfColumns = map[string]string{
+	"Filesystem": "filesystem", "文件系统": "filesystem",
+	"Type": "type", "类型": "type",
+	"Used": "used", "已用": "used",
+	"Avail": "available", "Available": "available", "可用": "available",
+	"Use%": "use%", "Capacity": "use%", "已用%": "use%",
+	"Mounted on": "mount", "挂载点": "mount",
+	"Size": "size", "容量": "size",
+}
+
+// dfBlocks "1K-blocks"、"1B-blocks"、"1024-blocks"、"1M-块" 等容量列
+var dfBlocks = regexp.MustCompile(`^(\d+)([KMGT]?)B?-(?:blocks|块)$`)
+
+// ParseDF 解析 df [-B1|-k|-h|-P|-T] 的输出
+// 容量列的单位从表头识别（1B-blocks、1K-blocks、Size 等），Size 列的数值带单位
+// 兼容设备名过长时换行的输出、包含空格的挂载点和 busybox 的 df
+func ParseDF(output string) (*DF, error) {
+	var (
+		warn       warnings
+		header     []string
+		multiplier uint64 = 1
+		pending    []string
+		result     = &DF{}
+	)
+	for i, line := range lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if header == nil {
+			header, multiplier = parseDFHeader(fields)
+			if header == nil {
+				return nil, fmt.Errorf("parse df: unrecognised header %q", line)
+			}
+			continue
+		}
+
+		// 设备名过长时 df 把其余字段放到下一行
+		if len(pending) > 0 {
+			fields = append(pending, fields...)
+			pending = nil
+		}
+		if len(fields) == 1 {
+			pending = fields
+			continue
+		}
+		if len(fields) < len(header) {
+			warn.addf(i+1, "expected %d columns, got %d", len(header), len(fields))
+			continue
+		}
+		if len(fields) > len(header) {
+			// 挂载点包含空格
+			last := len(header) - 1
+			fields = append(fields[:last:last], strings.Join(fields[last:], " "))
+		}
+
+		r := row{fields: fields, line: i + 1, warn: &warn}
+		var fs Filesystem
+		for j, name := range header {
+			switch name {
+			case "filesystem":
+				fs.Filesystem = r.str(j)
+			case "type":
+				fs.Type = r.str(j)
+			case "size":
+				fs.Size = r.size(j, "size", multiplier)
+			case "used":
+				fs.Used = r.size(j, "used", multiplier)
+			case "available":
+				fs.Available = r.size(j, "available", multiplier)
+			case "use%":
+				if r.str(j) != "-" {
+					fs.UsePercent = r.float(j, "use%")
+				}
+			case "mount":
+				fs.MountPoint = r.str(j)
+			}
+		}
+		result.Filesystems = append(result.Filesystems, fs)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("parse df: no header found")
+	}
+	if len(pending) > 0 {
+		warn.addf(0, "truncated line for %s", pending[0])
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// parseDFHeader 识别 df 表头，返回每列的字段名和容量列的单位
+// "Mounted on" 在输出中是两个字段，这里合并为一列
+func parseDFHeader(fields []string) ([]string, uint64) {
+	var (
+		header     []string
+		multiplier uint64 = 1
+	)
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f == "Mounted" && i+1 < len(fields) && fields[i+1] == "on" {
+			f = "Mounted on"
+			i++
+		}
+		if m := dfBlocks.FindStringSubmatch(f); m != nil {
+			size, err := ParseSize(m[1] + m[2])
+			if err != nil {
+				return nil, 0
+			}
+			header = append(header, "size")
+			multiplier = size
+			continue
+		}
+		name, ok := dfColumns[f]
+		if !ok {
+			name = ""
+		}
+		header = append(header, name)
+	}
+	if len(header) == 0 || header[0] != "filesystem" {
+		return nil, 0
+	}
+	return header, multiplier
+}
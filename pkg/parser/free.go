@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FreeRow free 输出中的一行，单位为字节
+type FreeRow struct {
+	Total     uint64 `json:"total"`
+	Used      uint64 `json:"used"`
+	Free      uint64 `json:"free"`
+	Shared    uint64 `json:"shared,omitempty"`
+	Buffers   uint64 `json:"buffers,omitempty"`
+	Cache     uint64 `json:"cache,omitempty"`
+	BuffCache uint64 `json:"buff_cache,omitempty"`
+	Available uint64 `json:"available,omitempty"`
+}
+
+// Free free 的解析结果
+type Free struct {
+	Mem      FreeRow  `json:"mem"`
+	Swap     FreeRow  `json:"swap"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// freeColumns free 表头列名（含 zh_CN 翻译）到字段的映射
+var freeColumns = map[string]string{
+	"total": "total", "总计": "total",
+	"used": "used", "已用": "used",
+	"free": "free", "空闲": "free",
+	"shared": "shared", "共享": "shared",
+	"buffers": "buffers", "缓冲": "buffers",
+	"cache": "cache", "cached": "cache", "缓存": "cache",
+	"buff/cache": "buff/cache", "缓冲/缓存": "buff/cache",
+	"available": "available", "可用": "available",
+}
+
+// ParseFree 解析 free 的输出，不带单位的数值乘以 unit 换算为字节（free -b 为 1，free -m 为 1<<20），
+// 带单位的数值（free -h）按单位换算
+// 兼容 procps-ng 3.3.10 之后的 buff/cache、available 列，-w 的 buffers/cache 列，
+// 老版本 procps 的 cached 列和 "-/+ buffers/cache" 行，以及 zh_CN 区域设置的表头
+// 老版本的 Mem used 包含缓存，这里统一改为 "-/+ buffers/cache" 行的 used，与新版本含义一致
+func ParseFree(output string, unit uint64) (*Free, error) {
+	var (
+		warn    warnings
+		header  []string
+		result  = &Free{}
+		seenMem bool
+	)
+	for i, line := range lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if header == nil {
+			if _, ok := freeColumns[fields[0]]; ok {
+				header = make([]string, len(fields))
+				for j, f := range fields {
+					header[j] = freeColumns[f]
+				}
+			}
+			continue
+		}
+
+		label, rest, ok := splitKeyValue(fields[0])
+		if !ok {
+			label = fields[0]
+		}
+		values := fields[1:]
+		if rest != "" {
+			// "内存：" 与数值之间可能没有空格
+			values = append([]string{rest}, values...)
+		}
+		r := row{fields: values, line: i + 1, warn: &warn}
+
+		switch label {
+		case "Mem", "内存":
+			seenMem = true
+			result.Mem = parseFreeRow(header, r, unit)
+		case "Swap", "交换":
+			result.Swap = parseFreeRow(header, r, unit)
+		case "-/+":
+			// "-/+ buffers/cache:" 后依次为不含缓存的 used 和 free
+			k := 1
+			for k < len(fields) && !strings.HasSuffix(fields[k], ":") && !strings.HasSuffix(fields[k], "：") {
+				k++
+			}
+			if k < len(fields) {
+				k++
+			}
+			r.fields = fields[k:]
+			result.Mem.Used = r.size(0, "used", unit)
+			if result.Mem.Available == 0 {
+				result.Mem.Available = r.size(1, "free", unit)
+			}
+		default:
+			if label != "Total" && label != "总量" && label != "Low" && label != "High" {
+				warn.addf(i+1, "unknown row %q", label)
+			}
+		}
+	}
+	if header == nil {
+		return nil, fmt.Errorf("parse free: no header found")
+	}
+	if !seenMem {
+		return nil, fmt.Errorf("parse free: no Mem row found")
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// parseFreeRow 按表头解析 Mem 或 Swap 行
+func parseFreeRow(header []string, r row, unit uint64) FreeRow {
+	var fr FreeRow
+	for i, name := range header {
+		if name == "" {
+			r.warn.addf(r.line, "unknown column %d", i+1)
+			continue
+		}
+		if i >= len(r.fields) {
+			// Swap 行没有 shared、buff/cache 等列
+			break
+		}
+		v := r.size(i, name, unit)
+		switch name {
+		case "total":
+			fr.Total = v
+		case "used":
+			fr.Used = v
+		case "free":
+			fr.Free = v
+		case "shared":
+			fr.Shared = v
+		case "buffers":
+			fr.Buffers = v
+		case "cache":
+			fr.Cache = v
+		case "buff/cache":
+			fr.BuffCache = v
+		case "available":
+			fr.Available = v
+		}
+	}
+	if fr.BuffCache == 0 {
+		fr.BuffCache = fr.Buffers + fr.Cache
+	}
+	return fr
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IPAddress 网卡上的一个地址
+type IPAddress struct {
+	Family    string `json:"family"`
+	Address   string `json:"address"`
+	PrefixLen int    `json:"prefix_len"`
+	Scope     string `json:"scope,omitempty"`
+}
+
+// IPInterface ip addr 输出中的一个网卡
+type IPInterface struct {
+	Index     int         `json:"index"`
+	Name      string      `json:"name"`
+	Link      string      `json:"link,omitempty"` // "veth0@if5" 中 @ 之后的部分
+	Flags     []string    `json:"flags,omitempty"`
+	MTU       int         `json:"mtu,omitempty"`
+	State     string      `json:"state,omitempty"`
+	MAC       string      `json:"mac,omitempty"`
+	Addresses []IPAddress `json:"addresses,omitempty"`
+}
+
+// HasFlag 判断网卡是否带有指定标志，如 "UP"
+func (i IPInterface) HasFlag(flag string) bool {
+	for _, f := range i.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// IPv4 返回第一个 IPv4 地址，没有时返回空字符串
+func (i IPInterface) IPv4() string {
+	for _, a := range i.Addresses {
+		if a.Family == "inet" {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+// IPAddr ip addr 的解析结果
+type IPAddr struct {
+	Interfaces []IPInterface `json:"interfaces"`
+	Warnings   []string      `json:"warnings,omitempty"`
+}
+
+// ipIndexLine "2: eth0: <...>" 形式的网卡行或 -o 输出中 "2: eth0    inet ..." 形式的地址行
+var ipIndexLine = regexp.MustCompile(`^(\d+):\s+(\S+?)(:?)(?:\s+(.*))?$`)
+
+// ParseIPAddr 解析 ip addr show 的多行输出或 ip -o addr show 的单行输出
+// -o 输出中同一网卡的每个地址占一行，这里按网卡序号合并
+func ParseIPAddr(output string) (*IPAddr, error) {
+	var (
+		warn   warnings
+		result = &IPAddr{}
+		byIdx  = make(map[int]int)
+		cur    = -1
+	)
+	for i, line := range lines(output) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineNo := i + 1
+
+		m := ipIndexLine.FindStringSubmatch(line)
+		if m == nil {
+			if cur < 0 {
+				warn.addf(lineNo, "address line before interface")
+				continue
+			}
+			parseIPDetail(&result.Interfaces[cur], line, lineNo, &warn)
+			continue
+		}
+
+		index, _ := strconv.Atoi(m[1])
+		name, link := m[2], ""
+		if j := strings.Index(name, "@"); j >= 0 {
+			name, link = name[:j], name[j+1:]
+		}
+		pos, ok := byIdx[index]
+		if !ok {
+			result.Interfaces = append(result.Interfaces, IPInterface{Index: index, Name: name, Link: link})
+			pos = len(result.Interfaces) - 1
+			byIdx[index] = pos
+		}
+		cur = pos
+
+		// -o 输出用 "\" 代替换行
+		for _, part := range strings.Split(m[4], `\`) {
+			if m[3] == ":" && strings.HasPrefix(strings.TrimSpace(part), "<") {
+				parseIPLinkHeader(&result.Interfaces[cur], part, lineNo, &warn)
+				continue
+			}
+			parseIPDetail(&result.Interfaces[cur], part, lineNo, &warn)
+		}
+	}
+	if len(result.Interfaces) == 0 {
+		return nil, fmt.Errorf("parse ip addr: no interfaces found")
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// parseIPLinkHeader 解析网卡行中的 "<BROADCAST,UP> mtu 1500 ... state UP ..."
+func parseIPLinkHeader(iface *IPInterface, s string, line int, warn *warnings) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return
+	}
+	flags := strings.Trim(fields[0], "<>")
+	if flags != "" {
+		iface.Flags = strings.Split(flags, ",")
+	}
+	for j := 1; j+1 < len(fields); j += 2 {
+		switch fields[j] {
+		case "mtu":
+			mtu, err := strconv.Atoi(fields[j+1])
+			if err != nil {
+				warn.addf(line, "invalid mtu %q", fields[j+1])
+			}
+			iface.MTU = mtu
+		case "state":
+			iface.State = fields[j+1]
+		}
+	}
+}
+
+// parseIPDetail 解析 link/ether、inet、inet6 行，其余行（valid_lft、altname 等）忽略
+func parseIPDetail(iface *IPInterface, s string, line int, warn *warnings) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return
+	}
+	switch {
+	case strings.HasPrefix(fields[0], "link/"):
+		if fields[0] != "link/none" {
+			iface.MAC = fields[1]
+		}
+	case fields[0] == "inet" || fields[0] == "inet6":
+		addr := IPAddress{Family: fields[0], Address: fields[1]}
+		if j := strings.Index(addr.Address, "/"); j >= 0 {
+			prefix, err := strconv.Atoi(addr.Address[j+1:])
+			if err != nil {
+				warn.addf(line, "invalid prefix in %q", addr.Address)
+			}
+			addr.Address, addr.PrefixLen = addr.Address[:j], prefix
+		} else if fields[0] == "inet" {
+			addr.PrefixLen = 32
+		} else {
+			addr.PrefixLen = 128
+		}
+		for j := 2; j+1 < len(fields); j++ {
+			if fields[j] == "scope" {
+				addr.Scope = fields[j+1]
+			}
+		}
+		iface.Addresses = append(iface.Addresses, addr)
+	}
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BlockDevice lsblk 输出中的一个块设备
+type BlockDevice struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type,omitempty"`
+	Size       uint64            `json:"size"`
+	FSType     string            `json:"fstype,omitempty"`
+	MountPoint string            `json:"mountpoint,omitempty"`
+	Model      string            `json:"model,omitempty"`
+	Rotational bool              `json:"rota,omitempty"`
+	Fields     map[string]string `json:"fields"`
+}
+
+// LSBlk lsblk 的解析结果
+type LSBlk struct {
+	Devices  []BlockDevice `json:"devices"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// lsblkPair lsblk -P 输出中的 KEY="value"
+var lsblkPair = regexp.MustCompile(`([A-Z0-9:_%-]+)="([^"]*)"`)
+
+// lsblkTree 树形输出中设备名前的连接符
+const lsblkTree = "├└│─`|- "
+
+// ParseLSBlk 解析 lsblk 的表格输出或 -P 的 KEY="value" 输出，SIZE 列可以是字节数（-b）或带单位的容量
+// 表格输出按表头的列位置取值，空列（如未挂载设备的 MOUNTPOINT）不会导致后续列错位；设备名去掉树形连接符
+func ParseLSBlk(output string) (*LSBlk, error) {
+	var (
+		warn   warnings
+		header []lsblkColumn
+		result = &LSBlk{}
+	)
+	for i, line := range lines(output) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var fields map[string]string
+		switch {
+		case strings.Contains(line, `="`):
+			fields = make(map[string]string)
+			for _, m := range lsblkPair.FindAllStringSubmatch(line, -1) {
+				fields[m[1]] = m[2]
+			}
+		case header == nil:
+			header = parseLSBlkHeader(line)
+			continue
+		default:
+			fields = lsblkFields(header, line)
+		}
+
+		// util-linux 2.37 起 MOUNTPOINT 改为 MOUNTPOINTS
+		if _, ok := fields["MOUNTPOINT"]; !ok {
+			fields["MOUNTPOINT"] = fields["MOUNTPOINTS"]
+		}
+		fields["NAME"] = strings.TrimLeft(fields["NAME"], lsblkTree)
+		if fields["NAME"] == "" {
+			warn.addf(i+1, "missing NAME column")
+			continue
+		}
+
+		dev := BlockDevice{
+			Name:       fields["NAME"],
+			Type:       fields["TYPE"],
+			FSType:     fields["FSTYPE"],
+			MountPoint: fields["MOUNTPOINT"],
+			Model:      fields["MODEL"],
+			Rotational: fields["ROTA"] == "1",
+			Fields:     fields,
+		}
+		if size, ok := fields["SIZE"]; ok && size != "" {
+			r := row{fields: []string{size}, line: i + 1, warn: &warn}
+			dev.Size = r.size(0, "SIZE", 1)
+		}
+		result.Devices = append(result.Devices, dev)
+	}
+	if header == nil && len(result.Devices) == 0 {
+		return nil, fmt.Errorf("parse lsblk: no header found")
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// lsblkColumn 表头中的一列及其位置（按字符计）
+type lsblkColumn struct {
+	name       string
+	start, end int
+}
+
+// parseLSBlkHeader 记录每个列名在表头中的位置
+func parseLSBlkHeader(line string) []lsblkColumn {
+	var columns []lsblkColumn
+	for _, t := range tokens(line) {
+		columns = append(columns, lsblkColumn{name: t.text, start: t.start, end: t.end})
+	}
+	return columns
+}
+
+// lsblkFields 按位置把数据行的每个字段归入与之重叠最多的列
+// lsblk 的数值列右对齐、文本列左对齐，两种情况下字段都与列名有重叠；
+// 不重叠的字段是左侧文本列溢出的部分（如 "vg-root (dm-0)"），归入起始位置在它之前的最后一列
+func lsblkFields(header []lsblkColumn, line string) map[string]string {
+	fields := make(map[string]string, len(header))
+	for _, t := range tokens(line) {
+		best, bestOverlap := 0, 0
+		for i, c := range header {
+			if overlap := minInt(t.end, c.end) - maxInt(t.start, c.start); overlap > bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+			if bestOverlap == 0 && c.start <= t.start {
+				best = i
+			}
+		}
+		name := header[best].name
+		if fields[name] != "" {
+			// 包含空格的挂载点或型号
+			fields[name] += " " + t.text
+		} else {
+			fields[name] = t.text
+		}
+	}
+	for _, c := range header {
+		if _, ok := fields[c.name]; !ok {
+			fields[c.name] = ""
+		}
+	}
+	return fields
+}
+
+// token 一行中以空白分隔的字段及其位置（按字符计）
+type token struct {
+	text       string
+	start, end int
+}
+
+// tokens 拆分字段并记录位置，位置按字符而不是字节计算，以兼容树形连接符
+func tokens(line string) []token {
+	var (
+		result []token
+		start  = -1
+		pos    int
+		b      strings.Builder
+	)
+	for _, r := range line {
+		if r == ' ' || r == '\t' {
+			if start >= 0 {
+				result = append(result, token{text: b.String(), start: start, end: pos})
+				b.Reset()
+				start = -1
+			}
+		} else {
+			if start < 0 {
+				start = pos
+			}
+			b.WriteRune(r)
+		}
+		pos++
+	}
+	if start >= 0 {
+		result = append(result, token{text: b.String(), start: start, end: pos})
+	}
+	return result
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
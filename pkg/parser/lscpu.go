@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LSCPU lscpu 的解析结果
+type LSCPU struct {
+	Fields         map[string]string `json:"fields"`
+	Architecture   string            `json:"architecture"`
+	OpModes        string            `json:"op_modes,omitempty"`
+	VendorID       string            `json:"vendor_id,omitempty"`
+	ModelName      string            `json:"model_name,omitempty"`
+	CPUs           int               `json:"cpus"`
+	ThreadsPerCore int               `json:"threads_per_core,omitempty"`
+	CoresPerSocket int               `json:"cores_per_socket,omitempty"`
+	Sockets        int               `json:"sockets,omitempty"`
+	NUMANodes      int               `json:"numa_nodes,omitempty"`
+	MHz            float64           `json:"mhz,omitempty"`
+	MaxMHz         float64           `json:"max_mhz,omitempty"`
+	Hypervisor     string            `json:"hypervisor,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+// lscpuKeys zh_CN 区域设置下 lscpu 字段名到 C 区域设置字段名的映射
+var lscpuKeys = map[string]string{
+	"架构":         "Architecture",
+	"CPU 运行模式":   "CPU op-mode(s)",
+	"字节序":        "Byte Order",
+	"CPU":        "CPU(s)",
+	"在线 CPU 列表":  "On-line CPU(s) list",
+	"每个核的线程数":    "Thread(s) per core",
+	"每个座的核数":     "Core(s) per socket",
+	"每个簇的核数":     "Core(s) per cluster",
+	"座":          "Socket(s)",
+	"簇":          "Cluster(s)",
+	"NUMA 节点":    "NUMA node(s)",
+	"厂商 ID":      "Vendor ID",
+	"型号名称":       "Model name",
+	"CPU 最大 MHz": "CPU max MHz",
+	"CPU 最小 MHz": "CPU min MHz",
+	"超管理器厂商":     "Hypervisor vendor",
+	"虚拟化类型":      "Virtualization type",
+	"虚拟化":        "Virtualization",
+}
+
+// ParseLSCPU 解析 lscpu 的输出，字段名统一为 C 区域设置的写法
+// 兼容 util-linux 2.37 之后按层级缩进的输出；同名字段（如 ARM big.LITTLE 的多个 Model name）保留第一个
+func ParseLSCPU(output string) (*LSCPU, error) {
+	var warn warnings
+	result := &LSCPU{Fields: make(map[string]string)}
+	for i, line := range lines(output) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			warn.addf(i+1, "unrecognised line %q", strings.TrimSpace(line))
+			continue
+		}
+		if k, ok := lscpuKeys[key]; ok {
+			key = k
+		}
+		if _, ok := result.Fields[key]; !ok {
+			result.Fields[key] = value
+		}
+	}
+	if len(result.Fields) == 0 {
+		return nil, fmt.Errorf("parse lscpu: no fields found")
+	}
+
+	f := result.Fields
+	result.Architecture = f["Architecture"]
+	result.OpModes = f["CPU op-mode(s)"]
+	result.VendorID = f["Vendor ID"]
+	result.ModelName = f["Model name"]
+	result.Hypervisor = f["Hypervisor vendor"]
+	result.CPUs = lscpuInt(f, &warn, "CPU(s)")
+	result.ThreadsPerCore = lscpuInt(f, &warn, "Thread(s) per core")
+	result.CoresPerSocket = lscpuInt(f, &warn, "Core(s) per socket", "Core(s) per cluster")
+	result.Sockets = lscpuInt(f, &warn, "Socket(s)", "Cluster(s)")
+	result.NUMANodes = lscpuInt(f, &warn, "NUMA node(s)")
+	result.MHz = lscpuFloat(f, &warn, "CPU MHz")
+	result.MaxMHz = lscpuFloat(f, &warn, "CPU max MHz")
+	result.Warnings = warn
+	return result, nil
+}
+
+// lscpuInt 解析第一个存在的整数字段，"-" 表示不适用
+func lscpuInt(fields map[string]string, warn *warnings, keys ...string) int {
+	for _, key := range keys {
+		value, ok := fields[key]
+		if !ok || value == "-" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			*warn = append(*warn, fmt.Sprintf("invalid %s value %q", key, value))
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// lscpuFloat 解析浮点字段
+func lscpuFloat(fields map[string]string, warn *warnings, key string) float64 {
+	value, ok := fields[key]
+	if !ok {
+		return 0
+	}
+	v, err := ParseFloat(value)
+	if err != nil {
+		*warn = append(*warn, fmt.Sprintf("invalid %s value %q", key, value))
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,191 @@
+// Package parser 解析 vmstat、mpstat、iostat、sar、pidstat、ss、free、df、lscpu、lsblk、ip 等命令的输出
+//
+// 各解析器按表头列名而不是列位置取值，兼容不同 sysstat/procps/util-linux 版本增删列的情况；
+// 数值解析兼容以逗号作为小数点的区域设置，时间和 "Average:" 前缀按区域设置的常见写法识别。
+// 采集时应使用 C 区域设置执行命令，这里的兼容处理只作为兜底。
+//
+// 无法识别的行不会导致整体失败，而是记入结果的 Warnings；只有找不到表头等完全无法解析的情况才返回错误。
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version 解析器版本，解析规则变化时递增，随采集结果输出以便比对不同版本的解析结果
+const Version = 1
+
+// ParseFloat 解析数值，兼容 "1,50" 形式的逗号小数点和 "12.5%" 形式的百分比
+func ParseFloat(s string) (float64, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// sizePattern 带单位的容量，如 "1.5G"、"512Mi"、"100 KB"、"1,5T"
+var sizePattern = regexp.MustCompile(`^([0-9]+(?:[.,][0-9]+)?)\s*([KMGTPE]?)(?:I?B?)$`)
+
+// ParseSize 解析容量并换算为字节，单位按 1024 进制（与 df -h、lsblk、free -h 一致）
+// 不带单位的数字原样返回
+func ParseSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	m := sizePattern.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	value, err := ParseFloat(m[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	multiplier := float64(1)
+	for _, unit := range "KMGTPE" {
+		if m[2] == "" {
+			break
+		}
+		multiplier *= 1024
+		if string(unit) == m[2] {
+			break
+		}
+	}
+	return uint64(value*multiplier + 0.5), nil
+}
+
+// FormatSize 按 df -h 的风格格式化字节数，如 "512"、"1.6M"、"97G"
+func FormatSize(bytes uint64) string {
+	if bytes < 1024 {
+		return strconv.FormatUint(bytes, 10)
+	}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len("KMGTPE") {
+		value /= 1024
+		unit++
+	}
+	if value < 10 {
+		return fmt.Sprintf("%.1f%c", value, "KMGTPE"[unit-1])
+	}
+	return fmt.Sprintf("%.0f%c", value, "KMGTPE"[unit-1])
+}
+
+// warnings 收集解析过程中的非致命问题
+type warnings []string
+
+func (w *warnings) addf(line int, format string, args ...interface{}) {
+	*w = append(*w, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+// columns 表头列名到字段下标的映射
+type columns map[string]int
+
+func newColumns(fields []string) columns {
+	c := make(columns, len(fields))
+	for i, f := range fields {
+		if _, ok := c[f]; !ok {
+			c[f] = i
+		}
+	}
+	return c
+}
+
+// index 返回第一个存在的列名的下标，都不存在时返回 -1
+func (c columns) index(names ...string) int {
+	for _, name := range names {
+		if i, ok := c[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// row 一行数据，按列下标取值，取值失败时记录警告
+type row struct {
+	fields []string
+	line   int
+	warn   *warnings
+}
+
+// str 返回字段，列不存在时返回空字符串
+func (r row) str(i int) string {
+	if i < 0 || i >= len(r.fields) {
+		return ""
+	}
+	return r.fields[i]
+}
+
+// float 解析浮点字段；列不存在时返回 0，字段缺失或格式错误时记录警告
+func (r row) float(i int, name string) float64 {
+	if i < 0 {
+		return 0
+	}
+	if i >= len(r.fields) {
+		r.warn.addf(r.line, "missing column %s", name)
+		return 0
+	}
+	v, err := ParseFloat(r.fields[i])
+	if err != nil {
+		r.warn.addf(r.line, "invalid %s value %q", name, r.fields[i])
+		return 0
+	}
+	return v
+}
+
+// int 解析整数字段
+func (r row) int(i int, name string) int {
+	return int(r.float(i, name))
+}
+
+// uint 解析非负整数字段
+func (r row) uint(i int, name string) uint64 {
+	v := r.float(i, name)
+	if v < 0 {
+		return 0
+	}
+	return uint64(v)
+}
+
+// size 解析容量字段，单位换算为字节，不带单位的数字乘以 multiplier
+func (r row) size(i int, name string, multiplier uint64) uint64 {
+	if i < 0 {
+		return 0
+	}
+	if i >= len(r.fields) {
+		r.warn.addf(r.line, "missing column %s", name)
+		return 0
+	}
+	s := r.fields[i]
+	if s == "-" {
+		return 0
+	}
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return v * multiplier
+	}
+	v, err := ParseSize(s)
+	if err != nil {
+		r.warn.addf(r.line, "invalid %s value %q", name, s)
+		return 0
+	}
+	return v
+}
+
+// lines 按行拆分，兼容 CRLF 换行
+func lines(output string) []string {
+	return strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+}
+
+// splitKeyValue 拆分 "key: value" 形式的行，兼容全角冒号
+func splitKeyValue(line string) (string, string, bool) {
+	i := strings.IndexAny(line, ":：")
+	if i < 0 {
+		return "", "", false
+	}
+	sep := 1
+	if strings.HasPrefix(line[i:], "：") {
+		sep = len("：")
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+sep:]), true
+}
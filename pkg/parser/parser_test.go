@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// freeUnits 各 free 样本采集时使用的单位
+var freeUnits = map[string]uint64{
+	"centos6-x86_64":   1024,
+	"centos7-x86_64":   1,
+	"ubuntu2204-wide":  1 << 20,
+	"zh_CN-aarch64":    1 << 20,
+	"human-ubuntu2404": 1,
+	"busybox":          1024,
+}
+
+// parsers testdata 下各目录对应的解析函数
+var parsers = map[string]func(name, output string) (interface{}, error){
+	"mpstat":  func(_, s string) (interface{}, error) { return ParseMPStat(s) },
+	"sar":     func(_, s string) (interface{}, error) { return ParseSarNetDev(s) },
+	"pidstat": func(_, s string) (interface{}, error) { return ParsePidstat(s) },
+	"iostat":  func(_, s string) (interface{}, error) { return ParseIOStat(s) },
+	"vmstat":  func(_, s string) (interface{}, error) { return ParseVMStat(s) },
+	"ss": func(name, s string) (interface{}, error) {
+		if strings.HasPrefix(name, "summary") {
+			return ParseSSSummary(s)
+		}
+		return ParseSSStates(s)
+	},
+	"free":  func(name, s string) (interface{}, error) { return ParseFree(s, freeUnits[name]) },
+	"df":    func(_, s string) (interface{}, error) { return ParseDF(s) },
+	"lscpu": func(_, s string) (interface{}, error) { return ParseLSCPU(s) },
+	"lsblk": func(_, s string) (interface{}, error) { return ParseLSBlk(s) },
+	"ip":    func(_, s string) (interface{}, error) { return ParseIPAddr(s) },
+}
+
+// TestGolden 用 testdata 下不同发行版、架构和区域设置的样本输出比对解析结果
+// 修改解析规则后使用 go test -update 重新生成 .json 文件并检查差异
+func TestGolden(t *testing.T) {
+	for tool, parse := range parsers {
+		files, err := filepath.Glob(filepath.Join("testdata", tool, "*.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 0 {
+			t.Errorf("No fixtures for %s", tool)
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), ".txt")
+			t.Run(tool+"/"+name, func(t *testing.T) {
+				input, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := parse(name, string(input))
+				if err != nil {
+					t.Fatalf("Parse failed: %v", err)
+				}
+				got, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, '\n')
+
+				golden := strings.TrimSuffix(file, ".txt") + ".json"
+				if *update {
+					if err := os.WriteFile(golden, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("Missing golden file, run go test -update: %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("Result differs from %s:\n%s", golden, got)
+				}
+			})
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]uint64{
+		"512":    512,
+		"1K":     1024,
+		"1.5G":   3 << 29,
+		"512Mi":  512 << 20,
+		"100 KB": 100 << 10,
+		"1,5T":   3 << 39,
+		"0B":     0,
+	}
+	for input, want := range tests {
+		got, err := ParseSize(input)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Error("Expected error for invalid size")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[uint64]string{
+		512:               "512",
+		1677721:           "1.6M",
+		104152956928:      "97G",
+		3 << 39:           "1.5T",
+		1<<30 - 1<<20*100: "924M",
+	}
+	for input, want := range tests {
+		if got := FormatSize(input); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseMPStatLocales(t *testing.T) {
+	var results []*MPStat
+	for _, name := range []string{"centos7-x86_64", "de_DE-x86_64", "zh_CN-aarch64"} {
+		input, err := os.ReadFile(filepath.Join("testdata", "mpstat", name+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := ParseMPStat(string(input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(result.Warnings) > 0 {
+			t.Errorf("%s: unexpected warnings %v", name, result.Warnings)
+		}
+		results = append(results, result)
+	}
+
+	// 只取汇总行，不重复计入采样行
+	if got := len(results[0].CPUs); got != 5 {
+		t.Errorf("Expected all + 4 CPUs, got %d", got)
+	}
+	if cpu := results[1].CPUs[1]; cpu.CPU != "0" || cpu.User != 9 || cpu.Idle != 87 {
+		t.Errorf("Unexpected comma decimal parsing: %+v", cpu)
+	}
+	if cpu := results[2].CPUs[0]; cpu.CPU != "all" || cpu.User != 25.13 {
+		t.Errorf("Unexpected zh_CN parsing: %+v", cpu)
+	}
+}
+
+func TestParseIOStatVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		device    string
+		readKBps  float64
+		writeKBps float64
+		await     float64
+		util      float64
+	}{
+		{"centos6-x86_64", "sda", 80, 512, 8, 6},
+		{"centos7-x86_64", "vda", 320, 2400, 7.06, 8.5},
+		{"ubuntu2204-x86_64", "nvme0n1", 3200, 9600, 1.625, 35.2},
+		{"openeuler-aarch64-mb", "sda", 1536, 6144, 8, 22},
+	}
+	for _, tt := range tests {
+		input, err := os.ReadFile(filepath.Join("testdata", "iostat", tt.name+".txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := ParseIOStat(string(input))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var found bool
+		for _, d := range result.Devices {
+			if d.Device != tt.device {
+				continue
+			}
+			found = true
+			if d.ReadKBps != tt.readKBps || d.WriteKBps != tt.writeKBps || d.Await != tt.await || d.Util != tt.util {
+				t.Errorf("%s: unexpected stats %+v", tt.name, d)
+			}
+		}
+		if !found {
+			t.Errorf("%s: device %s not found in last report", tt.name, tt.device)
+		}
+	}
+}
+
+func TestParseFreeNormalisesUsed(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "free", "centos6-x86_64.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseFree(string(input), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 老版本 procps 的 used 包含缓存，应取 "-/+ buffers/cache" 行
+	if result.Mem.Used != 4366300*1024 || result.Mem.Available != 11964724*1024 {
+		t.Errorf("Unexpected Mem row: %+v", result.Mem)
+	}
+	if result.Mem.BuffCache != (412300+11203400)*1024 {
+		t.Errorf("Expected buffers and cache summed, got %d", result.Mem.BuffCache)
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	result, err := ParseMPStat("12:00:01 CPU %usr %sys %idle\n12:00:02 all 1.0 x 98.0\n12:00:02 0 1.0\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings, got %v", result.Warnings)
+	}
+	if len(result.CPUs) != 1 || result.CPUs[0].Idle != 98 {
+		t.Errorf("Expected valid columns kept, got %+v", result.CPUs)
+	}
+
+	if _, err := ParseIOStat("iostat: command not found\n"); err == nil {
+		t.Error("Expected error without header")
+	}
+	if _, err := ParseDF(""); err == nil {
+		t.Error("Expected error for empty df output")
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SSSummary ss -s 中的 TCP 汇总
+type SSSummary struct {
+	Total       int      `json:"total"`
+	TCP         int      `json:"tcp"`
+	Established int      `json:"estab"`
+	Closed      int      `json:"closed"`
+	Orphaned    int      `json:"orphaned"`
+	SynRecv     int      `json:"synrecv"`
+	TimeWait    int      `json:"timewait"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// ssCounter TCP 行括号中的 "estab 5" 形式的计数
+var ssCounter = regexp.MustCompile(`([a-z]+) (\d+)`)
+
+// ParseSSSummary 解析 ss -s 的输出
+// 兼容老版本 iproute2 的 "synrecv 0, timewait 2/0" 写法
+func ParseSSSummary(output string) (*SSSummary, error) {
+	var (
+		warn   warnings
+		result = &SSSummary{}
+		found  bool
+	)
+	for i, line := range lines(output) {
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Total":
+			result.Total = leadingInt(value, i+1, &warn)
+		case "TCP":
+			found = true
+			result.TCP = leadingInt(value, i+1, &warn)
+			for _, m := range ssCounter.FindAllStringSubmatch(value, -1) {
+				n, _ := strconv.Atoi(m[2])
+				switch m[1] {
+				case "estab":
+					result.Established = n
+				case "closed":
+					result.Closed = n
+				case "orphaned":
+					result.Orphaned = n
+				case "synrecv":
+					result.SynRecv = n
+				case "timewait":
+					result.TimeWait = n
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("parse ss -s: no TCP line found")
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// leadingInt 解析 "190 (kernel 0)" 形式值开头的整数
+func leadingInt(value string, line int, warn *warnings) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		warn.addf(line, "missing value")
+		return 0
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		warn.addf(line, "invalid value %q", fields[0])
+	}
+	return n
+}
+
+// SSStates ss -tan 中各连接状态的数量
+type SSStates struct {
+	Counts   map[string]int `json:"counts"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// ParseSSStates 解析 ss -tan（或 -uan 等）的输出，按 State 列统计连接数
+// 带 Netid 列的输出（ss -an）同样适用
+func ParseSSStates(output string) (*SSStates, error) {
+	var (
+		warn  warnings
+		state = -1
+		count = make(map[string]int)
+	)
+	for i, line := range lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if state < 0 {
+			for j, f := range fields {
+				if f == "State" {
+					state = j
+				}
+			}
+			continue
+		}
+		if state >= len(fields) {
+			warn.addf(i+1, "missing State column")
+			continue
+		}
+		count[fields[state]]++
+	}
+	if state < 0 {
+		return nil, fmt.Errorf("parse ss: no State header found")
+	}
+	return &SSStates{Counts: count, Warnings: warn}, nil
+}
@@ -0,0 +1,390 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// timePattern sysstat 行首的采样时间，如 "10:30:01"、"10.30.01"、"10时30分01秒"、"下午10时30分01秒"
+var timePattern = regexp.MustCompile(`^(?:上午|下午)?(?:\d{1,2}[:.]\d{2}(?:[:.]\d{2})?|\d{1,2}时\d{1,2}分(?:\d{1,2}秒)?)$`)
+
+// meridiems 12 小时制时间后的上下午标记
+var meridiems = map[string]bool{"AM": true, "PM": true, "am": true, "pm": true, "上午": true, "下午": true, "午前": true, "午後": true}
+
+// sysstatRow sysstat 表格中的一行数据
+type sysstatRow struct {
+	row
+	average bool // "Average:" 等汇总行
+}
+
+// stripSysstatPrefix 去掉行首的采样时间或汇总标签，返回剩余字段以及是否为汇总行
+func stripSysstatPrefix(fields []string) ([]string, bool) {
+	if len(fields) == 0 {
+		return fields, false
+	}
+	if timePattern.MatchString(fields[0]) {
+		fields = fields[1:]
+		if len(fields) > 0 && meridiems[fields[0]] {
+			fields = fields[1:]
+		}
+		return fields, false
+	}
+	// 汇总标签随区域设置变化（Average:、平均时间:、Durchschn.:、Moyenne :），统一按冒号结尾识别
+	if len(fields) > 1 && (fields[1] == ":" || fields[1] == "：") {
+		return fields[2:], true
+	}
+	if strings.HasSuffix(fields[0], ":") || strings.HasSuffix(fields[0], "：") {
+		return fields[1:], true
+	}
+	return fields, false
+}
+
+// parseSysstatTable 解析 mpstat、sar、pidstat 的表格输出
+// 包含 key 列的行作为表头，之后的行按表头取值；表头可以重复出现，后出现的覆盖前面的
+// 数据行多出的字段合并到最后一列（如 pidstat 的 Command）
+func parseSysstatTable(output, key string, warn *warnings) (columns, []sysstatRow, error) {
+	var (
+		header []string
+		rows   []sysstatRow
+	)
+	for i, line := range lines(output) {
+		lineNo := i + 1
+		fields, average := stripSysstatPrefix(strings.Fields(line))
+		if len(fields) == 0 {
+			continue
+		}
+		if containsField(fields, key) {
+			header = fields
+			continue
+		}
+		if header == nil {
+			// 表头之前是 "Linux 5.15.0 (host) ..." 之类的标题行
+			continue
+		}
+		if len(fields) < len(header) {
+			warn.addf(lineNo, "expected %d columns, got %d", len(header), len(fields))
+			continue
+		}
+		if len(fields) > len(header) {
+			last := len(header) - 1
+			fields = append(fields[:last:last], strings.Join(fields[last:], " "))
+		}
+		rows = append(rows, sysstatRow{row: row{fields: fields, line: lineNo, warn: warn}, average: average})
+	}
+	if header == nil {
+		return nil, nil, fmt.Errorf("no header with column %s found", key)
+	}
+	return newColumns(header), rows, nil
+}
+
+// containsField 判断字段列表中是否有指定值
+func containsField(fields []string, value string) bool {
+	for _, f := range fields {
+		if f == value {
+			return true
+		}
+	}
+	return false
+}
+
+// selectRows 有汇总行时只取汇总行，否则按 key 列取每个对象最后一次采样，保持首次出现的顺序
+func selectRows(rows []sysstatRow, key int) []sysstatRow {
+	var averages []sysstatRow
+	for _, r := range rows {
+		if r.average {
+			averages = append(averages, r)
+		}
+	}
+	if len(averages) > 0 {
+		return averages
+	}
+
+	var (
+		order []string
+		last  = make(map[string]sysstatRow)
+	)
+	for _, r := range rows {
+		k := r.str(key)
+		if _, ok := last[k]; !ok {
+			order = append(order, k)
+		}
+		last[k] = r
+	}
+	selected := make([]sysstatRow, 0, len(order))
+	for _, k := range order {
+		selected = append(selected, last[k])
+	}
+	return selected
+}
+
+// CPUStat mpstat 中单个 CPU 的使用率，单位为百分比
+type CPUStat struct {
+	CPU    string  `json:"cpu"`
+	User   float64 `json:"user"`
+	Nice   float64 `json:"nice"`
+	System float64 `json:"system"`
+	IOWait float64 `json:"iowait"`
+	IRQ    float64 `json:"irq"`
+	Soft   float64 `json:"soft"`
+	Steal  float64 `json:"steal"`
+	Guest  float64 `json:"guest"`
+	Idle   float64 `json:"idle"`
+}
+
+// MPStat mpstat -P ALL 的解析结果
+type MPStat struct {
+	CPUs     []CPUStat `json:"cpus"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// ParseMPStat 解析 mpstat -P ALL 的输出，有 Average 汇总时取汇总，否则取每个 CPU 最后一次采样
+// 兼容 sysstat 10.x 之前的 %user/%sys 和之后的 %usr/%sys 列名
+func ParseMPStat(output string) (*MPStat, error) {
+	var warn warnings
+	cols, rows, err := parseSysstatTable(output, "CPU", &warn)
+	if err != nil {
+		return nil, fmt.Errorf("parse mpstat: %w", err)
+	}
+
+	cpu := cols.index("CPU")
+	user := cols.index("%usr", "%user")
+	nice := cols.index("%nice")
+	system := cols.index("%sys", "%system")
+	iowait := cols.index("%iowait")
+	irq := cols.index("%irq")
+	soft := cols.index("%soft")
+	steal := cols.index("%steal")
+	guest := cols.index("%guest")
+	idle := cols.index("%idle")
+
+	result := &MPStat{}
+	for _, r := range selectRows(rows, cpu) {
+		result.CPUs = append(result.CPUs, CPUStat{
+			CPU:    r.str(cpu),
+			User:   r.float(user, "%usr"),
+			Nice:   r.float(nice, "%nice"),
+			System: r.float(system, "%sys"),
+			IOWait: r.float(iowait, "%iowait"),
+			IRQ:    r.float(irq, "%irq"),
+			Soft:   r.float(soft, "%soft"),
+			Steal:  r.float(steal, "%steal"),
+			Guest:  r.float(guest, "%guest"),
+			Idle:   r.float(idle, "%idle"),
+		})
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// NetDevStat sar -n DEV 中单个网卡的速率
+type NetDevStat struct {
+	Interface string  `json:"interface"`
+	RxPackets float64 `json:"rx_pckps"`
+	TxPackets float64 `json:"tx_pckps"`
+	RxKB      float64 `json:"rx_kbps"`
+	TxKB      float64 `json:"tx_kbps"`
+	Util      float64 `json:"util_pct"`
+}
+
+// SarNetDev sar -n DEV 的解析结果
+type SarNetDev struct {
+	Interfaces []NetDevStat `json:"interfaces"`
+	Warnings   []string     `json:"warnings,omitempty"`
+}
+
+// ParseSarNetDev 解析 sar -n DEV 的输出，有 Average 汇总时取汇总，否则取每个网卡最后一次采样
+// %ifutil 列在 sysstat 10.1 之前不存在
+func ParseSarNetDev(output string) (*SarNetDev, error) {
+	var warn warnings
+	cols, rows, err := parseSysstatTable(output, "IFACE", &warn)
+	if err != nil {
+		return nil, fmt.Errorf("parse sar -n DEV: %w", err)
+	}
+
+	iface := cols.index("IFACE")
+	rxpck := cols.index("rxpck/s")
+	txpck := cols.index("txpck/s")
+	rxkb := cols.index("rxkB/s", "rxbyt/s")
+	txkb := cols.index("txkB/s", "txbyt/s")
+	util := cols.index("%ifutil")
+	// sysstat 8.x 之前以字节为单位
+	scale := 1.0
+	if _, ok := cols["rxbyt/s"]; ok {
+		scale = 1.0 / 1024
+	}
+
+	result := &SarNetDev{}
+	for _, r := range selectRows(rows, iface) {
+		result.Interfaces = append(result.Interfaces, NetDevStat{
+			Interface: r.str(iface),
+			RxPackets: r.float(rxpck, "rxpck/s"),
+			TxPackets: r.float(txpck, "txpck/s"),
+			RxKB:      r.float(rxkb, "rxkB/s") * scale,
+			TxKB:      r.float(txkb, "txkB/s") * scale,
+			Util:      r.float(util, "%ifutil"),
+		})
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// ProcessStat pidstat 中单个进程的 CPU 使用率
+type ProcessStat struct {
+	UID     string  `json:"uid,omitempty"`
+	User    string  `json:"user,omitempty"`
+	PID     int     `json:"pid"`
+	UserPct float64 `json:"usr_pct"`
+	System  float64 `json:"system_pct"`
+	Guest   float64 `json:"guest_pct"`
+	Wait    float64 `json:"wait_pct"`
+	CPUPct  float64 `json:"cpu_pct"`
+	CPU     string  `json:"cpu"`
+	Command string  `json:"command"`
+}
+
+// Pidstat pidstat 的解析结果
+type Pidstat struct {
+	Processes []ProcessStat `json:"processes"`
+	Warnings  []string      `json:"warnings,omitempty"`
+}
+
+// ParsePidstat 解析 pidstat 的 CPU 统计输出，有 Average 汇总时取汇总，否则取每个进程最后一次采样
+// -U 选项输出 USER 列代替 UID 列；%wait 列在 sysstat 12.x 才出现
+func ParsePidstat(output string) (*Pidstat, error) {
+	var warn warnings
+	cols, rows, err := parseSysstatTable(output, "PID", &warn)
+	if err != nil {
+		return nil, fmt.Errorf("parse pidstat: %w", err)
+	}
+
+	uid := cols.index("UID")
+	user := cols.index("USER")
+	pid := cols.index("PID")
+	usr := cols.index("%usr", "%user")
+	system := cols.index("%system")
+	guest := cols.index("%guest")
+	wait := cols.index("%wait")
+	cpuPct := cols.index("%CPU")
+	cpu := cols.index("CPU")
+	command := cols.index("Command")
+
+	result := &Pidstat{}
+	for _, r := range selectRows(rows, pid) {
+		result.Processes = append(result.Processes, ProcessStat{
+			UID:     r.str(uid),
+			User:    r.str(user),
+			PID:     r.int(pid, "PID"),
+			UserPct: r.float(usr, "%usr"),
+			System:  r.float(system, "%system"),
+			Guest:   r.float(guest, "%guest"),
+			Wait:    r.float(wait, "%wait"),
+			CPUPct:  r.float(cpuPct, "%CPU"),
+			CPU:     r.str(cpu),
+			Command: r.str(command),
+		})
+	}
+	result.Warnings = warn
+	return result, nil
+}
+
+// DiskIOStat iostat -x 中单个设备的统计
+type DiskIOStat struct {
+	Device     string  `json:"device"`
+	ReadsPerS  float64 `json:"r_per_s"`
+	WritesPerS float64 `json:"w_per_s"`
+	TPS        float64 `json:"tps"`
+	ReadKBps   float64 `json:"read_kbps"`
+	WriteKBps  float64 `json:"write_kbps"`
+	Await      float64 `json:"await_ms"`
+	ReadAwait  float64 `json:"r_await_ms"`
+	WriteAwait float64 `json:"w_await_ms"`
+	QueueSize  float64 `json:"queue_size"`
+	Util       float64 `json:"util_pct"`
+}
+
+// IOStat iostat 的解析结果
+type IOStat struct {
+	Devices  []DiskIOStat `json:"devices"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+// ParseIOStat 解析 iostat -d/-x 的输出，只取最后一次报告（第一次报告是开机以来的平均值）
+// 兼容 sysstat 9.x 的 rsec/s、10.x/11.x 的 await/avgqu-sz 和 12.x 的 r_await/aqu-sz 列
+func ParseIOStat(output string) (*IOStat, error) {
+	var (
+		warn    warnings
+		header  columns
+		devices []DiskIOStat
+		inTable bool
+	)
+	for i, line := range lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			inTable = false
+			continue
+		}
+		if fields[0] == "Device" || fields[0] == "Device:" {
+			header = newColumns(fields)
+			devices = nil
+			inTable = true
+			continue
+		}
+		if !inTable {
+			// 标题行、-t 输出的时间戳和 avg-cpu 段
+			continue
+		}
+		if len(fields) < len(header) {
+			warn.addf(i+1, "expected %d columns, got %d", len(header), len(fields))
+			continue
+		}
+		devices = append(devices, parseIOStatDevice(header, row{fields: fields, line: i + 1, warn: &warn}))
+	}
+	if header == nil {
+		return nil, fmt.Errorf("parse iostat: no Device header found")
+	}
+	return &IOStat{Devices: devices, Warnings: warn}, nil
+}
+
+// parseIOStatDevice 按表头解析一个设备行
+func parseIOStatDevice(cols columns, r row) DiskIOStat {
+	stat := DiskIOStat{
+		Device:     r.str(0),
+		ReadsPerS:  r.float(cols.index("r/s"), "r/s"),
+		WritesPerS: r.float(cols.index("w/s"), "w/s"),
+		ReadAwait:  r.float(cols.index("r_await"), "r_await"),
+		WriteAwait: r.float(cols.index("w_await"), "w_await"),
+		QueueSize:  r.float(cols.index("aqu-sz", "avgqu-sz"), "aqu-sz"),
+		Util:       r.float(cols.index("%util"), "%util"),
+	}
+
+	if i := cols.index("tps"); i >= 0 {
+		stat.TPS = r.float(i, "tps")
+	} else {
+		stat.TPS = stat.ReadsPerS + stat.WritesPerS
+	}
+
+	switch {
+	case cols.index("rkB/s") >= 0:
+		stat.ReadKBps = r.float(cols.index("rkB/s"), "rkB/s")
+		stat.WriteKBps = r.float(cols.index("wkB/s"), "wkB/s")
+	case cols.index("kB_read/s") >= 0:
+		stat.ReadKBps = r.float(cols.index("kB_read/s"), "kB_read/s")
+		stat.WriteKBps = r.float(cols.index("kB_wrtn/s"), "kB_wrtn/s")
+	case cols.index("rMB/s") >= 0:
+		stat.ReadKBps = r.float(cols.index("rMB/s"), "rMB/s") * 1024
+		stat.WriteKBps = r.float(cols.index("wMB/s"), "wMB/s") * 1024
+	case cols.index("rsec/s") >= 0:
+		// 扇区固定为 512 字节
+		stat.ReadKBps = r.float(cols.index("rsec/s"), "rsec/s") / 2
+		stat.WriteKBps = r.float(cols.index("wsec/s"), "wsec/s") / 2
+	}
+
+	if i := cols.index("await"); i >= 0 {
+		stat.Await = r.float(i, "await")
+	} else if ops := stat.ReadsPerS + stat.WritesPerS; ops > 0 {
+		// sysstat 12.x 去掉了 await 列，按读写次数加权
+		stat.Await = (stat.ReadAwait*stat.ReadsPerS + stat.WriteAwait*stat.WritesPerS) / ops
+	}
+	return stat
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VMStatSample vmstat 的一次采样，内存和交换单位为 vmstat 输出的单位（默认 KiB）
+type VMStatSample struct {
+	RunQueue        int `json:"r"`
+	Blocked         int `json:"b"`
+	SwapUsed        int `json:"swpd"`
+	Free            int `json:"free"`
+	Buffer          int `json:"buff"`
+	Cache           int `json:"cache"`
+	Inactive        int `json:"inact,omitempty"`
+	Active          int `json:"active,omitempty"`
+	SwapIn          int `json:"si"`
+	SwapOut         int `json:"so"`
+	BlocksIn        int `json:"bi"`
+	BlocksOut       int `json:"bo"`
+	Interrupts      int `json:"in"`
+	ContextSwitches int `json:"cs"`
+	User            int `json:"us"`
+	System          int `json:"sy"`
+	Idle            int `json:"id"`
+	IOWait          int `json:"wa"`
+	Steal           int `json:"st"`
+	Guest           int `json:"gu,omitempty"`
+}
+
+// VMStat vmstat 的解析结果
+type VMStat struct {
+	Samples  []VMStatSample `json:"samples"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// Last 返回最后一次采样；第一次采样是开机以来的平均值，多次采样时应取最后一次
+func (v *VMStat) Last() VMStatSample {
+	if len(v.Samples) == 0 {
+		return VMStatSample{}
+	}
+	return v.Samples[len(v.Samples)-1]
+}
+
+// vmstatColumns C 区域设置下 procps vmstat 的列名，表头被翻译时按此顺序兜底
+var vmstatColumns = []string{"r", "b", "swpd", "free", "buff", "cache", "si", "so", "bi", "bo", "in", "cs", "us", "sy", "id", "wa", "st"}
+
+// ParseVMStat 解析 vmstat [-a] 的输出
+// 兼容 procps-ng 4.x 新增的 gu 列、-a 的 inact/active 列以及没有 st 列的老版本
+func ParseVMStat(output string) (*VMStat, error) {
+	var (
+		warn   warnings
+		header columns
+		result = &VMStat{}
+	)
+	for i, line := range lines(output) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "procs") {
+			continue
+		}
+		if fields[0] == "r" && len(fields) > 1 && fields[1] == "b" {
+			header = newColumns(fields)
+			if header.index("cs") < 0 && len(fields) >= len(vmstatColumns) {
+				warn.addf(i+1, "unrecognised vmstat header, assuming standard column order")
+				header = newColumns(vmstatColumns)
+			}
+			continue
+		}
+		if header == nil {
+			continue
+		}
+		if len(fields) < len(header) {
+			warn.addf(i+1, "expected %d columns, got %d", len(header), len(fields))
+			continue
+		}
+
+		r := row{fields: fields, line: i + 1, warn: &warn}
+		result.Samples = append(result.Samples, VMStatSample{
+			RunQueue:        r.int(header.index("r"), "r"),
+			Blocked:         r.int(header.index("b"), "b"),
+			SwapUsed:        r.int(header.index("swpd"), "swpd"),
+			Free:            r.int(header.index("free"), "free"),
+			Buffer:          r.int(header.index("buff"), "buff"),
+			Cache:           r.int(header.index("cache"), "cache"),
+			Inactive:        r.int(header.index("inact"), "inact"),
+			Active:          r.int(header.index("active"), "active"),
+			SwapIn:          r.int(header.index("si"), "si"),
+			SwapOut:         r.int(header.index("so"), "so"),
+			BlocksIn:        r.int(header.index("bi"), "bi"),
+			BlocksOut:       r.int(header.index("bo"), "bo"),
+			Interrupts:      r.int(header.index("in"), "in"),
+			ContextSwitches: r.int(header.index("cs"), "cs"),
+			User:            r.int(header.index("us"), "us"),
+			System:          r.int(header.index("sy"), "sy"),
+			Idle:            r.int(header.index("id"), "id"),
+			IOWait:          r.int(header.index("wa"), "wa"),
+			Steal:           r.int(header.index("st"), "st"),
+			Guest:           r.int(header.index("gu"), "gu"),
+		})
+	}
+	if header == nil {
+		return nil, fmt.Errorf("parse vmstat: no header found")
+	}
+	result.Warnings = warn
+	return result, nil
+}
@@ -3,6 +3,9 @@ package collectors
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/devops-toolkit/clusterreport/pkg/collector"
@@ -23,6 +26,38 @@ func (c *CustomCollector) Name() string {
 	return c.name
 }
 
+// Configure 设置采集器配置，command 为在节点上执行的命令
+func (c *CustomCollector) Configure(options map[string]interface{}) error {
+	if cmd, ok := options["command"]; ok {
+		if _, isString := cmd.(string); !isString {
+			return fmt.Errorf("custom collector %s: command must be a string", c.name)
+		}
+	}
+	c.config = options
+	return nil
+}
+
+// command 返回配置的采集命令
+func (c *CustomCollector) command() string {
+	if cmd, ok := c.config["command"].(string); ok {
+		return strings.TrimSpace(cmd)
+	}
+	return ""
+}
+
+// Validate 校验配置
+func (c *CustomCollector) Validate(config collector.Config) error {
+	if c.command() == "" {
+		return fmt.Errorf("custom collector %s: command is required", c.name)
+	}
+	return nil
+}
+
+// SupportedTypes 返回支持的数据类型
+func (c *CustomCollector) SupportedTypes() []collector.DataType {
+	return []collector.DataType{collector.DataTypeCustom}
+}
+
 func (c *CustomCollector) Collect(ctx context.Context, node collector.Node) (*collector.Data, error) {
 	// 实现数据采集逻辑
 	// 1. SSH连接到节点
@@ -32,24 +67,56 @@ func (c *CustomCollector) Collect(ctx context.Context, node collector.Node) (*co
 
 	data := &collector.Data{
 		Node:      node.Name,
-		Type:      "custom",
+		Type:      collector.DataTypeCustom,
+		Collector: c.name,
 		Timestamp: time.Now(),
 		Metrics:   make(map[string]interface{}),
 	}
 
 	// 采集逻辑
-	cmd := "your-custom-command"
+	cmd := c.command()
+	if cmd == "" {
+		return nil, fmt.Errorf("custom collector %s: command is required", c.name)
+	}
 	output, err := node.Execute(ctx, cmd)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("custom command failed on %s: %w", node.Name, err)
 	}
 
 	// 解析输出
 	data.Metrics = parseOutput(output)
+	data.Raw = output
 
 	return data, nil
 }
 
+// parseOutput 解析 "key: value" 或 "key=value" 格式的输出，数值自动转换为 float64
+func parseOutput(output string) map[string]interface{} {
+	metrics := make(map[string]interface{})
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sep := strings.IndexAny(line, ":=")
+		if sep <= 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(line[sep+1:])
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			metrics[key] = f
+		} else {
+			metrics[key] = value
+		}
+	}
+
+	return metrics
+}
+
 // 注册插件
 func init() {
 	collector.Register("custom", NewCustomCollector("custom"))
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/devops-toolkit/clusterreport/pkg/collector"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -157,3 +158,20 @@ func (c *MySQLCollector) Close() error {
 	}
 	return nil
 }
+
+// newMySQLLegacy 按配置项创建 MySQL 采集器，未配置 host 时连接节点本身的 3306 端口
+func newMySQLLegacy(node collector.Node, options map[string]interface{}) (collector.LegacyCollector, error) {
+	config := MySQLConfig{Port: 3306}
+	if err := collector.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+	if config.Host == "" {
+		config.Host = collector.NodeHost(node)
+	}
+	return NewMySQLCollector(config)
+}
+
+// 注册插件
+func init() {
+	collector.Register("mysql", collector.NewLegacyAdapter("mysql", newMySQLLegacy))
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/devops-toolkit/clusterreport/pkg/collector"
 )
 
 // RedisCollector Redis 数据采集器插件示例
@@ -236,3 +238,20 @@ func (c *RedisCollector) Ping(ctx context.Context) error {
 	// return c.client.Ping(ctx).Err()
 	return nil
 }
+
+// newRedisLegacy 按配置项创建 Redis 采集器，未配置 host 时连接节点本身的 6379 端口
+func newRedisLegacy(node collector.Node, options map[string]interface{}) (collector.LegacyCollector, error) {
+	config := RedisConfig{Port: 6379}
+	if err := collector.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+	if config.Host == "" {
+		config.Host = collector.NodeHost(node)
+	}
+	return NewRedisCollector(config)
+}
+
+// 注册插件
+func init() {
+	collector.Register("redis", collector.NewLegacyAdapter("redis", newRedisLegacy))
+}
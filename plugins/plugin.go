@@ -8,6 +8,7 @@ import (
 
 	"github.com/devops-toolkit/clusterreport/pkg/analyzer"
 	"github.com/devops-toolkit/clusterreport/pkg/collector"
+	"github.com/devops-toolkit/clusterreport/pkg/generator"
 )
 
 // PluginType 插件类型
@@ -144,12 +145,7 @@ type AnalyzerPlugin interface {
 // OutputPlugin 输出插件接口
 type OutputPlugin interface {
 	Plugin
-
-	// Generate 生成输出
-	Generate(ctx context.Context, report *analyzer.Report) ([]byte, error)
-
-	// Format 输出格式
-	Format() string
+	generator.Generator
 }
 
 // BasePlugin 插件基础实现
@@ -240,16 +236,6 @@ func NewAnalyzerAdapter(name, version string, a analyzer.Analyzer) *AnalyzerAdap
 }
 
 // Analyze 分析数据
-func (a *AnalyzerAdapter) Analyze(ctx context.Context, data []collector.Data) (*analyzer.Analysis, error) {
-	return a.analyzer.Analyze(ctx, data)
-}
-
-// AnalysisType 分析类型
-func (a *AnalyzerAdapter) AnalysisType() analyzer.AnalysisType {
-	return a.analyzer.Type()
-}
-
-// Options 配置选项
-func (a *AnalyzerAdapter) Options() map[string]interface{} {
-	return a.analyzer.Options()
+func (a *AnalyzerAdapter) Analyze(data interface{}) (*analyzer.AnalysisResult, error) {
+	return a.analyzer.Analyze(data)
 }